@@ -17,6 +17,7 @@ import (
 	httpmiddleware "github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/queue"
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/repo"
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/storage"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/analytics"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/calendar"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
@@ -24,10 +25,14 @@ import (
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/identity"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/orgs"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/polls"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/tickets"
 	"github.com/Alexander-D-Karpov/kvorum/internal/config"
 	"github.com/Alexander-D-Karpov/kvorum/internal/observ"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
 	"github.com/joho/godotenv"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
 )
@@ -104,31 +109,88 @@ func main() {
 	}
 	defer scheduler.Close()
 
+	unitOfWork := uow.NewUnitOfWork(db.Pool())
+
 	userRepo := repo.NewUserRepo(db)
 	eventRepo := repo.NewEventRepo(db)
 	roleRepo := repo.NewRoleRepo(db)
+	roleInviteRepo := repo.NewRoleInviteRepo(db)
+	reminderRepo := repo.NewReminderRepo(db)
+	inviteRepo := repo.NewInviteRepo(db)
 	seriesRepo := repo.NewSeriesRepo(db)
+	orgRepo := repo.NewOrgRepo(db)
+	orgMemberRepo := repo.NewOrgMemberRepo(db)
+	joinRequestRepo := repo.NewJoinRequestRepo(db)
 	formRepo := repo.NewFormRepo(db)
 	responseRepo := repo.NewResponseRepo(db)
+	fileRepo := repo.NewFileRepo(db)
 	registrationRepo := repo.NewRegistrationRepo(db)
 	waitlistRepo := repo.NewWaitlistRepo(db)
+	ticketTypeRepo := repo.NewTicketTypeRepo(db)
 	checkinRepo := repo.NewCheckinRepo(db)
-	qrTokenRepo := repo.NewQRTokenRepo(db)
+	attemptRepo := repo.NewAttemptRepo(db)
+	deviceRepo := repo.NewDeviceRepo(db)
+	zoneRepo := repo.NewZoneRepo(db)
+	ticketRepo := repo.NewTicketRepo(db)
+	signingKeyRepo, err := repo.NewSigningKeyRepo(db, cfg.Security.HMACSecret)
+	if err != nil {
+		log.Fatal("Failed to set up ticket signing keys:", err)
+	}
 	pollRepo := repo.NewPollRepo(db)
 	voteRepo := repo.NewVoteRepo(db)
 	calendarEventRepo := repo.NewCalendarEventRepo(db)
 	analyticsRepo := repo.NewAnalyticsRepo(db)
 	campaignRepo := repo.NewCampaignRepo(db)
+	deliveryRepo := repo.NewDeliveryRepo(db)
+	audienceRepo := repo.NewAudienceRepo(db)
+	liveRepo := repo.NewLiveRepo(db)
+
+	var (
+		fileStorage storage.Storage
+		localFiles  httphandlers.LocalFiles
+	)
+	switch cfg.Storage.Driver {
+	case "s3":
+		fileStorage, err = storage.NewS3Storage(
+			cfg.Storage.S3Endpoint, cfg.Storage.S3Region, cfg.Storage.S3Bucket,
+			cfg.Storage.S3AccessKey, cfg.Storage.S3SecretKey, cfg.Storage.S3PathStyle,
+		)
+		if err != nil {
+			log.Fatal("Failed to configure s3 storage:", err)
+		}
+	case "local":
+		local := storage.NewLocalStorage(
+			cfg.Storage.LocalPath, cfg.Server.PublicURL+"/api/v1/files/local", cfg.Security.HMACSecret,
+		)
+		fileStorage, localFiles = local, local
+	default:
+		log.Fatalf("Unknown storage driver %q", cfg.Storage.Driver)
+	}
 
 	identitySvc := identity.NewService(userRepo)
-	eventsSvc := events.NewService(eventRepo, seriesRepo, roleRepo, scheduler, cache)
-	formsSvc := forms.NewService(formRepo, responseRepo, cache)
-	registrationsSvc := registrations.NewService(registrationRepo, waitlistRepo, eventRepo)
-	checkinSvc := checkin.NewService(checkinRepo, qrTokenRepo, cfg.Security.HMACSecret)
+	liveSvc := live.NewService(cache, liveRepo)
+	eventsSvc := events.NewService(
+		unitOfWork, eventRepo, seriesRepo, roleRepo, roleInviteRepo, inviteRepo, orgMemberRepo, reminderRepo, scheduler, cache,
+	)
+	registrationsSvc := registrations.NewService(
+		unitOfWork, registrationRepo, waitlistRepo, eventRepo, roleRepo, ticketTypeRepo, responseRepo, scheduler, liveSvc,
+	)
+	formsSvc := forms.NewService(
+		unitOfWork, formRepo, responseRepo, fileRepo, fileStorage, registrationRepo, identitySvc, registrationsSvc, cache,
+	)
+	ticketsSvc := tickets.NewService(ticketTypeRepo, eventRepo, roleRepo)
+	orgsSvc := orgs.NewService(unitOfWork, orgRepo, orgMemberRepo, joinRequestRepo)
+	checkinSvc := checkin.NewService(
+		unitOfWork, checkinRepo, attemptRepo, deviceRepo, zoneRepo, ticketRepo, ticketTypeRepo, signingKeyRepo,
+		registrationRepo, roleRepo, eventRepo, liveSvc,
+		checkin.KeyPolicy{RotateAfter: cfg.Security.TicketKeyRotation, Grace: cfg.Security.TicketKeyGrace},
+	)
 	pollsSvc := polls.NewService(pollRepo, voteRepo)
 	calendarSvc := calendar.NewService(calendarEventRepo)
 	analyticsSvc := analytics.NewService(analyticsRepo)
-	campaignsSvc := campaigns.NewService(campaignRepo, nil, nil)
+	campaignsSvc := campaigns.NewService(
+		unitOfWork, campaignRepo, deliveryRepo, audienceRepo, botmax.NewSender(botClient.Api), scheduler,
+	)
 
 	middleware := httpmiddleware.NewMiddleware(cfg.Security.HMACSecret, cache)
 
@@ -137,11 +199,15 @@ func main() {
 		eventsSvc,
 		formsSvc,
 		registrationsSvc,
+		ticketsSvc,
+		orgsSvc,
 		checkinSvc,
 		pollsSvc,
 		calendarSvc,
 		analyticsSvc,
 		campaignsSvc,
+		liveSvc,
+		localFiles,
 		botClient,
 		cache,
 		cfg.Security.WebhookSecret,
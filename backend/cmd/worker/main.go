@@ -8,10 +8,19 @@ import (
 	"syscall"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/botmax"
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/cache"
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/queue"
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/repo"
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/storage"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/identity"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/config"
 	"github.com/Alexander-D-Karpov/kvorum/internal/observ"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
 	"github.com/hibiken/asynq"
 	"github.com/joho/godotenv"
 )
@@ -35,6 +44,12 @@ func main() {
 	}
 	defer db.Close()
 
+	cache, err := cache.NewRedisCache(cfg.Redis.URL)
+	if err != nil {
+		log.Fatal("Failed to connect to redis:", err)
+	}
+	defer cache.Close()
+
 	botClient, err := botmax.NewClient(cfg.Bot.Token)
 	if err != nil {
 		log.Fatal("Failed to create bot client:", err)
@@ -51,11 +66,98 @@ func main() {
 		log.Fatal("Failed to create worker server:", err)
 	}
 
-	handlers := queue.NewTaskHandlers(botClient.Api, nil, nil)
+	scheduler, err := queue.NewAsynqScheduler(cfg.Redis.URL)
+	if err != nil {
+		log.Fatal("Failed to create scheduler:", err)
+	}
+	defer scheduler.Close()
+
+	periodic, err := queue.NewAsynqPeriodic(cfg.Redis.URL)
+	if err != nil {
+		log.Fatal("Failed to create periodic scheduler:", err)
+	}
+
+	unitOfWork := uow.NewUnitOfWork(db.Pool())
+	eventRepo := repo.NewEventRepo(db)
+	registrationRepo := repo.NewRegistrationRepo(db)
+	waitlistRepo := repo.NewWaitlistRepo(db)
+	roleRepo := repo.NewRoleRepo(db)
+	ticketTypeRepo := repo.NewTicketTypeRepo(db)
+	userRepo := repo.NewUserRepo(db)
+	reminderRepo := repo.NewReminderRepo(db)
+	deliveryRepo := repo.NewDeliveryRepo(db)
+	campaignRepo := repo.NewCampaignRepo(db)
+	audienceRepo := repo.NewAudienceRepo(db)
+	responseRepo := repo.NewResponseRepo(db)
+	liveRepo := repo.NewLiveRepo(db)
+	seriesRepo := repo.NewSeriesRepo(db)
+	roleInviteRepo := repo.NewRoleInviteRepo(db)
+	inviteRepo := repo.NewInviteRepo(db)
+	orgMemberRepo := repo.NewOrgMemberRepo(db)
+	formRepo := repo.NewFormRepo(db)
+	fileRepo := repo.NewFileRepo(db)
+
+	var fileStorage storage.Storage
+	switch cfg.Storage.Driver {
+	case "s3":
+		fileStorage, err = storage.NewS3Storage(
+			cfg.Storage.S3Endpoint, cfg.Storage.S3Region, cfg.Storage.S3Bucket,
+			cfg.Storage.S3AccessKey, cfg.Storage.S3SecretKey, cfg.Storage.S3PathStyle,
+		)
+		if err != nil {
+			log.Fatal("Failed to configure s3 storage:", err)
+		}
+	case "local":
+		fileStorage = storage.NewLocalStorage(
+			cfg.Storage.LocalPath, cfg.Server.PublicURL+"/api/v1/files/local", cfg.Security.HMACSecret,
+		)
+	default:
+		log.Fatalf("Unknown storage driver %q", cfg.Storage.Driver)
+	}
+
+	liveSvc := live.NewService(cache, liveRepo)
+
+	eventsSvc := events.NewService(
+		unitOfWork, eventRepo, seriesRepo, roleRepo, roleInviteRepo, inviteRepo, orgMemberRepo, reminderRepo, scheduler, cache,
+	)
+
+	registrationsSvc := registrations.NewService(
+		unitOfWork, registrationRepo, waitlistRepo, eventRepo, roleRepo, ticketTypeRepo, responseRepo, scheduler, liveSvc,
+	)
+	formsSvc := forms.NewService(
+		unitOfWork, formRepo, responseRepo, fileRepo, fileStorage, registrationRepo,
+		identity.NewService(userRepo), registrationsSvc, cache,
+	)
+	campaignsSvc := campaigns.NewService(
+		unitOfWork, campaignRepo, deliveryRepo, audienceRepo, botmax.NewSender(botClient.Api), scheduler,
+	)
+
+	handlers := queue.NewTaskHandlers(
+		botClient.Api,
+		queue.NewEventSource(eventRepo),
+		queue.NewRegistrationSource(registrationRepo),
+		registrationsSvc,
+		userRepo,
+		reminderRepo,
+		deliveryRepo,
+		campaignsSvc,
+		registrationsSvc,
+		eventsSvc,
+		formsSvc,
+	)
 
 	mux := asynq.NewServeMux()
 	mux.HandleFunc("reminder", handlers.HandleReminder)
 	mux.HandleFunc("campaign", handlers.HandleCampaign)
+	mux.HandleFunc("waitlist_promotion", handlers.HandleWaitlistPromotion)
+	mux.HandleFunc("registration_decision", handlers.HandleDecisionNotification)
+	mux.HandleFunc("form_hold_expiry", handlers.HandleFormHoldExpiry)
+	mux.HandleFunc("series_extension", handlers.HandleSeriesExtension)
+	mux.HandleFunc("file_cleanup", handlers.HandleFileCleanup)
+
+	if err := periodic.Start(); err != nil {
+		log.Fatal("Periodic scheduler failed to start:", err)
+	}
 
 	go func() {
 		logger.Info("Worker started")
@@ -69,6 +171,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down worker...")
+	periodic.Stop()
 	server.Stop()
 	logger.Info("Worker stopped")
 }
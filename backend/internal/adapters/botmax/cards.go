@@ -54,6 +54,8 @@ func BuildEventCardComponents(api *maxbotapi.Api, event *EventForCard, userStatu
 		statusEmoji = "❓ Возможно пойдёте"
 	case domainregistrations.StatusWaitlist:
 		statusEmoji = "⏳ Вы в листе ожидания"
+	case domainregistrations.StatusPendingForm:
+		statusEmoji = "📝 Место за вами, осталось заполнить анкету"
 	}
 
 	if statusEmoji != "" {
@@ -68,8 +70,16 @@ func BuildEventCardComponents(api *maxbotapi.Api, event *EventForCard, userStatu
 	row2 := kb.AddRow()
 	row2.AddCallback("❓ Возможно", schemes.DEFAULT, FormatCallbackPayload(event.ID, "rsvp", "maybe"))
 
+	if userStatus == domainregistrations.StatusGoing {
+		kb.AddRow().AddCallback("🎫 Билет", schemes.DEFAULT, FormatCallbackPayload(event.ID, "ticket", ""))
+	}
+
 	row3 := kb.AddRow()
-	row3.AddOpenApp("📱 Открыть мини-приложение", schemes.DEFAULT, "", fmt.Sprintf("event=%s", event.ID))
+	if userStatus == domainregistrations.StatusPendingForm {
+		row3.AddOpenApp("📝 Заполнить анкету", schemes.DEFAULT, "", fmt.Sprintf("event=%s&step=form", event.ID))
+	} else {
+		row3.AddOpenApp("📱 Открыть мини-приложение", schemes.DEFAULT, "", fmt.Sprintf("event=%s", event.ID))
+	}
 
 	return MessageComponents{
 		Text:     text,
@@ -121,6 +131,48 @@ func BuildReminderMessageComponents(api *maxbotapi.Api, event *EventForReminder,
 	}
 }
 
+type Decision struct {
+	EventID    shared.ID
+	EventTitle string
+	Status     domainregistrations.Status
+	Reason     string
+	HoldUntil  *time.Time
+}
+
+func BuildDecisionMessageComponents(api *maxbotapi.Api, d *Decision) MessageComponents {
+	var text string
+	switch d.Status {
+	case domainregistrations.StatusRejected:
+		text = fmt.Sprintf("❌ Заявка на **%s** отклонена\n", d.EventTitle)
+	case domainregistrations.StatusWaitlist:
+		text = fmt.Sprintf("✅ Заявка на **%s** одобрена\n\n⏳ Мест пока нет, вы в листе ожидания\n", d.EventTitle)
+	case domainregistrations.StatusPendingForm:
+		text = fmt.Sprintf("📝 Место на **%s** за вами\n\nЧтобы подтвердить участие, заполните анкету", d.EventTitle)
+		if d.HoldUntil != nil {
+			text += fmt.Sprintf(" в течение %d мин.", int(time.Until(*d.HoldUntil).Round(time.Minute).Minutes()))
+		}
+		text += "\n"
+	default:
+		text = fmt.Sprintf("✅ Заявка на **%s** одобрена, вы записаны\n", d.EventTitle)
+	}
+
+	if d.Reason != "" {
+		text += fmt.Sprintf("\n💬 %s\n", d.Reason)
+	}
+
+	kb := api.Messages.NewKeyboardBuilder()
+	if d.Status == domainregistrations.StatusPendingForm {
+		kb.AddRow().AddOpenApp("📝 Заполнить анкету", schemes.DEFAULT, "", fmt.Sprintf("event=%s&step=form", d.EventID))
+	} else {
+		kb.AddRow().AddOpenApp("📱 Открыть мини-приложение", schemes.DEFAULT, "", fmt.Sprintf("event=%s", d.EventID))
+	}
+
+	return MessageComponents{
+		Text:     text,
+		Keyboard: kb,
+	}
+}
+
 func BuildWelcomeMessageComponents(api *maxbotapi.Api, userName string) MessageComponents {
 	text := fmt.Sprintf("👋 Привет, %s!\n\n", userName)
 	text += "Я — бот Kvorum для управления событиями.\n\n"
@@ -138,3 +190,13 @@ func BuildWelcomeMessageComponents(api *maxbotapi.Api, userName string) MessageC
 		Keyboard: kb,
 	}
 }
+
+// BuildTicketCaption is the text sent along with a ticket's QR image.
+func BuildTicketCaption(title, when, venue string) string {
+	text := fmt.Sprintf("🎫 **%s**\n\n📅 %s\n", title, when)
+	if venue != "" {
+		text += fmt.Sprintf("📍 %s\n", venue)
+	}
+	text += "\nПокажите этот QR-код на входе."
+	return text
+}
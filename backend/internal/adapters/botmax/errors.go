@@ -0,0 +1,29 @@
+package botmax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	maxbotapi "github.com/max-messenger/max-bot-api-client-go"
+)
+
+// IsTransient reports whether a bot API call may succeed if retried: network
+// failures, timeouts, rate limiting and server errors.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr *maxbotapi.NetworkError
+	var timeoutErr *maxbotapi.TimeoutError
+	var apiErr *maxbotapi.APIError
+	switch {
+	case errors.As(err, &netErr), errors.As(err, &timeoutErr):
+		return true
+	case errors.As(err, &apiErr):
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	default:
+		return errors.Is(err, context.DeadlineExceeded)
+	}
+}
@@ -2,6 +2,7 @@ package botmax
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -111,15 +112,24 @@ func (h *Handler) handleMessageCallback(ctx context.Context, u *schemes.MessageC
 
 	switch payload.Action {
 	case "rsvp":
-		status := domainregistrations.Status(payload.Arg)
-		if err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, status); err != nil {
+		reg, err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, domainregistrations.Status(payload.Arg))
+		if errors.Is(err, domainregistrations.ErrAwaitingApproval) {
+			return h.answerCallback(ctx, u.Callback.CallbackID, "🕓 Заявка на рассмотрении")
+		}
+		if errors.Is(err, domainregistrations.ErrRegistrationRejected) {
+			return h.answerCallback(ctx, u.Callback.CallbackID, "❌ Заявка отклонена")
+		}
+		if err != nil {
 			return h.answerCallback(ctx, u.Callback.CallbackID, "Ошибка")
 		}
+		status := reg.Status
 
 		notifications := map[domainregistrations.Status]string{
-			domainregistrations.StatusGoing:    "✅ Вы записаны",
-			domainregistrations.StatusNotGoing: "❌ Отменено",
-			domainregistrations.StatusMaybe:    "❓ Напомним позже",
+			domainregistrations.StatusGoing:       "✅ Вы записаны",
+			domainregistrations.StatusNotGoing:    "❌ Отменено",
+			domainregistrations.StatusMaybe:       "❓ Напомним позже",
+			domainregistrations.StatusWaitlist:    "⏳ Мест нет, вы в листе ожидания",
+			domainregistrations.StatusPendingForm: "📝 Место за вами, заполните анкету",
 		}
 
 		notification := notifications[status]
@@ -130,7 +140,7 @@ func (h *Handler) handleMessageCallback(ctx context.Context, u *schemes.MessageC
 		return h.answerCallback(ctx, u.Callback.CallbackID, notification)
 
 	case "confirm":
-		if err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, domainregistrations.StatusGoing); err != nil {
+		if _, err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, domainregistrations.StatusGoing); err != nil {
 			return h.answerCallback(ctx, u.Callback.CallbackID, "Ошибка")
 		}
 		return h.answerCallback(ctx, u.Callback.CallbackID, "✅ Подтверждено")
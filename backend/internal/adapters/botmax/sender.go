@@ -0,0 +1,55 @@
+package botmax
+
+import (
+	"context"
+	"time"
+
+	maxbotapi "github.com/max-messenger/max-bot-api-client-go"
+)
+
+const (
+	sendAttempts = 3
+	sendBackoff  = 500 * time.Millisecond
+)
+
+// SendWithRetry sends msg, retrying transient bot API errors with
+// exponential backoff. It returns the number of attempts made.
+func SendWithRetry(ctx context.Context, api *maxbotapi.Api, msg *maxbotapi.Message) (string, int, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var messageID string
+		messageID, err = api.Messages.Send(ctx, msg)
+		if err == nil || !IsTransient(err) || attempt == sendAttempts {
+			return messageID, attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", attempt, ctx.Err()
+		case <-time.After(sendBackoff << (attempt - 1)):
+		}
+	}
+}
+
+// Sender sends plain markdown messages to MAX chats.
+type Sender struct {
+	api *maxbotapi.Api
+}
+
+func NewSender(api *maxbotapi.Api) *Sender {
+	return &Sender{api: api}
+}
+
+func (s *Sender) SendText(ctx context.Context, chatID int64, text string) (string, error) {
+	msg := maxbotapi.NewMessage().
+		SetChat(chatID).
+		SetText(text).
+		SetFormat("markdown")
+
+	messageID, _, err := SendWithRetry(ctx, s.api, msg)
+	return messageID, err
+}
+
+func (s *Sender) Transient(err error) bool {
+	return IsTransient(err)
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+func liveChannel(eventID shared.ID) string {
+	return fmt.Sprintf("live:event:%s", eventID)
+}
+
+func (r *RedisCache) PublishLive(ctx context.Context, update *live.Update) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, liveChannel(update.EventID), data).Err()
+}
+
+// SubscribeLive holds one Redis connection per subscriber for as long as
+// ctx lives.
+func (r *RedisCache) SubscribeLive(ctx context.Context, eventID shared.ID) (<-chan *live.Update, error) {
+	pubsub := r.client.Subscribe(ctx, liveChannel(eventID))
+	// Wait for the confirmation so that nothing published after we
+	// return is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	out := make(chan *live.Update, 16)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var update live.Update
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					log.Printf("live: bad message on %s: %v", msg.Channel, err)
+					continue
+				}
+				select {
+				case out <- &update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/redis/go-redis/v9"
 )
@@ -60,20 +61,36 @@ func (r *RedisCache) InvalidateEvent(ctx context.Context, id shared.ID) {
 	r.client.Del(ctx, key)
 }
 
-func (r *RedisCache) GetDraft(ctx context.Context, formID, userID shared.ID) (json.RawMessage, bool) {
+func (r *RedisCache) GetDraft(ctx context.Context, formID, userID shared.ID) (*forms.Response, bool) {
 	key := fmt.Sprintf("draft:form:%s:%s", formID, userID)
 	data, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		return nil, false
 	}
-	return data, true
+
+	var draft forms.Response
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return nil, false
+	}
+
+	return &draft, true
 }
 
-func (r *RedisCache) SetDraft(ctx context.Context, formID, userID shared.ID, data json.RawMessage, ttl time.Duration) {
-	key := fmt.Sprintf("draft:form:%s:%s", formID, userID)
+func (r *RedisCache) SetDraft(ctx context.Context, draft *forms.Response, ttl time.Duration) {
+	key := fmt.Sprintf("draft:form:%s:%s", draft.FormID, draft.UserID)
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return
+	}
+
 	r.client.Set(ctx, key, data, ttl)
 }
 
+func (r *RedisCache) InvalidateDraft(ctx context.Context, formID, userID shared.ID) {
+	key := fmt.Sprintf("draft:form:%s:%s", formID, userID)
+	r.client.Del(ctx, key)
+}
+
 func (r *RedisCache) Close() error {
 	return r.client.Close()
 }
@@ -0,0 +1,201 @@
+// Package export writes tabular data as CSV or as a single-sheet XLSX
+// workbook.
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// RowWriter writes a table one row at a time, so an export never has to be
+// held in memory. Close finishes the file.
+type RowWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// WriteCSV writes the rows as CSV.
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	return writeAll(NewCSVWriter(w), header, rows)
+}
+
+// WriteXLSX writes the rows as a workbook with one sheet.
+func WriteXLSX(w io.Writer, sheet string, header []string, rows [][]string) error {
+	xw, err := NewXLSXWriter(w, sheet)
+	if err != nil {
+		return err
+	}
+	return writeAll(xw, header, rows)
+}
+
+func writeAll(rw RowWriter, header []string, rows [][]string) error {
+	if err := rw.WriteRow(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := rw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+type csvWriter struct {
+	cw *csv.Writer
+}
+
+// NewCSVWriter writes CSV. Cells that a spreadsheet would read as a
+// formula are prefixed with a quote, since they hold user input.
+func NewCSVWriter(w io.Writer) RowWriter {
+	return &csvWriter{cw: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = escapeFormula(cell)
+	}
+	return c.cw.Write(safe)
+}
+
+func (c *csvWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+func escapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+type xlsxWriter struct {
+	zw  *zip.Writer
+	bw  *bufio.Writer
+	row int
+}
+
+// NewXLSXWriter writes a workbook with one sheet. Every cell is an inline
+// string, so nothing in the data is evaluated.
+func NewXLSXWriter(w io.Writer, sheet string) (RowWriter, error) {
+	zw := zip.NewWriter(w)
+
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", workbookXML(sheet)},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(f)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return &xlsxWriter{zw: zw, bw: bw}, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells []string) error {
+	x.row++
+	row := strconv.Itoa(x.row)
+	x.bw.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		x.bw.WriteString(`<c r="` + column(i) + row + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.bw, []byte(cell)); err != nil {
+			return err
+		}
+		x.bw.WriteString(`</t></is></c>`)
+	}
+	_, err := x.bw.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.bw.WriteString(`</sheetData></worksheet>`)
+	if err := x.bw.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// column turns a zero-based index into a column name: A, B, ..., Z, AA.
+func column(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func workbookXML(sheet string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(sheetName(sheet)))
+	return xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + b.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+}
+
+// sheetName drops the characters Excel forbids in sheet names and keeps
+// to its 31 character limit.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if strings.TrimSpace(name) == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+const contentTypes = xml.Header +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const rootRels = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookRels = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`</Relationships>`
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
 )
@@ -11,6 +12,10 @@ import (
 func (h *Handlers) GetEventAnalytics(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermViewAnalytics) {
+		return
+	}
+
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
 
@@ -46,6 +51,10 @@ func (h *Handlers) GetEventAnalytics(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) ExportEventAnalyticsCSV(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermViewAnalytics) {
+		return
+	}
+
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
 
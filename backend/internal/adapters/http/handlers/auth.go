@@ -133,6 +133,34 @@ func (h *Handlers) GetMe(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, user)
 }
 
+// GetSavedData shows what the user chose to have remembered for
+// prefilling forms.
+func (h *Handlers) GetSavedData(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	profile, err := h.identitySvc.GetProfile(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get saved data")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"fields": profile})
+}
+
+// ClearSavedData forgets the keys given as ?key=, or everything without
+// them.
+func (h *Handlers) ClearSavedData(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	user, err := h.identitySvc.ClearSavedData(r.Context(), userID, r.URL.Query()["key"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to clear saved data")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"fields": user.Profile()})
+}
+
 func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("session")
 	if err == nil {
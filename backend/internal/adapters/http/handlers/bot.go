@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/botmax"
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/render"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	maxbotapi "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
 )
@@ -132,14 +137,21 @@ func (h *Handlers) handleMessageCallback(ctx context.Context, mc *schemes.Messag
 
 	switch payload.Action {
 	case "rsvp":
-		status := registrations.Status(payload.Arg)
-		err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, status)
+		reg, err := h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, registrations.Status(payload.Arg))
 		if err != nil {
+			notification := "Ошибка"
+			switch {
+			case errors.Is(err, registrations.ErrAwaitingApproval):
+				notification = "🕓 Заявка на рассмотрении"
+			case errors.Is(err, registrations.ErrRegistrationRejected):
+				notification = "❌ Заявка отклонена"
+			}
 			_, _ = h.botClient.Messages.AnswerOnCallback(ctx, mc.Callback.CallbackID, &schemes.CallbackAnswer{
-				Notification: "Ошибка",
+				Notification: notification,
 			})
 			return
 		}
+		status := reg.Status
 
 		event, _ := h.eventsSvc.GetEvent(ctx, payload.EventID)
 		if mc.Message != nil && event != nil {
@@ -167,16 +179,31 @@ func (h *Handlers) handleMessageCallback(ctx context.Context, mc *schemes.Messag
 		}
 
 		notifications := map[registrations.Status]string{
-			registrations.StatusGoing:    "✅ Вы записаны",
-			registrations.StatusNotGoing: "❌ Отменено",
-			registrations.StatusMaybe:    "❓ Напомним позже",
+			registrations.StatusGoing:       "✅ Вы записаны",
+			registrations.StatusNotGoing:    "❌ Отменено",
+			registrations.StatusMaybe:       "❓ Напомним позже",
+			registrations.StatusWaitlist:    "⏳ Мест нет, вы в листе ожидания",
+			registrations.StatusPendingForm: "📝 Место за вами, заполните анкету",
 		}
 		_, _ = h.botClient.Messages.AnswerOnCallback(ctx, mc.Callback.CallbackID, &schemes.CallbackAnswer{
 			Notification: notifications[status],
 		})
 
+	case "ticket":
+		notification := "🎫 Билет отправлен"
+		if err := h.sendTicket(ctx, mc, payload.EventID, user.ID); err != nil {
+			log.Printf("Failed to send ticket: %v", err)
+			notification = "Ошибка"
+			if errors.Is(err, checkin.ErrNoConfirmedSeat) {
+				notification = "Билет выдаётся после подтверждения участия"
+			}
+		}
+		_, _ = h.botClient.Messages.AnswerOnCallback(ctx, mc.Callback.CallbackID, &schemes.CallbackAnswer{
+			Notification: notification,
+		})
+
 	case "confirm":
-		_ = h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, registrations.StatusGoing)
+		_, _ = h.registrationsSvc.UpdateRSVP(ctx, payload.EventID, user.ID, registrations.StatusGoing)
 		_, _ = h.botClient.Messages.AnswerOnCallback(ctx, mc.Callback.CallbackID, &schemes.CallbackAnswer{
 			Notification: "✅ Подтверждено",
 		})
@@ -189,6 +216,41 @@ func (h *Handlers) handleMessageCallback(ctx context.Context, mc *schemes.Messag
 	}
 }
 
+// sendTicket sends the attendee's ticket QR code as a photo to the chat the
+// button was pressed in.
+func (h *Handlers) sendTicket(ctx context.Context, mc *schemes.MessageCallbackUpdate, eventID, userID shared.ID) error {
+	pass, err := h.checkinSvc.IssuePass(ctx, eventID, userID)
+	if err != nil {
+		return err
+	}
+	q, err := render.EncodeQR([]byte(pass.Token))
+	if err != nil {
+		return err
+	}
+	var img bytes.Buffer
+	if err := render.WritePNG(&img, q, render.DefaultImageSize); err != nil {
+		return err
+	}
+	photo, err := h.botClient.Uploads.UploadPhotoFromReader(ctx, &img)
+	if err != nil {
+		return err
+	}
+
+	t := h.printableTicket(ctx, pass, userID)
+	msg := maxbotapi.NewMessage().
+		SetText(botmax.BuildTicketCaption(t.Title, render.FormatTicketTime(t.StartsAt, t.EndsAt, t.Timezone), t.Venue)).
+		SetFormat("markdown").
+		AddPhoto(photo)
+	if mc.Message != nil {
+		msg.SetChat(mc.Message.Recipient.ChatId)
+	} else {
+		msg.SetUser(mc.Callback.User.UserId)
+	}
+
+	_, _, err = botmax.SendWithRetry(ctx, h.botClient.Api, msg)
+	return err
+}
+
 func (h *Handlers) handleBotStarted(ctx context.Context, bs *schemes.BotStartedUpdate) {
 	userIDStr := strconv.FormatInt(bs.User.UserId, 10)
 	displayName := bs.User.FirstName
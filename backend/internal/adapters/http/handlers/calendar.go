@@ -11,6 +11,10 @@ import (
 func (h *Handlers) GetEventICS(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
 	ics, err := h.calendarSvc.GenerateEventICS(r.Context(), eventID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to generate ics")
@@ -39,6 +43,10 @@ func (h *Handlers) GetUserICS(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) GetGoogleCalendarLink(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
 	link, err := h.calendarSvc.GetGoogleCalendarLink(r.Context(), eventID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to generate link")
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
 )
@@ -19,6 +22,10 @@ func (h *Handlers) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermManageCampaigns) {
+		return
+	}
+
 	var req struct {
 		Name        string     `json:"name"`
 		Segment     string     `json:"segment"`
@@ -42,7 +49,14 @@ func (h *Handlers) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 		req.ScheduledAt,
 	)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to create campaign")
+		switch {
+		case errors.Is(err, campaigns.ErrInvalidSegment),
+			errors.Is(err, campaigns.ErrUnsupportedChannel),
+			errors.Is(err, campaigns.ErrEmptyMessage):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create campaign")
+		}
 		return
 	}
 
@@ -58,6 +72,10 @@ func (h *Handlers) GetCampaigns(w http.ResponseWriter, r *http.Request) {
 
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermManageCampaigns) {
+		return
+	}
+
 	campaigns, err := h.campaignsSvc.GetCampaigns(r.Context(), eventID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to get campaigns")
@@ -66,3 +84,24 @@ func (h *Handlers) GetCampaigns(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, campaigns)
 }
+
+func (h *Handlers) GetCampaignDeliveries(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	campaignID := shared.ID(chi.URLParam(r, "campaignID"))
+
+	if !h.authorize(w, r, eventID, events.PermManageCampaigns) {
+		return
+	}
+
+	deliveries, err := h.campaignsSvc.ListDeliveries(r.Context(), eventID, campaignID)
+	if errors.Is(err, campaigns.ErrCampaignNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get deliveries")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deliveries)
+}
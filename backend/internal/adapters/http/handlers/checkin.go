@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/render"
+	appcheckin "github.com/Alexander-D-Karpov/kvorum/internal/app/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// respondCheckinError reports a refused pass. Duplicates carry the time of
+// the first entry so the operator can tell a re-scan from a shared ticket.
+func respondCheckinError(w http.ResponseWriter, err error) {
+	var dup *checkin.DuplicateError
+	switch {
+	case errors.As(err, &dup):
+		respondJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":            "already checked in",
+			"first_checkin_at": dup.FirstAt,
+			"last_seen_at":     dup.LastAt,
+			"inside":           dup.Inside,
+		})
+	case errors.Is(err, checkin.ErrNotCheckedIn), errors.Is(err, checkin.ErrNotInside):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, checkin.ErrWrongEvent):
+		respondError(w, http.StatusUnprocessableEntity, "ticket is for another event, check the event selected on the scanner")
+	case errors.Is(err, checkin.ErrInvalidQRToken):
+		respondError(w, http.StatusBadRequest, "invalid qr code")
+	case errors.Is(err, checkin.ErrInvalidAction):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, checkin.ErrTicketRevoked):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, checkin.ErrNoConfirmedSeat), errors.Is(err, checkin.ErrTicketOutsideWindow):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, checkin.ErrZoneNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, checkin.ErrZoneDenied):
+		respondError(w, http.StatusForbidden, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to checkin")
+	}
+}
+
+// authorizeScanner is authorize for the door: a caller without check-in
+// rights is refused like anywhere else, but the attempt is audited first.
+func (h *Handlers) authorizeScanner(
+	w http.ResponseWriter,
+	r *http.Request,
+	eventID shared.ID,
+	scanner checkin.Scanner,
+	method checkin.Method,
+	action checkin.Action,
+	userID shared.ID,
+) bool {
+	_, err := h.eventsSvc.Authorize(r.Context(), eventID, scanner.UserID, events.PermScanCheckin)
+	if errors.Is(err, events.ErrUnauthorized) {
+		if aerr := h.checkinSvc.Deny(r.Context(), eventID, scanner, method, action, userID); aerr != nil {
+			log.Printf("audit denied check-in at event %s: %v", eventID, aerr)
+		}
+	}
+	if err != nil {
+		respondEventAccessError(w, err)
+		return false
+	}
+	return true
+}
+
+// scannerFrom identifies the staff member and device behind a check-in
+// request, and the zone it guards. The device ID comes from the body or the
+// X-Device-ID header.
+func scannerFrom(r *http.Request, deviceID string, zoneID *shared.ID) checkin.Scanner {
+	if deviceID == "" {
+		deviceID = r.Header.Get("X-Device-ID")
+	}
+	if zoneID != nil && *zoneID == "" {
+		zoneID = nil
+	}
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	return checkin.Scanner{
+		UserID:    middleware.GetUserID(r.Context()),
+		DeviceID:  deviceID,
+		ZoneID:    zoneID,
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// GetCheckinAttempts lists every scan and manual check-in at the event,
+// refused ones included, newest first.
+func (h *Handlers) GetCheckinAttempts(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageRegistrations) {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	attempts, err := h.checkinSvc.ListAttempts(r.Context(), eventID, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get check-in attempts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attempts)
+}
+
+func (h *Handlers) GetOccupancy(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermViewAnalytics) {
+		return
+	}
+
+	var zoneID *shared.ID
+	if v := r.URL.Query().Get("zone_id"); v != "" {
+		id := shared.ID(v)
+		zoneID = &id
+	}
+
+	occupancy, err := h.checkinSvc.Occupancy(r.Context(), eventID, zoneID)
+	if errors.Is(err, checkin.ErrZoneNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get occupancy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, occupancy)
+}
+
+// GetTicketKeys publishes the keys scanners use to verify tickets offline.
+// Retired keys stay listed until they stop verifying.
+func (h *Handlers) GetTicketKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.checkinSvc.PublicKeys(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get keys")
+		return
+	}
+
+	type keyJSON struct {
+		ID        string     `json:"kid"`
+		Algorithm string     `json:"alg"`
+		PublicKey string     `json:"public_key"`
+		CreatedAt time.Time  `json:"created_at"`
+		RetiredAt *time.Time `json:"retired_at,omitempty"`
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	}
+	result := make([]keyJSON, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, keyJSON{
+			ID:        k.ID,
+			Algorithm: "Ed25519",
+			PublicKey: base64.RawURLEncoding.EncodeToString(k.PublicKey),
+			CreatedAt: k.CreatedAt,
+			RetiredAt: k.RetiredAt,
+			ExpiresAt: k.ExpiresAt,
+		})
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondJSON(w, http.StatusOK, map[string]interface{}{"keys": result})
+}
+
+func (h *Handlers) GetRevokedTickets(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermScanCheckin) {
+		return
+	}
+
+	revoked, err := h.checkinSvc.RevocationList(r.Context(), eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get revocation list")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"event_id":     eventID,
+		"generated_at": time.Now().UTC(),
+		"revoked":      revoked,
+	})
+}
+
+func (h *Handlers) RevokeTicket(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	ticketID := shared.ID(chi.URLParam(r, "ticketID"))
+
+	if !h.authorize(w, r, eventID, events.PermManageRegistrations) {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	ticket, err := h.checkinSvc.RevokeTicket(r.Context(), eventID, ticketID, req.Reason)
+	if errors.Is(err, checkin.ErrTicketNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to revoke ticket")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"ticket_id":  ticket.ID,
+		"revoked_at": ticket.RevokedAt,
+		"reason":     ticket.RevokeReason,
+	})
+}
+
+// GetQRImage renders the caller's ticket QR code for the event, {id}, as
+// PNG or SVG. The size query parameter sets the width in pixels.
+func (h *Handlers) GetQRImage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	format := chi.URLParam(r, "format")
+	if format != "png" && format != "svg" {
+		respondError(w, http.StatusNotFound, "unknown format")
+		return
+	}
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	size = render.ClampSize(size)
+
+	pass, ok := h.issuePass(w, r, eventID, userID)
+	if !ok {
+		return
+	}
+	q, err := render.EncodeQR([]byte(pass.Token))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to generate qr")
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := render.ContentTypePNG
+	if format == "svg" {
+		contentType = render.ContentTypeSVG
+		err = render.WriteSVG(&buf, q, size)
+	} else {
+		err = render.WritePNG(&buf, q, size)
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to generate qr")
+		return
+	}
+
+	// Tokens are signed afresh on each request and tickets can be revoked,
+	// so the image is not worth caching.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// GetTicketPDF renders the caller's ticket for the event, {id}, as a
+// printable A6 page.
+func (h *Handlers) GetTicketPDF(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	pass, ok := h.issuePass(w, r, eventID, userID)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.WritePDF(&buf, h.printableTicket(r.Context(), pass, userID)); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to render ticket")
+		return
+	}
+
+	w.Header().Set("Content-Type", render.ContentTypePDF)
+	w.Header().Set("Content-Disposition", `attachment; filename="ticket-`+string(pass.Ticket.ID)+`.pdf"`)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (h *Handlers) issuePass(w http.ResponseWriter, r *http.Request, eventID, userID shared.ID) (*appcheckin.Pass, bool) {
+	pass, err := h.checkinSvc.IssuePass(r.Context(), eventID, userID)
+	if errors.Is(err, checkin.ErrNoConfirmedSeat) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to generate qr")
+		return nil, false
+	}
+	return pass, true
+}
+
+// printableTicket fills in the attendee's name; a ticket without one is
+// still a valid ticket.
+func (h *Handlers) printableTicket(ctx context.Context, pass *appcheckin.Pass, userID shared.ID) *render.Ticket {
+	t := &render.Ticket{
+		ID:       string(pass.Ticket.ID),
+		Title:    pass.Event.Title,
+		StartsAt: pass.Event.StartsAt,
+		EndsAt:   pass.Event.EndsAt,
+		Timezone: pass.Event.Timezone,
+		Venue:    pass.Event.Location,
+		Token:    pass.Token,
+	}
+	if t.Venue == "" {
+		t.Venue = pass.Event.OnlineURL
+	}
+	if user, err := h.identitySvc.GetUser(ctx, userID); err == nil {
+		t.Attendee = user.DisplayName
+	}
+	return t
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	appcheckin "github.com/Alexander-D-Karpov/kvorum/internal/app/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetCheckinManifest gives a scanner device the attendee list for offline
+// use. With ?since=<cursor> only the entries changed since then are sent.
+func (h *Handlers) GetCheckinManifest(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermScanCheckin) {
+		return
+	}
+
+	var since *time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		since = &t
+	}
+
+	manifest, err := h.checkinSvc.Manifest(r.Context(), eventID, scannerFrom(r, r.URL.Query().Get("device_id"), nil), since)
+	if errors.Is(err, checkin.ErrDeviceRequired) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get manifest")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusOK, manifest)
+}
+
+// SyncCheckins takes a batch of scans a device made offline and reports
+// how each was settled. Scans marked "retry" should be uploaded again.
+func (h *Handlers) SyncCheckins(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermScanCheckin) {
+		return
+	}
+
+	var req struct {
+		DeviceID string                   `json:"device_id"`
+		ZoneID   *shared.ID               `json:"zone_id"`
+		Pending  int                      `json:"pending"`
+		Scans    []appcheckin.OfflineScan `json:"scans"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	results, err := h.checkinSvc.SyncScans(r.Context(), eventID, scannerFrom(r, req.DeviceID, req.ZoneID), req.Scans, req.Pending)
+	switch {
+	case errors.Is(err, checkin.ErrDeviceRequired),
+		errors.Is(err, appcheckin.ErrMissingLocalID):
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, appcheckin.ErrSyncBatchTooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, "failed to sync check-ins")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"results":   results,
+		"synced_at": time.Now().UTC(),
+	})
+}
+
+// GetScannerDevices shows the sync state of each scanner device at the
+// event, so organizers can spot one that has stopped uploading.
+func (h *Handlers) GetScannerDevices(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageRegistrations) {
+		return
+	}
+
+	devices, err := h.checkinSvc.ListDevices(r.Context(), eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get devices")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, devices)
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
 )
@@ -14,8 +18,9 @@ func (h *Handlers) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 
 	var req struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		Title          string     `json:"title"`
+		Description    string     `json:"description"`
+		OrganizationID *shared.ID `json:"organization_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -23,8 +28,12 @@ func (h *Handlers) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	event, err := h.eventsSvc.CreateEvent(r.Context(), userID, req.Title, req.Description)
+	event, err := h.eventsSvc.CreateEvent(r.Context(), userID, req.Title, req.Description, req.OrganizationID)
 	if err != nil {
+		if errors.Is(err, orgs.ErrNotAdmin) {
+			respondError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, "failed to create event")
 		return
 	}
@@ -35,27 +44,61 @@ func (h *Handlers) CreateEvent(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) GetEvent(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
-	event, err := h.eventsSvc.GetEvent(r.Context(), eventID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "event not found")
+	event, ok := h.viewEvent(w, r, eventID)
+	if !ok {
 		return
 	}
 
 	respondJSON(w, http.StatusOK, event)
 }
 
+// viewEvent loads the event and checks that the caller may see it, taking
+// the share token from the "token" query parameter. On failure it has
+// already written the response.
+func (h *Handlers) viewEvent(w http.ResponseWriter, r *http.Request, eventID shared.ID) (*events.Event, bool) {
+	userID := middleware.GetUserID(r.Context())
+
+	event, err := h.eventsSvc.ViewEvent(r.Context(), eventID, userID, r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, events.ErrEventNotFound) {
+			respondError(w, http.StatusNotFound, "event not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get event")
+		return nil, false
+	}
+
+	return event, true
+}
+
 func (h *Handlers) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	scope, err := events.ParseEditScope(r.URL.Query().Get("scope"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid scope")
+		return
+	}
+
 	var updates events.Event
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request")
 		return
 	}
 
-	if err := h.eventsSvc.UpdateEvent(r.Context(), userID, eventID, &updates); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to update event")
+	if err := h.eventsSvc.UpdateOccurrences(r.Context(), userID, eventID, &updates, scope); err != nil {
+		switch {
+		case errors.Is(err, events.ErrNotSeriesEvent):
+			respondError(w, http.StatusBadRequest, "event is not part of a series")
+		case errors.Is(err, events.ErrInvalidTimeRange),
+			errors.Is(err, events.ErrInvalidCapacity),
+			errors.Is(err, events.ErrInvalidVisibility),
+			errors.Is(err, events.ErrMembershipNoOrg):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update event")
+		}
 		return
 	}
 
@@ -78,7 +121,17 @@ func (h *Handlers) CancelEvent(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
-	if err := h.eventsSvc.CancelEvent(r.Context(), userID, eventID); err != nil {
+	scope, err := events.ParseEditScope(r.URL.Query().Get("scope"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid scope")
+		return
+	}
+
+	if err := h.eventsSvc.CancelOccurrences(r.Context(), userID, eventID, scope); err != nil {
+		if errors.Is(err, events.ErrNotSeriesEvent) {
+			respondError(w, http.StatusBadRequest, "event is not part of a series")
+			return
+		}
 		respondError(w, http.StatusInternalServerError, "failed to cancel event")
 		return
 	}
@@ -86,6 +139,231 @@ func (h *Handlers) CancelEvent(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
 }
 
+func (h *Handlers) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		RRule string     `json:"rrule"`
+		Until *time.Time `json:"until"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	series, err := h.eventsSvc.CreateSeries(r.Context(), userID, eventID, req.RRule, req.Until)
+	if err != nil {
+		switch {
+		case errors.Is(err, events.ErrInvalidRRule):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, events.ErrSeriesExists), errors.Is(err, events.ErrSeriesRequiresStart):
+			respondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, events.ErrUnauthorized):
+			respondError(w, http.StatusForbidden, "forbidden")
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to create series")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, series)
+}
+
+func (h *Handlers) ListOccurrences(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
+	occurrences, err := h.eventsSvc.ListOccurrences(r.Context(), eventID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, occurrences)
+}
+
 func (h *Handlers) ListEvents(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, []interface{}{})
 }
+
+func (h *Handlers) GetShareLink(w http.ResponseWriter, r *http.Request) {
+	h.respondShareLink(w, r, h.eventsSvc.GetShareLink)
+}
+
+func (h *Handlers) RotateShareLink(w http.ResponseWriter, r *http.Request) {
+	h.respondShareLink(w, r, h.eventsSvc.RotateShareLink)
+}
+
+func (h *Handlers) respondShareLink(w http.ResponseWriter, r *http.Request, get func(ctx context.Context, userID, eventID shared.ID) (string, error)) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	token, err := get(r.Context(), userID, eventID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+func (h *Handlers) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if err := h.eventsSvc.RevokeShareLink(r.Context(), userID, eventID); err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
+
+func (h *Handlers) ListInvites(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	invites, err := h.eventsSvc.ListInvites(r.Context(), userID, eventID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string][]shared.ID{"user_ids": invites})
+}
+
+func (h *Handlers) InviteUsers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		UserIDs []shared.ID `json:"user_ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.UserIDs) == 0 {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if err := h.eventsSvc.InviteUsers(r.Context(), userID, eventID, req.UserIDs); err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	inviteeID := shared.ID(chi.URLParam(r, "userID"))
+
+	if err := h.eventsSvc.RevokeInvite(r.Context(), userID, eventID, inviteeID); err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) GetReminders(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	offsets, pending, err := h.eventsSvc.ListReminders(r.Context(), userID, eventID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	minutes := make([]int, 0, len(offsets))
+	for _, d := range offsets {
+		minutes = append(minutes, int(d/time.Minute))
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"offsets_minutes": minutes,
+		"scheduled":       pending,
+	})
+}
+
+// SetReminders replaces the event's reminder offsets. A null list restores
+// the defaults and an empty one turns reminders off.
+func (h *Handlers) SetReminders(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		OffsetsMinutes []int `json:"offsets_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	var offsets []time.Duration
+	if req.OffsetsMinutes != nil {
+		offsets = make([]time.Duration, 0, len(req.OffsetsMinutes))
+		for _, m := range req.OffsetsMinutes {
+			offsets = append(offsets, time.Duration(m)*time.Minute)
+		}
+	}
+
+	if err := h.eventsSvc.SetReminderOffsets(r.Context(), userID, eventID, offsets); err != nil {
+		if errors.Is(err, events.ErrInvalidReminderOffsets) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// SetFormRequirement turns the mandatory registration form on or off.
+func (h *Handlers) SetFormRequirement(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		Required    bool `json:"required"`
+		HoldMinutes int  `json:"hold_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	hold := time.Duration(req.HoldMinutes) * time.Minute
+	event, err := h.eventsSvc.SetFormRequirement(r.Context(), userID, eventID, req.Required, hold)
+	if err != nil {
+		if errors.Is(err, events.ErrInvalidFormHold) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"required":     event.RequiresForm(),
+		"hold_minutes": int(event.FormHold() / time.Minute),
+	})
+}
+
+func respondEventAccessError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, events.ErrEventNotFound):
+		respondError(w, http.StatusNotFound, "event not found")
+	case errors.Is(err, events.ErrUnauthorized):
+		respondError(w, http.StatusForbidden, "forbidden")
+	default:
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	appforms "github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// LocalFiles serves presigned URLs of the local storage backend. It is nil
+// when files live in S3, which serves its own URLs.
+type LocalFiles interface {
+	ServeFile(w http.ResponseWriter, r *http.Request, key string)
+}
+
+// UploadFile accepts a multipart upload with a "file" part and the
+// "field_id" it answers.
+func (h *Handlers) UploadFile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	formID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.viewForm(w, r, formID) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, forms.MaxFileSize+1<<20)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, forms.ErrFileTooLarge.Error())
+			return
+		}
+		respondError(w, http.StatusBadRequest, "invalid multipart request")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	part, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer part.Close()
+
+	contentType, err := detectContentType(header.Header.Get("Content-Type"), part)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+
+	file, err := h.formsSvc.UploadFile(r.Context(), formID, userID, appforms.Upload{
+		FieldID:     r.FormValue("field_id"),
+		Name:        header.Filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		Body:        part,
+	})
+	if err != nil {
+		respondFormError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, fileJSON(file))
+}
+
+// GetFileURL returns a short-lived download link. Uploaders can fetch
+// their own files; organizers with form access can fetch any.
+func (h *Handlers) GetFileURL(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	fileID := shared.ID(chi.URLParam(r, "id"))
+
+	file, err := h.formsSvc.GetFile(r.Context(), fileID)
+	if err != nil {
+		respondFormError(w, err)
+		return
+	}
+	if file.UserID != userID && !h.authorize(w, r, file.EventID, events.PermManageForms) {
+		return
+	}
+
+	url, expiresAt, err := h.formsSvc.FileURL(r.Context(), file)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to sign file url")
+		return
+	}
+
+	body := fileJSON(file)
+	body["url"] = url
+	body["expires_at"] = expiresAt
+	respondJSON(w, http.StatusOK, body)
+}
+
+// ServeLocalFile serves presigned URLs of the local storage backend; the
+// signature in the query string is the only credential.
+func (h *Handlers) ServeLocalFile(w http.ResponseWriter, r *http.Request) {
+	if h.localFiles == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.localFiles.ServeFile(w, r, chi.URLParam(r, "*"))
+}
+
+// DeleteResponse withdraws the user's response to a form together with its
+// uploaded files.
+func (h *Handlers) DeleteResponse(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	formID := shared.ID(chi.URLParam(r, "id"))
+
+	if err := h.formsSvc.DeleteResponse(r.Context(), formID, userID); err != nil {
+		respondFormError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refinable lists the sniffed types that can't tell formats apart, with
+// the declared types each may stand for: office documents are zip
+// archives, CSV is plain text, and unrecognised binaries may be anything
+// the sniffer doesn't know how to spot.
+var refinable = map[string]func(declared string) bool{
+	"application/octet-stream": func(declared string) bool {
+		return !sniffable(declared)
+	},
+	"application/zip": func(declared string) bool {
+		return strings.HasPrefix(declared, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(declared, "application/vnd.oasis.opendocument.")
+	},
+	"text/plain": func(declared string) bool {
+		return declared == "text/csv" || declared == "text/markdown"
+	},
+}
+
+// sniffable reports whether http.DetectContentType recognises the type, in
+// which case a file that didn't sniff as it isn't one.
+func sniffable(mediaType string) bool {
+	for _, prefix := range []string{"image/", "audio/", "video/", "text/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	switch mediaType {
+	case "application/pdf", "application/zip", "application/x-gzip", "application/x-rar-compressed",
+		"application/postscript", "application/ogg", "application/wasm", "application/vnd.ms-fontobject":
+		return true
+	}
+	return false
+}
+
+// detectContentType sniffs the content. The declared type is only used to
+// narrow a sniffed type that is too generic, so a client can't pass off a
+// file as something it isn't.
+func detectContentType(declared string, f io.ReadSeeker) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if declaredType, _, err := mime.ParseMediaType(declared); err == nil {
+		if narrows, ok := refinable[sniffed]; ok && narrows(declaredType) {
+			return declaredType, nil
+		}
+	}
+	return sniffed, nil
+}
+
+func fileJSON(file *forms.File) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           file.ID,
+		"field_id":     file.FieldID,
+		"name":         file.Name,
+		"content_type": file.ContentType,
+		"size":         file.Size,
+		"created_at":   file.CreatedAt,
+	}
+}
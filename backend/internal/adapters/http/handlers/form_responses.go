@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/export"
+	appforms "github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListFormResponses pages through submitted answers. Query parameters:
+// version (defaults to the active form), status (registration status),
+// limit, offset and answer.<field>=<value> filters.
+func (h *Handlers) ListFormResponses(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageForms) {
+		return
+	}
+
+	version, filter, ok := parseResponseQuery(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	page, err := h.formsSvc.ListSubmissions(r.Context(), eventID, version, filter, limit, offset)
+	if err != nil {
+		respondResultsError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+// ExportFormResponses serves the filtered responses as responses.csv or
+// responses.xlsx.
+func (h *Handlers) ExportFormResponses(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageForms) {
+		return
+	}
+
+	version, filter, ok := parseResponseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	format := chi.URLParam(r, "format")
+	if format != "csv" && format != "xlsx" {
+		respondError(w, http.StatusNotFound, "unknown export format")
+		return
+	}
+
+	out := &exportWriter{w: w, format: format}
+	err := h.formsSvc.ExportSubmissions(r.Context(), eventID, version, filter, out)
+	if !out.started {
+		if err != nil {
+			respondResultsError(w, err)
+		}
+		return
+	}
+	if err == nil {
+		err = out.rows.Close()
+	}
+	if err != nil {
+		// The status line is already sent; a truncated file is all the
+		// client can be told.
+		log.Printf("export responses for event %s: %v", eventID, err)
+	}
+}
+
+// exportWriter sends the response headers when the first row arrives, so
+// errors found before that still get a proper status.
+type exportWriter struct {
+	w       http.ResponseWriter
+	format  string
+	started bool
+	rows    export.RowWriter
+}
+
+func (e *exportWriter) WriteRow(cells []string) error {
+	if !e.started {
+		e.started = true
+		contentType := export.ContentTypeCSV
+		if e.format == "xlsx" {
+			contentType = export.ContentTypeXLSX
+		}
+		e.w.Header().Set("Content-Type", contentType)
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=responses.%s", e.format))
+		e.w.WriteHeader(http.StatusOK)
+
+		var err error
+		if e.format == "xlsx" {
+			e.rows, err = export.NewXLSXWriter(e.w, "Responses")
+		} else {
+			e.rows = export.NewCSVWriter(e.w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return e.rows.WriteRow(cells)
+}
+
+// GetFormSummary aggregates the filtered responses per question.
+func (h *Handlers) GetFormSummary(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermViewAnalytics) {
+		return
+	}
+
+	version, filter, ok := parseResponseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.formsSvc.SummarizeSubmissions(r.Context(), eventID, version, filter)
+	if err != nil {
+		respondResultsError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+func parseResponseQuery(w http.ResponseWriter, r *http.Request) (int, appforms.SubmissionFilter, bool) {
+	q := r.URL.Query()
+	filter := appforms.SubmissionFilter{
+		Answers: make(map[string]string),
+		Status:  registrations.Status(q.Get("status")),
+	}
+
+	version := 0
+	if v := q.Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "invalid version")
+			return 0, filter, false
+		}
+		version = n
+	}
+
+	for key, values := range q {
+		if field, ok := strings.CutPrefix(key, "answer."); ok && len(values) > 0 {
+			filter.Answers[field] = values[0]
+		}
+	}
+
+	return version, filter, true
+}
+
+func respondResultsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, forms.ErrInvalidFilter) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondFormError(w, err)
+}
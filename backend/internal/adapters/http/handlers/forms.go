@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	appforms "github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
 )
@@ -14,6 +18,10 @@ import (
 func (h *Handlers) CreateForm(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermManageForms) {
+		return
+	}
+
 	var req struct {
 		Schema json.RawMessage `json:"schema"`
 		Rules  json.RawMessage `json:"rules"`
@@ -24,31 +32,121 @@ func (h *Handlers) CreateForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	form, err := h.formsSvc.CreateForm(r.Context(), eventID, req.Schema, req.Rules)
+	form, migration, err := h.formsSvc.CreateForm(r.Context(), eventID, req.Schema, req.Rules)
+	if errors.Is(err, forms.ErrInvalidSchema) || errors.Is(err, forms.ErrInvalidRules) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to create form")
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, form)
+	respondJSON(w, http.StatusCreated, struct {
+		*forms.Form
+		Migration *appforms.Migration `json:"migration"`
+	}{form, migration})
+}
+
+func (h *Handlers) ListFormVersions(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageForms) {
+		return
+	}
+
+	versions, err := h.formsSvc.ListVersions(r.Context(), eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get forms")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, versions)
+}
+
+func (h *Handlers) DiffFormVersions(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageForms) {
+		return
+	}
+
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		respondError(w, http.StatusBadRequest, "from and to versions are required")
+		return
+	}
+
+	diff, err := h.formsSvc.DiffVersions(r.Context(), eventID, from, to)
+	if err != nil {
+		respondFormError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, diff)
 }
 
 func (h *Handlers) GetActiveForm(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
 	form, err := h.formsSvc.GetActiveForm(r.Context(), eventID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "form not found")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, form)
+	// The rule context lets clients evaluate the form's rules exactly as
+	// the server does on submit.
+	userID := middleware.GetUserID(r.Context())
+	rc, err := h.formsSvc.RuleContext(r.Context(), eventID, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get form")
+		return
+	}
+	prefill, err := h.formsSvc.Prefill(r.Context(), form, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get form")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		*forms.Form
+		Context forms.Context          `json:"context"`
+		Prefill map[string]interface{} `json:"prefill"`
+	}{form, rc, prefill})
 }
 
-func (h *Handlers) SubmitForm(w http.ResponseWriter, r *http.Request) {
+// viewForm lets the request through when the user may see the form's
+// event, the same check GetActiveForm makes by event ID.
+func (h *Handlers) viewForm(w http.ResponseWriter, r *http.Request, formID shared.ID) bool {
+	if !formID.Valid() {
+		respondFormError(w, forms.ErrFormNotFound)
+		return false
+	}
+
+	form, err := h.formsSvc.GetForm(r.Context(), formID)
+	if err != nil {
+		respondFormError(w, err)
+		return false
+	}
+
+	_, ok := h.viewEvent(w, r, form.EventID)
+	return ok
+}
+
+func (h *Handlers) EvaluateForm(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	formID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.viewForm(w, r, formID) {
+		return
+	}
+
 	var req struct {
 		Answers json.RawMessage `json:"answers"`
 	}
@@ -58,34 +156,104 @@ func (h *Handlers) SubmitForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.formsSvc.SubmitResponse(r.Context(), formID, userID, req.Answers)
+	state, err := h.formsSvc.Evaluate(r.Context(), formID, userID, req.Answers)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to submit form")
+		respondFormError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, state)
+}
+
+func (h *Handlers) SubmitForm(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	formID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.viewForm(w, r, formID) {
+		return
+	}
+
+	var req struct {
+		Answers  json.RawMessage `json:"answers"`
+		Remember bool            `json:"remember"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	response, err := h.formsSvc.SubmitResponse(r.Context(), formID, userID, req.Answers, req.Remember)
+	if err != nil {
+		respondFormError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, response)
 }
 
+// respondFormError reports invalid answers per field so clients can show
+// each message next to its input.
+func respondFormError(w http.ResponseWriter, err error) {
+	var verr *forms.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":  "invalid answers",
+			"fields": verr.Fields,
+		})
+	case errors.Is(err, forms.ErrInvalidAnswers):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, forms.ErrFormNotFound), errors.Is(err, forms.ErrVersionNotFound),
+		errors.Is(err, forms.ErrResponseNotFound), errors.Is(err, forms.ErrFileNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, forms.ErrNotFileField):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, forms.ErrFileTooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, forms.ErrFileTypeNotAllowed):
+		respondError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, forms.ErrFormInactive):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to process form")
+	}
+}
+
 func (h *Handlers) GetDraft(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	formID := shared.ID(chi.URLParam(r, "id"))
 
-	draft, ok := h.formsSvc.GetDraft(r.Context(), formID, userID)
-	if !ok {
+	if !h.viewForm(w, r, formID) {
+		return
+	}
+
+	draft, err := h.formsSvc.GetDraft(r.Context(), formID, userID)
+	if errors.Is(err, forms.ErrResponseNotFound) {
 		respondJSON(w, http.StatusOK, map[string]interface{}{})
 		return
 	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to load draft")
+		return
+	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{"draft": draft})
+	respondJSON(w, http.StatusOK, draftJSON(draft))
 }
 
+// SaveDraft autosaves answers. Clients send the revision they last loaded
+// and get 409 with the newer draft when another device saved in between.
 func (h *Handlers) SaveDraft(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	formID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.viewForm(w, r, formID) {
+		return
+	}
+
 	var req struct {
-		Data json.RawMessage `json:"data"`
+		Data     json.RawMessage `json:"data"`
+		Revision *int            `json:"revision"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -93,35 +261,57 @@ func (h *Handlers) SaveDraft(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.formsSvc.SaveDraft(r.Context(), formID, userID, req.Data); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to save draft")
-		return
+	draft, err := h.formsSvc.SaveDraft(r.Context(), formID, userID, req.Data, req.Revision)
+	var conflict *forms.DraftConflictError
+	switch {
+	case errors.As(err, &conflict):
+		body := draftJSON(conflict.Current)
+		body["error"] = err.Error()
+		respondJSON(w, http.StatusConflict, body)
+	case errors.Is(err, forms.ErrAlreadySubmitted):
+		respondError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		respondFormError(w, err)
+	default:
+		respondJSON(w, http.StatusOK, draftJSON(draft))
 	}
+}
 
-	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+func draftJSON(draft *forms.Response) map[string]interface{} {
+	return map[string]interface{}{
+		"draft":    draft.Answers,
+		"revision": draft.Revision,
+		"saved_at": draft.UpdatedAt,
+	}
 }
 
 func (h *Handlers) ScanCheckin(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
 	var req struct {
-		QRCode string `json:"qr_code"`
+		QRCode   string         `json:"qr_code"`
+		Action   checkin.Action `json:"action"`
+		DeviceID string         `json:"device_id"`
+		ZoneID   *shared.ID     `json:"zone_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request")
 		return
 	}
+	if req.ZoneID != nil && *req.ZoneID != "" && !req.ZoneID.Valid() {
+		respondError(w, http.StatusBadRequest, "invalid zone_id")
+		return
+	}
+
+	scanner := scannerFrom(r, req.DeviceID, req.ZoneID)
+	if !h.authorizeScanner(w, r, eventID, scanner, checkin.MethodQR, req.Action, "") {
+		return
+	}
 
-	c, err := h.checkinSvc.ValidateAndCheckin(r.Context(), req.QRCode, checkin.MethodQR)
+	c, err := h.checkinSvc.ValidateAndCheckin(r.Context(), eventID, scanner, req.QRCode, req.Action)
 	if err != nil {
-		if errors.Is(err, checkin.ErrAlreadyCheckedIn) {
-			respondError(w, http.StatusConflict, "already checked in")
-			return
-		}
-		if errors.Is(err, checkin.ErrInvalidQRToken) {
-			respondError(w, http.StatusBadRequest, "invalid qr code")
-			return
-		}
-		respondError(w, http.StatusInternalServerError, "failed to checkin")
+		respondCheckinError(w, err)
 		return
 	}
 
@@ -132,32 +322,59 @@ func (h *Handlers) ManualCheckin(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
 	var req struct {
-		UserID string `json:"user_id"`
+		UserID   shared.ID      `json:"user_id"`
+		Action   checkin.Action `json:"action"`
+		DeviceID string         `json:"device_id"`
+		ZoneID   *shared.ID     `json:"zone_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request")
 		return
 	}
+	if !req.UserID.Valid() {
+		respondError(w, http.StatusBadRequest, "invalid user_id")
+		return
+	}
+	if req.ZoneID != nil && *req.ZoneID != "" && !req.ZoneID.Valid() {
+		respondError(w, http.StatusBadRequest, "invalid zone_id")
+		return
+	}
+
+	scanner := scannerFrom(r, req.DeviceID, req.ZoneID)
+	if !h.authorizeScanner(w, r, eventID, scanner, checkin.MethodManual, req.Action, req.UserID) {
+		return
+	}
 
-	c, err := h.checkinSvc.ManualCheckin(r.Context(), eventID, shared.ID(req.UserID))
+	c, err := h.checkinSvc.ManualCheckin(r.Context(), eventID, scanner, req.UserID, req.Action)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to checkin")
+		respondCheckinError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, c)
 }
 
+// GetQRCode returns the caller's ticket for the event, {id}, with a signed
+// token to render as a QR code.
 func (h *Handlers) GetQRCode(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
-	token, err := h.checkinSvc.GenerateQRToken(r.Context(), userID, eventID)
+	ticket, token, err := h.checkinSvc.IssueTicket(r.Context(), eventID, userID)
+	if errors.Is(err, checkin.ErrNoConfirmedSeat) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to generate qr")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"ticket_id":   ticket.ID,
+		"token":       token,
+		"valid_from":  ticket.ValidFrom,
+		"valid_until": ticket.ValidUntil,
+	})
 }
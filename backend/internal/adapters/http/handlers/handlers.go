@@ -13,7 +13,11 @@ import (
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/identity"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/orgs"
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/tickets"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/notifications"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/Alexander-D-Karpov/kvorum/internal/security"
 )
@@ -29,11 +33,13 @@ type PollsService interface {
 	Vote(ctx context.Context, pollID, userID shared.ID, optionKey string) error
 	GetResults(ctx context.Context, pollID shared.ID) (map[string]int, error)
 	GetPollsByEvent(ctx context.Context, eventID shared.ID) (interface{}, error)
+	GetPollEventID(ctx context.Context, pollID shared.ID) (shared.ID, error)
 }
 
 type CalendarService interface {
 	GenerateEventICS(ctx context.Context, eventID shared.ID) ([]byte, error)
 	GenerateUserICS(ctx context.Context, userID shared.ID) ([]byte, error)
+	GenerateEventsICS(ctx context.Context, eventIDs []shared.ID) ([]byte, error)
 	GetGoogleCalendarLink(ctx context.Context, eventID shared.ID) (string, error)
 }
 
@@ -45,6 +51,7 @@ type AnalyticsService interface {
 type CampaignsService interface {
 	CreateCampaign(ctx context.Context, eventID shared.ID, name, segment, channel, message string, scheduledAt *time.Time) (*campaigns.Campaign, error)
 	GetCampaigns(ctx context.Context, eventID shared.ID) ([]*campaigns.Campaign, error)
+	ListDeliveries(ctx context.Context, eventID, campaignID shared.ID) ([]*notifications.Delivery, error)
 }
 
 type Handlers struct {
@@ -52,11 +59,15 @@ type Handlers struct {
 	eventsSvc        *events.Service
 	formsSvc         *forms.Service
 	registrationsSvc *registrations.Service
+	ticketsSvc       *tickets.Service
+	orgsSvc          *orgs.Service
 	checkinSvc       *checkin.Service
 	pollsSvc         PollsService
 	calendarSvc      CalendarService
 	analyticsSvc     AnalyticsService
 	campaignsSvc     CampaignsService
+	liveSvc          *live.Service
+	localFiles       LocalFiles
 	botClient        *botmax.Client
 	cache            Cache
 	webhookSecret    string
@@ -68,11 +79,15 @@ func NewHandlers(
 	eventsSvc *events.Service,
 	formsSvc *forms.Service,
 	registrationsSvc *registrations.Service,
+	ticketsSvc *tickets.Service,
+	orgsSvc *orgs.Service,
 	checkinSvc *checkin.Service,
 	pollsSvc PollsService,
 	calendarSvc CalendarService,
 	analyticsSvc AnalyticsService,
 	campaignsSvc CampaignsService,
+	liveSvc *live.Service,
+	localFiles LocalFiles,
 	botClient *botmax.Client,
 	cache Cache,
 	webhookSecret string,
@@ -83,11 +98,15 @@ func NewHandlers(
 		eventsSvc:        eventsSvc,
 		formsSvc:         formsSvc,
 		registrationsSvc: registrationsSvc,
+		ticketsSvc:       ticketsSvc,
+		orgsSvc:          orgsSvc,
 		checkinSvc:       checkinSvc,
 		pollsSvc:         pollsSvc,
 		calendarSvc:      calendarSvc,
 		analyticsSvc:     analyticsSvc,
 		campaignsSvc:     campaignsSvc,
+		liveSvc:          liveSvc,
+		localFiles:       localFiles,
 		botClient:        botClient,
 		cache:            cache,
 		webhookSecret:    webhookSecret,
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// liveKeepAlive keeps proxies from dropping a quiet stream. Each tick also
+// checks that the viewer still has access, so a revoked role ends the
+// stream within one period.
+const liveKeepAlive = 25 * time.Second
+
+// StreamEventLive is a server-sent event stream of the event's check-ins,
+// RSVP changes, waitlist moves and registrations. It opens with a
+// snapshot of the counts; every later message carries fresh counts too.
+func (h *Handlers) StreamEventLive(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	userID := middleware.GetUserID(r.Context())
+
+	if !h.authorize(w, r, eventID, events.PermViewAnalytics) {
+		return
+	}
+
+	ctx := r.Context()
+	updates, err := h.liveSvc.Subscribe(ctx, eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to subscribe to live updates")
+		return
+	}
+	snapshot, err := h.liveSvc.Snapshot(ctx, eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get live counts")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary requests.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		respondError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := writeLiveUpdate(w, snapshot); err != nil || rc.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(liveKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := writeLiveUpdate(w, u); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := h.eventsSvc.Authorize(ctx, eventID, userID, events.PermViewAnalytics); err != nil {
+				return
+			}
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeLiveUpdate(w io.Writer, u *live.Update) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", u.Kind, data)
+	return err
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+type orgRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (h *Handlers) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req orgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	org, err := h.orgsSvc.CreateOrganization(r.Context(), userID, req.Name, req.Description)
+	if err != nil {
+		respondOrgError(w, err, "failed to create organization")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, org)
+}
+
+func (h *Handlers) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	org, err := h.orgsSvc.GetOrganization(r.Context(), orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to get organization")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, org)
+}
+
+func (h *Handlers) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	var req orgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	org, err := h.orgsSvc.UpdateOrganization(r.Context(), userID, orgID, req.Name, req.Description)
+	if err != nil {
+		respondOrgError(w, err, "failed to update organization")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, org)
+}
+
+func (h *Handlers) ListMyOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	list, err := h.orgsSvc.ListMyOrganizations(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list organizations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+func (h *Handlers) ListOrgMembers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	members, err := h.orgsSvc.ListMembers(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to list members")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, members)
+}
+
+func (h *Handlers) UpdateOrgMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+	memberID := shared.ID(chi.URLParam(r, "userID"))
+
+	var req struct {
+		Role orgs.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if err := h.orgsSvc.UpdateMemberRole(r.Context(), userID, orgID, memberID, req.Role); err != nil {
+		respondOrgError(w, err, "failed to update member")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) RemoveOrgMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+	memberID := shared.ID(chi.URLParam(r, "userID"))
+
+	if err := h.orgsSvc.RemoveMember(r.Context(), userID, orgID, memberID); err != nil {
+		respondOrgError(w, err, "failed to remove member")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) RequestToJoinOrg(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	joinReq, err := h.orgsSvc.RequestToJoin(r.Context(), userID, orgID, req.Message)
+	if err != nil {
+		respondOrgError(w, err, "failed to request membership")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, joinReq)
+}
+
+func (h *Handlers) ListOrgJoinRequests(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	list, err := h.orgsSvc.ListJoinRequests(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to list join requests")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+func (h *Handlers) ApproveOrgJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+	requestID := shared.ID(chi.URLParam(r, "requestID"))
+
+	joinReq, err := h.orgsSvc.ApproveJoinRequest(r.Context(), userID, orgID, requestID)
+	if err != nil {
+		respondOrgError(w, err, "failed to approve join request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, joinReq)
+}
+
+func (h *Handlers) RejectOrgJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+	requestID := shared.ID(chi.URLParam(r, "requestID"))
+
+	joinReq, err := h.orgsSvc.RejectJoinRequest(r.Context(), userID, orgID, requestID)
+	if err != nil {
+		respondOrgError(w, err, "failed to reject join request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, joinReq)
+}
+
+func (h *Handlers) GetOrgInviteLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	token, err := h.orgsSvc.GetInviteLink(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to get invite link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+func (h *Handlers) RotateOrgInviteLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	token, err := h.orgsSvc.RotateInviteLink(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to rotate invite link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+func (h *Handlers) RevokeOrgInviteLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	if err := h.orgsSvc.RevokeInviteLink(r.Context(), userID, orgID); err != nil {
+		respondOrgError(w, err, "failed to revoke invite link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) GetOrgFeedLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	token, err := h.orgsSvc.GetFeedToken(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to get feed link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+func (h *Handlers) RotateOrgFeedLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	token, err := h.orgsSvc.RotateFeedToken(r.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(w, err, "failed to rotate feed link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+func (h *Handlers) JoinOrgByInvite(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	token := chi.URLParam(r, "token")
+
+	org, err := h.orgsSvc.JoinByInvite(r.Context(), userID, token)
+	if err != nil {
+		respondOrgError(w, err, "failed to join organization")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, org)
+}
+
+func (h *Handlers) ListOrgEvents(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	if _, err := h.orgsSvc.GetOrganization(r.Context(), orgID); err != nil {
+		respondOrgError(w, err, "failed to list events")
+		return
+	}
+
+	list, err := h.eventsSvc.ListOrganizationEvents(r.Context(), orgID, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list events")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// GetOrgICS serves the organization's calendar feed. Like the event list it
+// only contains events the caller may see. Calendar clients subscribe with
+// the member's feed token instead of a session.
+func (h *Handlers) GetOrgICS(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	orgID := shared.ID(chi.URLParam(r, "id"))
+
+	if _, err := h.orgsSvc.GetOrganization(r.Context(), orgID); err != nil {
+		respondOrgError(w, err, "failed to generate ics")
+		return
+	}
+
+	if token := r.URL.Query().Get("token"); token != "" {
+		member, err := h.orgsSvc.FeedMember(r.Context(), orgID, token)
+		if err != nil {
+			respondOrgError(w, err, "failed to generate ics")
+			return
+		}
+		userID = member
+	}
+
+	list, err := h.eventsSvc.ListOrganizationEvents(r.Context(), orgID, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to generate ics")
+		return
+	}
+
+	ids := make([]shared.ID, 0, len(list))
+	for _, event := range list {
+		ids = append(ids, event.ID)
+	}
+
+	ics, err := h.calendarSvc.GenerateEventsICS(r.Context(), ids)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to generate ics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=organization.ics")
+	w.Write(ics)
+}
+
+func respondOrgError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, orgs.ErrOrgNotFound),
+		errors.Is(err, orgs.ErrJoinRequestNotFound),
+		errors.Is(err, orgs.ErrInvalidInvite),
+		errors.Is(err, orgs.ErrInvalidFeedToken):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, orgs.ErrNotAdmin), errors.Is(err, orgs.ErrNotMember):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, orgs.ErrAlreadyMember),
+		errors.Is(err, orgs.ErrLastAdmin),
+		errors.Is(err, orgs.ErrJoinRequestDecided):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, orgs.ErrInvalidOrg), errors.Is(err, orgs.ErrInvalidRole):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/polls"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
@@ -13,6 +14,10 @@ import (
 func (h *Handlers) CreatePoll(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.authorize(w, r, eventID, events.PermManagePolls) {
+		return
+	}
+
 	var req struct {
 		Question string          `json:"question"`
 		Options  json.RawMessage `json:"options"`
@@ -37,6 +42,10 @@ func (h *Handlers) CreatePoll(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) GetEventPolls(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
 	polls, err := h.pollsSvc.GetPollsByEvent(r.Context(), eventID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to get polls")
@@ -59,6 +68,10 @@ func (h *Handlers) VoteOnPoll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.viewPoll(w, r, pollID) {
+		return
+	}
+
 	if err := h.pollsSvc.Vote(r.Context(), pollID, userID, req.OptionKey); err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to vote")
 		return
@@ -70,6 +83,10 @@ func (h *Handlers) VoteOnPoll(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) GetPollResults(w http.ResponseWriter, r *http.Request) {
 	pollID := shared.ID(chi.URLParam(r, "id"))
 
+	if !h.viewPoll(w, r, pollID) {
+		return
+	}
+
 	results, err := h.pollsSvc.GetResults(r.Context(), pollID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to get results")
@@ -78,3 +95,15 @@ func (h *Handlers) GetPollResults(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, results)
 }
+
+// viewPoll applies the visibility of the poll's event.
+func (h *Handlers) viewPoll(w http.ResponseWriter, r *http.Request, pollID shared.ID) bool {
+	eventID, err := h.pollsSvc.GetPollEventID(r.Context(), pollID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "poll not found")
+		return false
+	}
+
+	_, ok := h.viewEvent(w, r, eventID)
+	return ok
+}
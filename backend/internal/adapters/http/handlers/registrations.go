@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	appregistrations "github.com/Alexander-D-Karpov/kvorum/internal/app/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/go-chi/chi/v5"
@@ -15,17 +21,91 @@ func (h *Handlers) RegisterForEvent(w http.ResponseWriter, r *http.Request) {
 	eventID := shared.ID(chi.URLParam(r, "id"))
 
 	var req struct {
-		Source string                 `json:"source"`
-		UTM    map[string]interface{} `json:"utm"`
+		Source       string                 `json:"source"`
+		UTM          map[string]interface{} `json:"utm"`
+		Series       bool                   `json:"series"`
+		TicketTypeID *shared.ID             `json:"ticket_type_id"`
+		Quantity     int                    `json:"quantity"`
+		UnlockCode   string                 `json:"unlock_code"`
+		// Answers to the event's active form, submitted together with the
+		// registration so that events requiring the form seat the attendee
+		// right away. Not accepted for a whole series.
+		Answers  json.RawMessage `json:"answers"`
+		Remember bool            `json:"remember"`
 	}
 
 	json.NewDecoder(r.Body).Decode(&req)
 
 	utmBytes, _ := json.Marshal(req.UTM)
 
-	reg, err := h.registrationsSvc.Register(r.Context(), eventID, userID, req.Source, utmBytes)
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
+	if req.Series && len(req.Answers) > 0 {
+		respondError(w, http.StatusBadRequest, "answers cannot be submitted for a whole series")
+		return
+	}
+
+	if req.Series {
+		occurrences, err := h.eventsSvc.ListUpcomingOccurrences(r.Context(), eventID)
+		if err != nil {
+			respondError(w, http.StatusNotFound, "event not found")
+			return
+		}
+
+		eventIDs := make([]shared.ID, 0, len(occurrences))
+		for _, occ := range occurrences {
+			eventIDs = append(eventIDs, occ.ID)
+		}
+
+		regs, err := h.registrationsSvc.RegisterMany(r.Context(), eventIDs, userID, req.Source, utmBytes)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to register")
+			return
+		}
+
+		respondJSON(w, http.StatusCreated, regs)
+		return
+	}
+
+	ticket := appregistrations.TicketSelection{
+		TypeID:     req.TicketTypeID,
+		Quantity:   req.Quantity,
+		UnlockCode: req.UnlockCode,
+	}
+
+	var (
+		reg *registrations.Registration
+		err error
+	)
+	register := func(ctx context.Context) error {
+		reg, err = h.registrationsSvc.Register(ctx, eventID, userID, ticket, req.Source, utmBytes)
+		// Answers from someone already registered are kept all the same.
+		if errors.Is(err, registrations.ErrAlreadyRegistered) {
+			return nil
+		}
+		return err
+	}
+
+	if len(req.Answers) == 0 {
+		_ = register(r.Context())
+	} else if serr := h.formsSvc.SubmitAndRegister(r.Context(), eventID, userID, req.Answers, req.Remember, register); serr != nil && !errors.Is(serr, err) {
+		// Not the registration's own error: the answers were refused or
+		// nothing was committed.
+		respondFormError(w, serr)
+		return
+	}
+
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to register")
+		switch {
+		case errors.Is(err, registrations.ErrAlreadyRegistered):
+			respondJSON(w, http.StatusOK, reg)
+		case errors.Is(err, registrations.ErrCapacityReached):
+			respondError(w, http.StatusConflict, "event capacity reached")
+		default:
+			respondTicketError(w, err, "failed to register")
+		}
 		return
 	}
 
@@ -45,12 +125,24 @@ func (h *Handlers) UpdateRSVP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.registrationsSvc.UpdateRSVP(r.Context(), eventID, userID, registrations.Status(req.Status)); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to update rsvp")
+	reg, err := h.registrationsSvc.UpdateRSVP(r.Context(), eventID, userID, registrations.Status(req.Status))
+	if err != nil {
+		switch {
+		case errors.Is(err, registrations.ErrInvalidStatus):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, registrations.ErrRegistrationNotFound):
+			respondError(w, http.StatusNotFound, "registration not found")
+		case errors.Is(err, registrations.ErrCapacityReached):
+			respondError(w, http.StatusConflict, "event capacity reached")
+		case errors.Is(err, registrations.ErrAwaitingApproval), errors.Is(err, registrations.ErrRegistrationRejected):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update rsvp")
+		}
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	respondJSON(w, http.StatusOK, reg)
 }
 
 func (h *Handlers) CancelRegistration(w http.ResponseWriter, r *http.Request) {
@@ -64,3 +156,81 @@ func (h *Handlers) CancelRegistration(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
 }
+
+func (h *Handlers) ListRegistrations(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var statuses []registrations.Status
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		for _, status := range strings.Split(raw, ",") {
+			statuses = append(statuses, registrations.Status(status))
+		}
+	}
+
+	regs, err := h.registrationsSvc.ListRegistrations(r.Context(), userID, eventID, statuses)
+	if err != nil {
+		if errors.Is(err, events.ErrUnauthorized) {
+			respondError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, regs)
+}
+
+type decisionRequest struct {
+	UserIDs []shared.ID `json:"user_ids"`
+	Reason  string      `json:"reason"`
+}
+
+// ApproveRegistrations handles both the bulk endpoint, which takes user_ids in
+// the body, and the single one with the applicant in the path.
+func (h *Handlers) ApproveRegistrations(w http.ResponseWriter, r *http.Request) {
+	h.decideRegistrations(w, r, h.registrationsSvc.ApproveRegistrations)
+}
+
+func (h *Handlers) RejectRegistrations(w http.ResponseWriter, r *http.Request) {
+	h.decideRegistrations(w, r, h.registrationsSvc.RejectRegistrations)
+}
+
+func (h *Handlers) decideRegistrations(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, organizerID, eventID shared.ID, userIDs []shared.ID, reason string) ([]*registrations.Registration, error),
+) {
+	organizerID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req decisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if userID := chi.URLParam(r, "userID"); userID != "" {
+		req.UserIDs = []shared.ID{shared.ID(userID)}
+	}
+	if len(req.UserIDs) == 0 {
+		respondError(w, http.StatusBadRequest, "user_ids required")
+		return
+	}
+
+	regs, err := decide(r.Context(), organizerID, eventID, req.UserIDs, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, events.ErrUnauthorized):
+			respondError(w, http.StatusForbidden, "forbidden")
+		case errors.Is(err, registrations.ErrRegistrationNotFound):
+			respondError(w, http.StatusNotFound, "registration not found")
+		case errors.Is(err, registrations.ErrNotPending), errors.Is(err, registrations.ErrCapacityReached):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to record decision")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, regs)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+// authorize checks that the caller holds perm on the event. On failure it
+// has already written the response.
+func (h *Handlers) authorize(w http.ResponseWriter, r *http.Request, eventID shared.ID, perm events.Permission) bool {
+	userID := middleware.GetUserID(r.Context())
+
+	if _, err := h.eventsSvc.Authorize(r.Context(), eventID, userID, perm); err != nil {
+		respondEventAccessError(w, err)
+		return false
+	}
+
+	return true
+}
+
+func (h *Handlers) GetMyPermissions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	role, perms, err := h.eventsSvc.MyPermissions(r.Context(), eventID, userID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"role":        role,
+		"permissions": perms,
+	})
+}
+
+func (h *Handlers) ListRoles(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	roles, err := h.eventsSvc.ListRoles(r.Context(), userID, eventID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, roles)
+}
+
+func (h *Handlers) GrantRole(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		UserID shared.ID   `json:"user_id"`
+		Role   events.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if err := h.eventsSvc.GrantRole(r.Context(), userID, eventID, req.UserID, req.Role); err != nil {
+		respondRoleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	targetID := shared.ID(chi.URLParam(r, "userID"))
+
+	if err := h.eventsSvc.RevokeRole(r.Context(), userID, eventID, targetID); err != nil {
+		respondRoleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) CreateRoleInvite(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		Role           events.Role `json:"role"`
+		ExpiresInHours int         `json:"expires_in_hours"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ExpiresInHours < 0 {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+	invite, err := h.eventsSvc.CreateRoleInvite(r.Context(), userID, eventID, req.Role, ttl)
+	if err != nil {
+		respondRoleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, invite)
+}
+
+func (h *Handlers) ListRoleInvites(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	invites, err := h.eventsSvc.ListRoleInvites(r.Context(), userID, eventID)
+	if err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invites)
+}
+
+func (h *Handlers) RevokeRoleInvite(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	inviteID := shared.ID(chi.URLParam(r, "inviteID"))
+
+	if err := h.eventsSvc.RevokeRoleInvite(r.Context(), userID, eventID, inviteID); err != nil {
+		respondEventAccessError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) AcceptRoleInvite(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	token := chi.URLParam(r, "token")
+
+	invite, err := h.eventsSvc.AcceptRoleInvite(r.Context(), userID, token)
+	if err != nil {
+		respondRoleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"event_id": invite.EventID,
+		"role":     invite.Role,
+	})
+}
+
+func (h *Handlers) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req struct {
+		UserID shared.ID `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	event, err := h.eventsSvc.TransferOwnership(r.Context(), userID, eventID, req.UserID)
+	if err != nil {
+		respondRoleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, event)
+}
+
+func respondRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, events.ErrInvalidRole):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, events.ErrCannotChangeOwner):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, events.ErrRoleInviteInvalid):
+		respondError(w, http.StatusNotFound, err.Error())
+	default:
+		respondEventAccessError(w, err)
+	}
+}
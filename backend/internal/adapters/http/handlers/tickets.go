@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/http/middleware"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/tickets"
+	"github.com/go-chi/chi/v5"
+)
+
+type ticketTypeRequest struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Price        int64               `json:"price"`
+	Currency     string              `json:"currency"`
+	PriceTiers   []tickets.PriceTier `json:"price_tiers"`
+	Capacity     int                 `json:"capacity"`
+	SalesStart   *time.Time          `json:"sales_start"`
+	SalesEnd     *time.Time          `json:"sales_end"`
+	Hidden       bool                `json:"hidden"`
+	UnlockCode   string              `json:"unlock_code"`
+	PerUserLimit *int                `json:"per_user_limit"`
+	SortOrder    int                 `json:"sort_order"`
+}
+
+func (req *ticketTypeRequest) apply(t *tickets.TicketType) {
+	t.Name = req.Name
+	t.Description = req.Description
+	t.Price = req.Price
+	if req.Currency != "" {
+		t.Currency = req.Currency
+	}
+	t.PriceTiers = req.PriceTiers
+	t.Capacity = req.Capacity
+	t.SalesStart = req.SalesStart
+	t.SalesEnd = req.SalesEnd
+	t.Hidden = req.Hidden
+	t.UnlockCode = req.UnlockCode
+	if req.PerUserLimit != nil {
+		t.PerUserLimit = *req.PerUserLimit
+	}
+	t.SortOrder = req.SortOrder
+}
+
+type ticketTypeResponse struct {
+	*tickets.TicketType
+	CurrentPrice int64 `json:"CurrentPrice"`
+}
+
+func (h *Handlers) ListTicketTypes(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if _, ok := h.viewEvent(w, r, eventID); !ok {
+		return
+	}
+
+	types, err := h.ticketsSvc.ListTicketTypes(r.Context(), eventID, userID, r.URL.Query().Get("code"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	now := time.Now()
+	result := make([]ticketTypeResponse, 0, len(types))
+	for _, t := range types {
+		result = append(result, ticketTypeResponse{TicketType: t, CurrentPrice: t.CurrentPrice(now)})
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *Handlers) CreateTicketType(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	var req ticketTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	t := tickets.NewTicketType(eventID, req.Name)
+	req.apply(t)
+
+	t, err := h.ticketsSvc.CreateTicketType(r.Context(), userID, t)
+	if err != nil {
+		respondTicketError(w, err, "failed to create ticket type")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, t)
+}
+
+func (h *Handlers) UpdateTicketType(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := shared.ID(chi.URLParam(r, "id"))
+
+	var req ticketTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	updates := &tickets.TicketType{PerUserLimit: 1}
+	req.apply(updates)
+
+	t, err := h.ticketsSvc.UpdateTicketType(r.Context(), userID, id, updates)
+	if err != nil {
+		respondTicketError(w, err, "failed to update ticket type")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, t)
+}
+
+func (h *Handlers) DeleteTicketType(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := shared.ID(chi.URLParam(r, "id"))
+
+	if err := h.ticketsSvc.DeleteTicketType(r.Context(), userID, id); err != nil {
+		respondTicketError(w, err, "failed to delete ticket type")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// respondTicketError maps ticket type errors shared by the ticket and
+// registration endpoints; anything unknown becomes a 500 with fallback.
+func respondTicketError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, tickets.ErrTicketTypeNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, events.ErrUnauthorized), errors.Is(err, tickets.ErrTicketTypeLocked):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, tickets.ErrSalesNotStarted), errors.Is(err, tickets.ErrSalesEnded):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, tickets.ErrTicketTypeRequired),
+		errors.Is(err, tickets.ErrPerUserLimit),
+		errors.Is(err, tickets.ErrInvalidQuantity),
+		errors.Is(err, tickets.ErrInvalidSalesWindow),
+		errors.Is(err, tickets.ErrInvalidTicketConfig):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, fallback)
+	}
+}
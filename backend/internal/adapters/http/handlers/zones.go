@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/go-chi/chi/v5"
+)
+
+type zoneRequest struct {
+	Name          string        `json:"name"`
+	TicketTypeIDs []shared.ID   `json:"ticket_type_ids"`
+	Roles         []events.Role `json:"roles"`
+	SortOrder     int           `json:"sort_order"`
+}
+
+func (req *zoneRequest) apply(z *checkin.Zone) {
+	z.Name = req.Name
+	z.TicketTypeIDs = req.TicketTypeIDs
+	if z.TicketTypeIDs == nil {
+		z.TicketTypeIDs = []shared.ID{}
+	}
+	z.Roles = req.Roles
+	if z.Roles == nil {
+		z.Roles = []events.Role{}
+	}
+	z.SortOrder = req.SortOrder
+}
+
+func respondZoneError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, checkin.ErrZoneNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, checkin.ErrInvalidZone), errors.Is(err, events.ErrInvalidRole):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, checkin.ErrZoneInUse):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// ListZones is open to door staff, whose scanners pick the zone they
+// guard from it.
+func (h *Handlers) ListZones(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermScanCheckin) {
+		return
+	}
+
+	zones, err := h.checkinSvc.ListZones(r.Context(), eventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get zones")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, zones)
+}
+
+func (h *Handlers) CreateZone(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+
+	if !h.authorize(w, r, eventID, events.PermManageTickets) {
+		return
+	}
+
+	var req zoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	z := checkin.NewZone(eventID, req.Name)
+	req.apply(z)
+
+	z, err := h.checkinSvc.CreateZone(r.Context(), z)
+	if err != nil {
+		respondZoneError(w, err, "failed to create zone")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, z)
+}
+
+func (h *Handlers) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	zoneID := shared.ID(chi.URLParam(r, "zoneID"))
+
+	if !h.authorize(w, r, eventID, events.PermManageTickets) {
+		return
+	}
+
+	var req zoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	updates := &checkin.Zone{}
+	req.apply(updates)
+
+	z, err := h.checkinSvc.UpdateZone(r.Context(), eventID, zoneID, updates)
+	if err != nil {
+		respondZoneError(w, err, "failed to update zone")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, z)
+}
+
+func (h *Handlers) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	eventID := shared.ID(chi.URLParam(r, "id"))
+	zoneID := shared.ID(chi.URLParam(r, "zoneID"))
+
+	if !h.authorize(w, r, eventID, events.PermManageTickets) {
+		return
+	}
+
+	if err := h.checkinSvc.DeleteZone(r.Context(), eventID, zoneID); err != nil {
+		respondZoneError(w, err, "failed to delete zone")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -47,6 +47,27 @@ func (m *Middleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalAuth attaches the user when a valid session is present and lets
+// anonymous requests through otherwise.
+func (m *Middleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, err := m.sessionStore.GetSession(r.Context(), cookie.Value)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, session.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
 func GetUserID(ctx context.Context) shared.ID {
 	if userID, ok := ctx.Value(userIDKey).(shared.ID); ok {
 		return userID
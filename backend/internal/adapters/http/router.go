@@ -45,54 +45,167 @@ func NewRouter(h *handlers.Handlers, m *middleware.Middleware) *Router {
 		r.Route("/events", func(r chi.Router) {
 			r.Get("/", h.ListEvents)
 			r.Post("/", m.RequireAuth(h.CreateEvent))
-			r.Get("/{id}", h.GetEvent)
+			r.Get("/{id}", m.OptionalAuth(h.GetEvent))
 			r.Put("/{id}", m.RequireAuth(h.UpdateEvent))
 			r.Post("/{id}/publish", m.RequireAuth(h.PublishEvent))
 			r.Post("/{id}/cancel", m.RequireAuth(h.CancelEvent))
+			r.Post("/{id}/series", m.RequireAuth(h.CreateSeries))
+			r.Get("/{id}/occurrences", m.OptionalAuth(h.ListOccurrences))
+			r.Get("/{id}/share-link", m.RequireAuth(h.GetShareLink))
+			r.Post("/{id}/share-link", m.RequireAuth(h.RotateShareLink))
+			r.Delete("/{id}/share-link", m.RequireAuth(h.RevokeShareLink))
+
+			r.Get("/{id}/reminders", m.RequireAuth(h.GetReminders))
+			r.Put("/{id}/reminders", m.RequireAuth(h.SetReminders))
+			r.Put("/{id}/form-requirement", m.RequireAuth(h.SetFormRequirement))
+			r.Get("/{id}/permissions", m.RequireAuth(h.GetMyPermissions))
+			r.Post("/{id}/transfer", m.RequireAuth(h.TransferOwnership))
+
+			r.Route("/{id}/roles", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListRoles))
+				r.Post("/", m.RequireAuth(h.GrantRole))
+				r.Delete("/{userID}", m.RequireAuth(h.RevokeRole))
+			})
+
+			r.Route("/{id}/role-invites", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListRoleInvites))
+				r.Post("/", m.RequireAuth(h.CreateRoleInvite))
+				r.Delete("/{inviteID}", m.RequireAuth(h.RevokeRoleInvite))
+			})
+
+			r.Route("/{id}/invites", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListInvites))
+				r.Post("/", m.RequireAuth(h.InviteUsers))
+				r.Delete("/{userID}", m.RequireAuth(h.RevokeInvite))
+			})
+
 			r.Post("/{id}/register", m.RequireAuth(h.RegisterForEvent))
 			r.Post("/{id}/rsvp", m.RequireAuth(h.UpdateRSVP))
 			r.Delete("/{id}/register", m.RequireAuth(h.CancelRegistration))
 
+			r.Route("/{id}/registrations", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListRegistrations))
+				r.Post("/approve", m.RequireAuth(h.ApproveRegistrations))
+				r.Post("/reject", m.RequireAuth(h.RejectRegistrations))
+				r.Post("/{userID}/approve", m.RequireAuth(h.ApproveRegistrations))
+				r.Post("/{userID}/reject", m.RequireAuth(h.RejectRegistrations))
+			})
+
+			r.Route("/{id}/ticket-types", func(r chi.Router) {
+				r.Get("/", m.OptionalAuth(h.ListTicketTypes))
+				r.Post("/", m.RequireAuth(h.CreateTicketType))
+			})
+
 			r.Route("/{id}/forms", func(r chi.Router) {
-				r.Get("/active", h.GetActiveForm)
+				r.Get("/", m.RequireAuth(h.ListFormVersions))
+				r.Get("/diff", m.RequireAuth(h.DiffFormVersions))
+				r.Get("/active", m.OptionalAuth(h.GetActiveForm))
+				r.Get("/responses", m.RequireAuth(h.ListFormResponses))
+				r.Get("/responses.{format}", m.RequireAuth(h.ExportFormResponses))
+				r.Get("/summary", m.RequireAuth(h.GetFormSummary))
 				r.Post("/", m.RequireAuth(h.CreateForm))
 			})
 
+			r.Route("/{id}/zones", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListZones))
+				r.Post("/", m.RequireAuth(h.CreateZone))
+				r.Put("/{zoneID}", m.RequireAuth(h.UpdateZone))
+				r.Delete("/{zoneID}", m.RequireAuth(h.DeleteZone))
+			})
+
 			r.Post("/{id}/checkin/scan", m.RequireAuth(h.ScanCheckin))
 			r.Post("/{id}/checkin/manual", m.RequireAuth(h.ManualCheckin))
+			r.Get("/{id}/checkin/occupancy", m.RequireAuth(h.GetOccupancy))
+			r.Get("/{id}/checkin/attempts", m.RequireAuth(h.GetCheckinAttempts))
+			r.Get("/{id}/checkin/manifest", m.RequireAuth(h.GetCheckinManifest))
+			r.Post("/{id}/checkin/sync", m.RequireAuth(h.SyncCheckins))
+			r.Get("/{id}/checkin/devices", m.RequireAuth(h.GetScannerDevices))
+			r.Get("/{id}/tickets/revoked", m.RequireAuth(h.GetRevokedTickets))
+			r.Post("/{id}/tickets/{ticketID}/revoke", m.RequireAuth(h.RevokeTicket))
 
 			r.Route("/{id}/polls", func(r chi.Router) {
-				r.Get("/", h.GetEventPolls)
+				r.Get("/", m.OptionalAuth(h.GetEventPolls))
 				r.Post("/", m.RequireAuth(h.CreatePoll))
 			})
 
 			r.Route("/{id}/campaigns", func(r chi.Router) {
 				r.Get("/", m.RequireAuth(h.GetCampaigns))
 				r.Post("/", m.RequireAuth(h.CreateCampaign))
+				r.Get("/{campaignID}/deliveries", m.RequireAuth(h.GetCampaignDeliveries))
 			})
 
-			r.Get("/{id}/ics", h.GetEventICS)
-			r.Get("/{id}/google-calendar", h.GetGoogleCalendarLink)
+			r.Get("/{id}/ics", m.OptionalAuth(h.GetEventICS))
+			r.Get("/{id}/google-calendar", m.OptionalAuth(h.GetGoogleCalendarLink))
 			r.Get("/{id}/analytics", m.RequireAuth(h.GetEventAnalytics))
 			r.Get("/{id}/analytics.csv", m.RequireAuth(h.ExportEventAnalyticsCSV))
+			r.Get("/{id}/live", m.RequireAuth(h.StreamEventLive))
+		})
+
+		r.Route("/orgs", func(r chi.Router) {
+			r.Post("/", m.RequireAuth(h.CreateOrganization))
+			r.Post("/join/{token}", m.RequireAuth(h.JoinOrgByInvite))
+			r.Get("/{id}", h.GetOrganization)
+			r.Put("/{id}", m.RequireAuth(h.UpdateOrganization))
+			r.Get("/{id}/events", m.OptionalAuth(h.ListOrgEvents))
+			r.Get("/{id}/events.ics", m.OptionalAuth(h.GetOrgICS))
+
+			r.Route("/{id}/members", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListOrgMembers))
+				r.Put("/{userID}", m.RequireAuth(h.UpdateOrgMember))
+				r.Delete("/{userID}", m.RequireAuth(h.RemoveOrgMember))
+			})
+
+			r.Post("/{id}/join", m.RequireAuth(h.RequestToJoinOrg))
+			r.Route("/{id}/join-requests", func(r chi.Router) {
+				r.Get("/", m.RequireAuth(h.ListOrgJoinRequests))
+				r.Post("/{requestID}/approve", m.RequireAuth(h.ApproveOrgJoinRequest))
+				r.Post("/{requestID}/reject", m.RequireAuth(h.RejectOrgJoinRequest))
+			})
+
+			r.Get("/{id}/invite-link", m.RequireAuth(h.GetOrgInviteLink))
+			r.Post("/{id}/invite-link", m.RequireAuth(h.RotateOrgInviteLink))
+			r.Delete("/{id}/invite-link", m.RequireAuth(h.RevokeOrgInviteLink))
+			r.Get("/{id}/feed-link", m.RequireAuth(h.GetOrgFeedLink))
+			r.Post("/{id}/feed-link", m.RequireAuth(h.RotateOrgFeedLink))
 		})
 
 		r.Route("/forms", func(r chi.Router) {
 			r.Post("/{id}/submit", m.RequireAuth(h.SubmitForm))
+			r.Post("/{id}/evaluate", m.OptionalAuth(h.EvaluateForm))
 			r.Get("/{id}/draft", m.RequireAuth(h.GetDraft))
 			r.Put("/{id}/draft", m.RequireAuth(h.SaveDraft))
+			r.Post("/{id}/files", m.RequireAuth(h.UploadFile))
+			r.Delete("/{id}/response", m.RequireAuth(h.DeleteResponse))
+		})
+
+		r.Route("/files", func(r chi.Router) {
+			r.Get("/local/*", h.ServeLocalFile)
+			r.Get("/{id}/url", m.RequireAuth(h.GetFileURL))
+		})
+
+		r.Post("/role-invites/{token}/accept", m.RequireAuth(h.AcceptRoleInvite))
+
+		r.Route("/ticket-types", func(r chi.Router) {
+			r.Put("/{id}", m.RequireAuth(h.UpdateTicketType))
+			r.Delete("/{id}", m.RequireAuth(h.DeleteTicketType))
 		})
 
 		r.Route("/tickets", func(r chi.Router) {
+			r.Get("/keys", h.GetTicketKeys)
 			r.Get("/{id}/qr", m.RequireAuth(h.GetQRCode))
+			r.Get("/{id}/qr.{format}", m.RequireAuth(h.GetQRImage))
+			r.Get("/{id}/ticket.pdf", m.RequireAuth(h.GetTicketPDF))
 		})
 
 		r.Route("/polls", func(r chi.Router) {
 			r.Post("/{id}/vote", m.RequireAuth(h.VoteOnPoll))
-			r.Get("/{id}/results", h.GetPollResults)
+			r.Get("/{id}/results", m.OptionalAuth(h.GetPollResults))
 		})
 
 		r.Get("/me/ics", m.RequireAuth(h.GetUserICS))
+		r.Get("/me/orgs", m.RequireAuth(h.ListMyOrganizations))
+		r.Get("/me/saved-fields", m.RequireAuth(h.GetSavedData))
+		r.Delete("/me/saved-fields", m.RequireAuth(h.ClearSavedData))
 	})
 
 	return &Router{Mux: r}
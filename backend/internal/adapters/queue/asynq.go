@@ -3,15 +3,20 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
 )
 
 type AsynqScheduler struct {
-	client *asynq.Client
+	client    *asynq.Client
+	inspector *asynq.Inspector
 }
 
 func NewAsynqScheduler(redisURL string) (*AsynqScheduler, error) {
@@ -20,23 +25,40 @@ func NewAsynqScheduler(redisURL string) (*AsynqScheduler, error) {
 		return nil, err
 	}
 
-	client := asynq.NewClient(asynq.RedisClientOpt{
+	redisOpt := asynq.RedisClientOpt{
 		Addr:     opts.Addr,
 		Password: opts.Password,
 		DB:       opts.DB,
-	})
+	}
 
-	return &AsynqScheduler{client: client}, nil
+	return &AsynqScheduler{
+		client:    asynq.NewClient(redisOpt),
+		inspector: asynq.NewInspector(redisOpt),
+	}, nil
 }
 
-func (a *AsynqScheduler) ScheduleReminder(ctx context.Context, at time.Time, payload interface{}) (string, error) {
-	data, err := json.Marshal(payload)
+// ScheduleReminder enqueues the reminder under its own ID, so scheduling the
+// same reminder twice cannot produce two tasks.
+func (a *AsynqScheduler) ScheduleReminder(ctx context.Context, reminder *events.Reminder) (string, error) {
+	data, err := json.Marshal(ReminderPayload{
+		ReminderID: reminder.ID,
+		EventID:    reminder.EventID,
+		Type:       "reminder",
+		Before:     reminder.Offset,
+	})
 	if err != nil {
 		return "", err
 	}
 
 	task := asynq.NewTask("reminder", data)
-	info, err := a.client.Enqueue(task, asynq.ProcessAt(at))
+	info, err := a.client.EnqueueContext(ctx, task,
+		asynq.TaskID(reminder.ID.String()),
+		asynq.ProcessAt(reminder.At),
+		asynq.MaxRetry(5),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return reminder.ID.String(), nil
+	}
 	if err != nil {
 		return "", err
 	}
@@ -44,17 +66,127 @@ func (a *AsynqScheduler) ScheduleReminder(ctx context.Context, at time.Time, pay
 	return info.ID, nil
 }
 
+// CancelReminder removes a scheduled reminder task. Tasks that already ran
+// are gone from the queue and are ignored.
+func (a *AsynqScheduler) CancelReminder(ctx context.Context, taskID string) error {
+	err := a.inspector.DeleteTask("default", taskID)
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ScheduleCampaign queues delivery of a campaign. The campaign ID doubles as
+// the task ID so a campaign is never queued twice.
 func (a *AsynqScheduler) ScheduleCampaign(ctx context.Context, campaignID string, at time.Time) error {
-	data, _ := json.Marshal(map[string]string{"campaign_id": campaignID})
+	data, err := json.Marshal(CampaignPayload{CampaignID: campaignID})
+	if err != nil {
+		return err
+	}
+
 	task := asynq.NewTask("campaign", data)
-	_, err := a.client.Enqueue(task, asynq.ProcessAt(at))
+	_, err = a.client.EnqueueContext(ctx, task,
+		asynq.TaskID("campaign:"+campaignID),
+		asynq.ProcessAt(at),
+		asynq.MaxRetry(5),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
+
+func (a *AsynqScheduler) ScheduleWaitlistPromotion(ctx context.Context, eventID shared.ID) error {
+	data, err := json.Marshal(WaitlistPromotionPayload{EventID: eventID})
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask("waitlist_promotion", data)
+	_, err = a.client.EnqueueContext(ctx, task, asynq.Queue("critical"), asynq.MaxRetry(10))
+	return err
+}
+
+func (a *AsynqScheduler) ScheduleDecisionNotification(ctx context.Context, reg *registrations.Registration, eventTitle string) error {
+	data, err := json.Marshal(DecisionNotificationPayload{
+		EventID:    reg.EventID,
+		EventTitle: eventTitle,
+		UserID:     reg.UserID,
+		Status:     reg.Status,
+		Reason:     reg.DecisionReason,
+		HoldUntil:  reg.HoldUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask("registration_decision", data)
+	_, err = a.client.EnqueueContext(ctx, task, asynq.MaxRetry(5))
+	return err
+}
+
+// ScheduleFormHoldExpiry queues the release of a pending_form seat for when
+// its hold runs out. Stale tasks are harmless: expiry rechecks the hold.
+func (a *AsynqScheduler) ScheduleFormHoldExpiry(ctx context.Context, reg *registrations.Registration) error {
+	if reg.HoldUntil == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(FormHoldExpiryPayload{EventID: reg.EventID, UserID: reg.UserID})
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask("form_hold_expiry", data)
+	_, err = a.client.EnqueueContext(ctx, task,
+		asynq.Queue("critical"),
+		asynq.ProcessAt(*reg.HoldUntil),
+		asynq.MaxRetry(10),
+	)
 	return err
 }
 
 func (a *AsynqScheduler) Close() error {
+	a.inspector.Close()
 	return a.client.Close()
 }
 
+// AsynqPeriodic enqueues the worker's housekeeping tasks on a schedule.
+type AsynqPeriodic struct {
+	scheduler *asynq.Scheduler
+}
+
+func NewAsynqPeriodic(redisURL string) (*AsynqPeriodic, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler := asynq.NewScheduler(asynq.RedisClientOpt{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	}, nil)
+
+	// Every worker runs a scheduler; Unique keeps their ticks from piling up.
+	for _, task := range []string{"series_extension", "file_cleanup"} {
+		_, err = scheduler.Register("@hourly", asynq.NewTask(task, nil), asynq.Unique(30*time.Minute))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &AsynqPeriodic{scheduler: scheduler}, nil
+}
+
+func (p *AsynqPeriodic) Start() error {
+	return p.scheduler.Start()
+}
+
+func (p *AsynqPeriodic) Stop() {
+	p.scheduler.Shutdown()
+}
+
 type AsynqServer struct {
 	server *asynq.Server
 }
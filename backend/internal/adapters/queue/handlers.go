@@ -3,11 +3,16 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/adapters/botmax"
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/notifications"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/hibiken/asynq"
 	maxbotapi "github.com/max-messenger/max-bot-api-client-go"
@@ -21,6 +26,7 @@ type Event struct {
 	Timezone    string
 	Location    string
 	OnlineURL   string
+	Cancelled   bool
 }
 
 type Registration struct {
@@ -36,49 +42,141 @@ type RegistrationGetter interface {
 	GetUserRegistrations(ctx context.Context, eventID shared.ID) ([]Registration, error)
 }
 
+type WaitlistPromoter interface {
+	PromoteWaitlist(ctx context.Context, eventID shared.ID) ([]*registrations.Registration, error)
+}
+
+type ChatResolver interface {
+	GetMaxChatID(ctx context.Context, userID shared.ID) (int64, error)
+}
+
+type ReminderStore interface {
+	GetByID(ctx context.Context, id shared.ID) (*events.Reminder, error)
+	UpdateStatus(ctx context.Context, id shared.ID, status events.ReminderStatus) error
+}
+
+type DeliveryStore interface {
+	RecordReminder(ctx context.Context, d *notifications.Delivery) error
+	ListReminderRecipients(ctx context.Context, reminderID shared.ID) ([]shared.ID, error)
+}
+
+type CampaignDeliverer interface {
+	Deliver(ctx context.Context, campaignID shared.ID, last bool) error
+}
+
+type FormHoldExpirer interface {
+	ExpireFormHold(ctx context.Context, eventID, userID shared.ID) error
+}
+
+type SeriesExtender interface {
+	ExtendSeries(ctx context.Context) error
+}
+
+// FileCleaner deletes uploads nobody attached to a form response.
+type FileCleaner interface {
+	CleanupFiles(ctx context.Context) error
+}
+
 type TaskHandlers struct {
-	botClient   *maxbotapi.Api
-	eventGetter EventGetter
-	regGetter   RegistrationGetter
+	botClient    *maxbotapi.Api
+	eventGetter  EventGetter
+	regGetter    RegistrationGetter
+	promoter     WaitlistPromoter
+	chatResolver ChatResolver
+	reminders    ReminderStore
+	deliveries   DeliveryStore
+	campaigns    CampaignDeliverer
+	formHolds    FormHoldExpirer
+	series       SeriesExtender
+	files        FileCleaner
 }
 
-func NewTaskHandlers(botClient *maxbotapi.Api, eventGetter EventGetter, regGetter RegistrationGetter) *TaskHandlers {
+func NewTaskHandlers(
+	botClient *maxbotapi.Api,
+	eventGetter EventGetter,
+	regGetter RegistrationGetter,
+	promoter WaitlistPromoter,
+	chatResolver ChatResolver,
+	reminders ReminderStore,
+	deliveries DeliveryStore,
+	campaigns CampaignDeliverer,
+	formHolds FormHoldExpirer,
+	series SeriesExtender,
+	files FileCleaner,
+) *TaskHandlers {
 	return &TaskHandlers{
-		botClient:   botClient,
-		eventGetter: eventGetter,
-		regGetter:   regGetter,
+		botClient:    botClient,
+		eventGetter:  eventGetter,
+		regGetter:    regGetter,
+		promoter:     promoter,
+		chatResolver: chatResolver,
+		reminders:    reminders,
+		deliveries:   deliveries,
+		campaigns:    campaigns,
+		formHolds:    formHolds,
+		series:       series,
+		files:        files,
 	}
 }
 
 type ReminderPayload struct {
-	EventID shared.ID     `json:"event_id"`
-	Type    string        `json:"type"`
-	Before  time.Duration `json:"before"`
+	ReminderID shared.ID     `json:"reminder_id"`
+	EventID    shared.ID     `json:"event_id"`
+	Type       string        `json:"type"`
+	Before     time.Duration `json:"before"`
 }
 
+// HandleReminder sends a reminder to every going or maybe attendee. Reminders
+// that were cancelled or rescheduled since they were enqueued are dropped.
+// Users already reached are skipped, so a retried task only sends to those
+// who failed; transient failures fail the task to trigger a retry.
 func (h *TaskHandlers) HandleReminder(ctx context.Context, task *asynq.Task) error {
 	var payload ReminderPayload
 	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
-		return fmt.Errorf("unmarshal payload: %w", err)
+		return fmt.Errorf("unmarshal payload: %w: %w", err, asynq.SkipRetry)
 	}
 
 	log.Printf("Processing reminder: event=%s, before=%s", payload.EventID, payload.Before)
 
-	if h.eventGetter == nil || h.regGetter == nil {
-		log.Println("EventGetter or RegistrationGetter not set, skipping")
+	reminder, err := h.reminders.GetByID(ctx, payload.ReminderID)
+	if errors.Is(err, events.ErrReminderNotFound) {
+		log.Printf("Reminder %s no longer exists, skipping", payload.ReminderID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get reminder: %w", err)
+	}
+	if reminder.Status != events.ReminderPending {
+		log.Printf("Reminder %s is %s, skipping", reminder.ID, reminder.Status)
 		return nil
 	}
 
-	event, err := h.eventGetter.GetEvent(ctx, payload.EventID)
+	event, err := h.eventGetter.GetEvent(ctx, reminder.EventID)
+	if errors.Is(err, events.ErrEventNotFound) {
+		return h.reminders.UpdateStatus(ctx, reminder.ID, events.ReminderCancelled)
+	}
 	if err != nil {
 		return fmt.Errorf("get event: %w", err)
 	}
+	if event.Cancelled {
+		log.Printf("Event %s is cancelled, dropping reminder %s", event.ID, reminder.ID)
+		return h.reminders.UpdateStatus(ctx, reminder.ID, events.ReminderCancelled)
+	}
 
-	regs, err := h.regGetter.GetUserRegistrations(ctx, payload.EventID)
+	regs, err := h.regGetter.GetUserRegistrations(ctx, event.ID)
 	if err != nil {
 		return fmt.Errorf("get registrations: %w", err)
 	}
 
+	reached, err := h.deliveries.ListReminderRecipients(ctx, reminder.ID)
+	if err != nil {
+		return fmt.Errorf("list deliveries: %w", err)
+	}
+	done := make(map[shared.ID]bool, len(reached))
+	for _, userID := range reached {
+		done[userID] = true
+	}
+
 	eventForReminder := &botmax.EventForReminder{
 		ID:          event.ID,
 		Title:       event.Title,
@@ -89,11 +187,11 @@ func (h *TaskHandlers) HandleReminder(ctx context.Context, task *asynq.Task) err
 		OnlineURL:   event.OnlineURL,
 	}
 
-	components := botmax.BuildReminderMessageComponents(h.botClient, eventForReminder, payload.Before)
+	components := botmax.BuildReminderMessageComponents(h.botClient, eventForReminder, reminder.Offset)
 
-	var successCount, errorCount int
+	var successCount, errorCount, retryCount int
 	for _, reg := range regs {
-		if reg.ChatID == 0 {
+		if reg.ChatID == 0 || done[reg.UserID] {
 			continue
 		}
 
@@ -103,29 +201,157 @@ func (h *TaskHandlers) HandleReminder(ctx context.Context, task *asynq.Task) err
 			SetFormat("markdown").
 			AddKeyboard(components.Keyboard)
 
-		_, err := h.botClient.Messages.Send(ctx, msg)
+		delivery := notifications.NewReminderDelivery(reminder.ID, reg.UserID)
+		messageID, attempts, err := botmax.SendWithRetry(ctx, h.botClient, msg)
+		delivery.Attempts = attempts
 		if err != nil {
 			log.Printf("Failed to send reminder to user %s: %v", reg.UserID, err)
+			delivery.MarkFailed(err)
 			errorCount++
+			if botmax.IsTransient(err) {
+				retryCount++
+			}
 		} else {
+			delivery.MarkSent(messageID)
 			successCount++
 		}
+
+		if err := h.deliveries.RecordReminder(ctx, delivery); err != nil {
+			return fmt.Errorf("record delivery: %w", err)
+		}
 	}
 
 	log.Printf("Reminder sent: success=%d, errors=%d", successCount, errorCount)
-	return nil
+
+	if retryCount > 0 {
+		return fmt.Errorf("reminder %s: %d transient send failures", reminder.ID, retryCount)
+	}
+
+	return h.reminders.UpdateStatus(ctx, reminder.ID, events.ReminderSent)
 }
 
 type CampaignPayload struct {
 	CampaignID string `json:"campaign_id"`
 }
 
+// HandleCampaign delivers a campaign. Delivery resumes where a previous
+// attempt stopped, so a failed task can simply be retried. The task can run
+// before the transaction that created the campaign commits, so a missing
+// campaign is retried too until the last attempt.
 func (h *TaskHandlers) HandleCampaign(ctx context.Context, task *asynq.Task) error {
 	var payload CampaignPayload
 	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
-		return fmt.Errorf("unmarshal payload: %w", err)
+		return fmt.Errorf("unmarshal payload: %w: %w", err, asynq.SkipRetry)
 	}
 
 	log.Printf("Processing campaign: id=%s", payload.CampaignID)
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	last := retried >= maxRetry
+
+	err := h.campaigns.Deliver(ctx, shared.ID(payload.CampaignID), last)
+	if errors.Is(err, campaigns.ErrCampaignNotFound) {
+		if !last {
+			return fmt.Errorf("campaign %s not found yet", payload.CampaignID)
+		}
+		log.Printf("Campaign %s no longer exists, skipping", payload.CampaignID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deliver campaign %s: %w", payload.CampaignID, err)
+	}
+	return nil
+}
+
+type WaitlistPromotionPayload struct {
+	EventID shared.ID `json:"event_id"`
+}
+
+func (h *TaskHandlers) HandleWaitlistPromotion(ctx context.Context, task *asynq.Task) error {
+	var payload WaitlistPromotionPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	promoted, err := h.promoter.PromoteWaitlist(ctx, payload.EventID)
+	if err != nil {
+		return fmt.Errorf("promote waitlist: %w", err)
+	}
+
+	log.Printf("Waitlist promoted: event=%s, count=%d", payload.EventID, len(promoted))
+	return nil
+}
+
+type FormHoldExpiryPayload struct {
+	EventID shared.ID `json:"event_id"`
+	UserID  shared.ID `json:"user_id"`
+}
+
+func (h *TaskHandlers) HandleFormHoldExpiry(ctx context.Context, task *asynq.Task) error {
+	var payload FormHoldExpiryPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	if err := h.formHolds.ExpireFormHold(ctx, payload.EventID, payload.UserID); err != nil {
+		return fmt.Errorf("expire form hold: %w", err)
+	}
+	return nil
+}
+
+// HandleSeriesExtension materializes recurring occurrences that moved into
+// the horizon. Series are locked one at a time, so a tick that overlaps an
+// edit just waits for it.
+func (h *TaskHandlers) HandleSeriesExtension(ctx context.Context, _ *asynq.Task) error {
+	return h.series.ExtendSeries(ctx)
+}
+
+func (h *TaskHandlers) HandleFileCleanup(ctx context.Context, _ *asynq.Task) error {
+	return h.files.CleanupFiles(ctx)
+}
+
+type DecisionNotificationPayload struct {
+	EventID    shared.ID            `json:"event_id"`
+	EventTitle string               `json:"event_title"`
+	UserID     shared.ID            `json:"user_id"`
+	Status     registrations.Status `json:"status"`
+	Reason     string               `json:"reason"`
+	HoldUntil  *time.Time           `json:"hold_until,omitempty"`
+}
+
+func (h *TaskHandlers) HandleDecisionNotification(ctx context.Context, task *asynq.Task) error {
+	var payload DecisionNotificationPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	chatID, err := h.chatResolver.GetMaxChatID(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("resolve chat: %w", err)
+	}
+	if chatID == 0 {
+		log.Printf("Decision not delivered, user %s has no MAX chat", payload.UserID)
+		return nil
+	}
+
+	components := botmax.BuildDecisionMessageComponents(h.botClient, &botmax.Decision{
+		EventID:    payload.EventID,
+		EventTitle: payload.EventTitle,
+		Status:     payload.Status,
+		Reason:     payload.Reason,
+		HoldUntil:  payload.HoldUntil,
+	})
+
+	msg := maxbotapi.NewMessage().
+		SetChat(chatID).
+		SetText(components.Text).
+		SetFormat("markdown").
+		AddKeyboard(components.Keyboard)
+
+	if _, err := h.botClient.Messages.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send decision: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type eventLoader interface {
+	GetByID(ctx context.Context, id shared.ID) (*events.Event, error)
+}
+
+// EventSource adapts the event repository to EventGetter.
+type EventSource struct {
+	repo eventLoader
+}
+
+func NewEventSource(repo eventLoader) *EventSource {
+	return &EventSource{repo: repo}
+}
+
+func (s *EventSource) GetEvent(ctx context.Context, eventID shared.ID) (Event, error) {
+	event, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          event.ID,
+		Title:       event.Title,
+		Description: event.Description,
+		StartsAt:    event.StartsAt,
+		Timezone:    event.Timezone,
+		Location:    event.Location,
+		OnlineURL:   event.OnlineURL,
+		Cancelled:   event.Status == events.StatusCancelled,
+	}, nil
+}
+
+type recipientLoader interface {
+	GetByEventWithChatIDs(ctx context.Context, eventID shared.ID) ([]registrations.Recipient, error)
+}
+
+// RegistrationSource adapts the registration repository to
+// RegistrationGetter.
+type RegistrationSource struct {
+	repo recipientLoader
+}
+
+func NewRegistrationSource(repo recipientLoader) *RegistrationSource {
+	return &RegistrationSource{repo: repo}
+}
+
+func (s *RegistrationSource) GetUserRegistrations(ctx context.Context, eventID shared.ID) ([]Registration, error) {
+	recipients, err := s.repo.GetByEventWithChatIDs(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Registration, 0, len(recipients))
+	for _, rec := range recipients {
+		result = append(result, Registration{UserID: rec.UserID, ChatID: rec.ChatID})
+	}
+
+	return result, nil
+}
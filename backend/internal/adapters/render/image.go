@@ -0,0 +1,98 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+const (
+	ContentTypePNG = "image/png"
+	ContentTypeSVG = "image/svg+xml"
+	ContentTypePDF = "application/pdf"
+)
+
+// Image sizes in pixels, for PNG, or in user units, for SVG.
+const (
+	DefaultImageSize = 512
+	MinImageSize     = 128
+	MaxImageSize     = 2048
+)
+
+// quietZone is the light margin around the symbol, in modules.
+const quietZone = 4
+
+// ClampSize returns size within the allowed range, or the default when it
+// is not set.
+func ClampSize(size int) int {
+	if size <= 0 {
+		return DefaultImageSize
+	}
+	return min(max(size, MinImageSize), MaxImageSize)
+}
+
+// WritePNG draws the code as a size×size PNG. Modules are whole pixels so
+// the code stays sharp; any leftover goes to the margin.
+func WritePNG(w io.Writer, q *QR, size int) error {
+	scale := max(size/(q.Size+2*quietZone), 1)
+	size = max(size, scale*(q.Size+2*quietZone))
+	offset := (size - scale*q.Size) / 2
+
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.Dark(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(offset+y*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[offset+x*scale+dx] = 1
+				}
+			}
+		}
+	}
+
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	return enc.Encode(w, img)
+}
+
+// WriteSVG draws the code as a single path, one subpath per horizontal run
+// of dark modules.
+func WriteSVG(w io.Writer, q *QR, size int) error {
+	bw := bufio.NewWriter(w)
+	view := q.Size + 2*quietZone
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, view, view)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, view, view)
+	for _, r := range q.runs() {
+		fmt.Fprintf(bw, "M%d %dh%dv1h-%dz", r.x+quietZone, r.y+quietZone, r.n, r.n)
+	}
+	bw.WriteString(`"/></svg>`)
+	return bw.Flush()
+}
+
+// run is n dark modules in row y starting at column x.
+type run struct{ x, y, n int }
+
+func (q *QR) runs() []run {
+	var result []run
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; {
+			if !q.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < q.Size && q.Dark(x, y) {
+				x++
+			}
+			result = append(result, run{x: start, y: y, n: x - start})
+		}
+	}
+	return result
+}
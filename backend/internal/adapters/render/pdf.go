@@ -0,0 +1,161 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Ticket is what goes on a printed ticket.
+type Ticket struct {
+	ID       string
+	Title    string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Timezone string
+	Venue    string
+	Attendee string
+	Token    string
+}
+
+// A6 in points.
+const (
+	pageWidth  = 297.64
+	pageHeight = 419.53
+	margin     = 20.0
+	qrWidth    = 180.0
+)
+
+// Tickets are set in the Go fonts, which cover Cyrillic and are embedded
+// as subsets so the PDF reads the same everywhere.
+const (
+	fontFamily = "Go"
+	gray       = 115
+)
+
+// WritePDF lays the ticket out on a single A6 page: title, time in the
+// event's timezone, venue and attendee on top, the QR code below.
+func WritePDF(w io.Writer, t *Ticket) error {
+	q, err := EncodeQR([]byte(t.Token))
+	if err != nil {
+		return err
+	}
+
+	pdf := newTicketPDF(t.Title)
+
+	textWidth := pageWidth - 2*margin
+	y := margin + 15
+
+	pdf.SetFont(fontFamily, "B", 15)
+	for _, line := range wrap(pdf, t.Title, textWidth, 3) {
+		pdf.Text(margin, y, line)
+		y += 18
+	}
+	y += 4
+
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.Text(margin, y, FormatTicketTime(t.StartsAt, t.EndsAt, t.Timezone))
+	y += 14
+	if t.Venue != "" {
+		for _, line := range wrap(pdf, t.Venue, textWidth, 3) {
+			pdf.Text(margin, y, line)
+			y += 13
+		}
+	}
+	y += 8
+
+	pdf.SetFont(fontFamily, "", 7)
+	pdf.SetTextColor(gray, gray, gray)
+	pdf.Text(margin, y, "УЧАСТНИК")
+	pdf.SetTextColor(0, 0, 0)
+	y += 15
+	pdf.SetFont(fontFamily, "B", 12)
+	for _, line := range wrap(pdf, t.Attendee, textWidth, 2) {
+		pdf.Text(margin, y, line)
+		y += 15
+	}
+
+	// The QR code keeps its place at the bottom; a long header is cut
+	// short above rather than pushing it off the page.
+	module := qrWidth / float64(q.Size+2*quietZone)
+	left := (pageWidth-qrWidth)/2 + module*quietZone
+	top := pageHeight - (margin + 14 + qrWidth) + module*quietZone
+	pdf.SetFillColor(0, 0, 0)
+	for _, r := range q.runs() {
+		pdf.Rect(left+float64(r.x)*module, top+float64(r.y)*module, float64(r.n)*module, module, "F")
+	}
+
+	pdf.SetFont(fontFamily, "", 7)
+	pdf.SetTextColor(gray, gray, gray)
+	pdf.Text(margin, pageHeight-margin, "Билет "+t.ID)
+
+	return pdf.Output(w)
+}
+
+func newTicketPDF(title string) *gofpdf.Fpdf {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "pt",
+		Size:    gofpdf.SizeType{Wd: pageWidth, Ht: pageHeight},
+	})
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetTitle(title, true)
+	pdf.AddPage()
+	return pdf
+}
+
+var monthsGenitive = [...]string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+// FormatTicketTime renders the event's time span in its timezone, e.g.
+// "17 октября 2026, 19:00–22:00 (Europe/Moscow)".
+func FormatTicketTime(startsAt, endsAt time.Time, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		loc, timezone = time.UTC, "UTC"
+	}
+	start := startsAt.In(loc)
+	day := func(t time.Time) string {
+		return fmt.Sprintf("%d %s %d", t.Day(), monthsGenitive[t.Month()-1], t.Year())
+	}
+
+	text := day(start) + ", " + start.Format("15:04")
+	if !endsAt.IsZero() && endsAt.After(startsAt) {
+		end := endsAt.In(loc)
+		if end.YearDay() == start.YearDay() && end.Year() == start.Year() {
+			text += "–" + end.Format("15:04")
+		} else {
+			text += " – " + day(end) + ", " + end.Format("15:04")
+		}
+	}
+	return text + " (" + timezone + ")"
+}
+
+// wrap breaks text into at most maxLines lines that fit width in the
+// current font, ending the last one with "…" if it had to be cut.
+func wrap(pdf *gofpdf.Fpdf, text string, width float64, maxLines int) []string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil
+	}
+
+	lines := pdf.SplitText(text, width)
+	if len(lines) <= maxLines {
+		return lines
+	}
+
+	last := []rune(strings.Join(lines[maxLines-1:], " "))
+	for len(last) > 0 && pdf.GetStringWidth(string(last)+"…") > width {
+		last = last[:len(last)-1]
+	}
+	return append(lines[:maxLines-1], strings.TrimRight(string(last), " ")+"…")
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestWritePDFText(t *testing.T) {
+	ticket := &Ticket{
+		ID:       "7d5f0f7e-3c1a-4e59-9a36-2f3f5c8f4b11",
+		Title:    "Летняя школа программирования",
+		StartsAt: time.Date(2026, 10, 17, 16, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2026, 10, 17, 19, 0, 0, 0, time.UTC),
+		Timezone: "Europe/Moscow",
+		Venue:    "Санкт-Петербург, Кронверкский пр., 49",
+		Attendee: "Ёлкина Анна",
+		Token:    strings.Repeat("A", 188),
+	}
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, ticket); err != nil {
+		t.Fatalf("WritePDF: %v", err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("/FontFile2")); n != 2 {
+		t.Errorf("%d fonts embedded, want 2", n)
+	}
+
+	doc, err := pdf.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	page := doc.Page(1)
+	for _, name := range page.Fonts() {
+		if page.Font(name).V.Key("ToUnicode").IsNull() {
+			t.Errorf("font %s has no ToUnicode map", name)
+		}
+	}
+	content, err := io.ReadAll(page.V.Key("Contents").Reader())
+	if err != nil {
+		t.Fatalf("read page content: %v", err)
+	}
+	text := shownText(content)
+
+	want := []string{
+		ticket.Title, ticket.Venue, ticket.Attendee, "УЧАСТНИК", "Билет " + ticket.ID,
+		"17 октября 2026, 19:00–22:00 (Europe/Moscow)",
+	}
+	for _, s := range want {
+		if !strings.Contains(text, s) {
+			t.Errorf("page text %q is missing %q", text, s)
+		}
+	}
+
+	for _, ttf := range [][]byte{goregular.TTF, gobold.TTF} {
+		font, err := sfnt.Parse(ttf)
+		if err != nil {
+			t.Fatalf("parse font: %v", err)
+		}
+		var sb sfnt.Buffer
+		for _, r := range strings.Join(want, "") {
+			if i, err := font.GlyphIndex(&sb, r); err != nil || i == 0 {
+				t.Errorf("font has no glyph for %q", r)
+			}
+		}
+	}
+}
+
+// shownText joins the strings shown with Tj, one space apart. gofpdf writes them as literal
+// strings of UTF-16BE code units, escaping only parentheses and
+// backslashes.
+func shownText(content []byte) string {
+	var text []rune
+	for {
+		start := bytes.Index(content, []byte(" ("))
+		if start < 0 {
+			break
+		}
+		content = content[start+2:]
+
+		var units []uint16
+		var b []byte
+		for len(content) > 0 && content[0] != ')' {
+			if content[0] == '\\' {
+				content = content[1:]
+			}
+			b = append(b, content[0])
+			content = content[1:]
+		}
+		for i := 0; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		text = append(text, utf16.Decode(units)...)
+		text = append(text, ' ')
+	}
+	return string(text)
+}
+
+func TestWrap(t *testing.T) {
+	doc := newTicketPDF("")
+	doc.SetFont(fontFamily, "B", 15)
+
+	lines := wrap(doc, strings.Repeat("Конференция ", 20), 257, 3)
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], "…") {
+		t.Fatalf("wrap = %q, want 3 lines ending in an ellipsis", lines)
+	}
+	for _, line := range lines {
+		if w := doc.GetStringWidth(line); w > 257 {
+			t.Errorf("line %q is %.1fpt wide, want at most 257", line, w)
+		}
+	}
+
+	if lines := wrap(doc, "  Короткий   заголовок ", 257, 3); len(lines) != 1 || lines[0] != "Короткий заголовок" {
+		t.Errorf("wrap short title = %q", lines)
+	}
+}
@@ -0,0 +1,42 @@
+// Package render draws tickets: QR codes as PNG or SVG and a printable
+// PDF ticket.
+package render
+
+import (
+	"errors"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+var ErrTooLong = errors.New("data too long for a qr code")
+
+// maxQRBytes is what a version 40 symbol holds in byte mode at level M.
+const maxQRBytes = 2331
+
+// QR is an encoded QR code symbol of Size×Size modules.
+type QR struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (q *QR) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// EncodeQR encodes data with error correction level M, in the smallest
+// version it fits. A ticket token needs version 10.
+func EncodeQR(data []byte) (*QR, error) {
+	if len(data) > maxQRBytes {
+		return nil, ErrTooLong
+	}
+	code, err := qrcode.New(string(data), qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	// The quiet zone is left to whoever draws the symbol.
+	code.DisableBorder = true
+
+	modules := code.Bitmap()
+	return &QR{Size: len(modules), modules: modules}, nil
+}
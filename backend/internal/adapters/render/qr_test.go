@@ -0,0 +1,39 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		size int
+	}{
+		{"short", "kvorum", 21},
+		// A ticket token is 141 bytes, 188 characters in base64url.
+		{"ticket token", strings.Repeat("Ab-_", 47), 57},
+	}
+	for _, tt := range tests {
+		q, err := EncodeQR([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: EncodeQR: %v", tt.name, err)
+		}
+		if q.Size != tt.size {
+			t.Errorf("%s: size = %d, want %d", tt.name, q.Size, tt.size)
+		}
+		// Finder patterns sit in three corners, without a border around
+		// the symbol.
+		for _, corner := range [][2]int{{0, 0}, {q.Size - 7, 0}, {0, q.Size - 7}} {
+			if !q.Dark(corner[0], corner[1]) || q.Dark(corner[0]+1, corner[1]+1) || !q.Dark(corner[0]+3, corner[1]+3) {
+				t.Errorf("%s: no finder pattern at %v", tt.name, corner)
+			}
+		}
+	}
+
+	if _, err := EncodeQR(make([]byte, maxQRBytes+1)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("EncodeQR with too much data: err = %v, want ErrTooLong", err)
+	}
+}
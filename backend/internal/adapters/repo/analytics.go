@@ -24,6 +24,9 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
                 COUNT(*) FILTER (WHERE status = 'not_going') as not_going_count,
                 COUNT(*) FILTER (WHERE status = 'maybe') as maybe_count,
                 COUNT(*) FILTER (WHERE status = 'waitlist') as waitlist_count,
+                COUNT(*) FILTER (WHERE status = 'pending') as pending_count,
+                COUNT(*) FILTER (WHERE decided_at IS NOT NULL AND status <> 'rejected') as approved_count,
+                COUNT(*) FILTER (WHERE status = 'rejected') as rejected_count,
                 COUNT(*) as total_count
             FROM registrations
             WHERE event_id = $1
@@ -31,9 +34,11 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
               AND created_at <= $3
         ),
         checkins AS (
-            SELECT COUNT(*) as checkin_count
+            SELECT COUNT(DISTINCT user_id) as checkin_count
             FROM checkins
             WHERE event_id = $1
+              AND zone_id IS NULL
+              AND direction = 'in'
               AND at >= $2
               AND at <= $3
         ),
@@ -53,6 +58,9 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
             s.not_going_count,
             s.maybe_count,
             s.waitlist_count,
+            s.pending_count,
+            s.approved_count,
+            s.rejected_count,
             COALESCE(c.checkin_count, 0) as checkin_count
         FROM stats s
         CROSS JOIN checkins c
@@ -64,12 +72,15 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
 	result.PeriodTo = to
 	result.BySource = make(map[string]int64)
 
-	err := r.db.pool.QueryRow(ctx, query, eventID, from, to).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, from, to).Scan(
 		&result.TotalRegistrations,
 		&result.Going,
 		&result.NotGoing,
 		&result.Maybe,
 		&result.Waitlist,
+		&result.Pending,
+		&result.Approved,
+		&result.Rejected,
 		&result.CheckedIn,
 	)
 	if err != nil {
@@ -87,7 +98,7 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
         GROUP BY source
     `
 
-	rows, err := r.db.pool.Query(ctx, sourcesQuery, eventID, from, to)
+	rows, err := r.db.conn(ctx).Query(ctx, sourcesQuery, eventID, from, to)
 	if err != nil {
 		return &result, nil
 	}
@@ -102,5 +113,92 @@ func (r *AnalyticsRepo) GetEventAnalytics(ctx context.Context, eventID shared.ID
 		result.BySource[source] = count
 	}
 
+	ticketTypesQuery := `
+        SELECT 
+            t.id,
+            t.name,
+            t.capacity,
+            COALESCE(SUM(r.quantity) FILTER (WHERE r.status = 'going'), 0) as going_seats,
+            COALESCE(SUM(r.quantity) FILTER (WHERE r.status = 'waitlist'), 0) as waitlist_seats
+        FROM ticket_types t
+        LEFT JOIN registrations r
+            ON r.ticket_type_id = t.id
+           AND r.created_at >= $2
+           AND r.created_at <= $3
+        WHERE t.event_id = $1
+        GROUP BY t.id, t.name, t.capacity, t.sort_order, t.created_at
+        ORDER BY t.sort_order, t.created_at
+    `
+
+	typeRows, err := r.db.conn(ctx).Query(ctx, ticketTypesQuery, eventID, from, to)
+	if err != nil {
+		return &result, nil
+	}
+	defer typeRows.Close()
+
+	for typeRows.Next() {
+		var stats analytics.TicketTypeStats
+		if err := typeRows.Scan(&stats.TicketTypeID, &stats.Name, &stats.Capacity, &stats.Going, &stats.Waitlist); err != nil {
+			continue
+		}
+		result.ByTicketType = append(result.ByTicketType, stats)
+	}
+
+	zonesQuery := `
+        WITH passes AS (
+            SELECT
+                zone_id,
+                user_id,
+                direction,
+                at,
+                LEAD(at) OVER (PARTITION BY zone_id, user_id ORDER BY at, id) as next_at,
+                ROW_NUMBER() OVER (PARTITION BY zone_id, user_id ORDER BY at DESC, id DESC) as latest
+            FROM checkins
+            WHERE event_id = $1
+              AND zone_id IS NOT NULL
+        ),
+        stays AS (
+            SELECT
+                p.zone_id,
+                p.user_id,
+                COALESCE(p.next_at, GREATEST(p.at, LEAST(NOW(), e.ends_at))) - p.at as dwell
+            FROM passes p
+            JOIN events e ON e.id = $1
+            WHERE p.direction = 'in'
+              AND p.at >= $2
+              AND p.at <= $3
+        )
+        SELECT
+            z.id,
+            z.name,
+            COUNT(DISTINCT s.user_id) as attended,
+            COUNT(s.user_id) as entries,
+            (SELECT COUNT(*) FROM passes p WHERE p.zone_id = z.id AND p.latest = 1 AND p.direction = 'in') as inside,
+            COALESCE(EXTRACT(EPOCH FROM SUM(s.dwell)), 0)::bigint as dwell_seconds
+        FROM checkin_zones z
+        LEFT JOIN stays s ON s.zone_id = z.id
+        WHERE z.event_id = $1
+        GROUP BY z.id, z.name, z.sort_order, z.created_at
+        ORDER BY z.sort_order, z.created_at
+    `
+
+	zoneRows, err := r.db.conn(ctx).Query(ctx, zonesQuery, eventID, from, to)
+	if err != nil {
+		return &result, nil
+	}
+	defer zoneRows.Close()
+
+	for zoneRows.Next() {
+		var stats analytics.ZoneStats
+		err := zoneRows.Scan(&stats.ZoneID, &stats.Name, &stats.Attended, &stats.Entries, &stats.Inside, &stats.TotalDwellSeconds)
+		if err != nil {
+			continue
+		}
+		if stats.Attended > 0 {
+			stats.AvgDwellSeconds = stats.TotalDwellSeconds / int64(stats.Attended)
+		}
+		result.ByZone = append(result.ByZone, stats)
+	}
+
 	return &result, nil
 }
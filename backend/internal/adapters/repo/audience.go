@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type AudienceRepo struct {
+	db *DB
+}
+
+func NewAudienceRepo(db *DB) *AudienceRepo {
+	return &AudienceRepo{db: db}
+}
+
+// segmentQueries select the user IDs of each campaign segment for event $1.
+var segmentQueries = map[campaigns.Segment]string{
+	campaigns.SegmentGoing: `
+		SELECT user_id FROM registrations WHERE event_id = $1 AND status = 'going'`,
+	campaigns.SegmentMaybe: `
+		SELECT user_id FROM registrations WHERE event_id = $1 AND status = 'maybe'`,
+	campaigns.SegmentWaitlist: `
+		SELECT user_id FROM registrations WHERE event_id = $1 AND status = 'waitlist'`,
+	campaigns.SegmentCheckedIn: `
+		SELECT DISTINCT user_id FROM checkins WHERE event_id = $1 AND zone_id IS NULL`,
+	campaigns.SegmentNotCheckedIn: `
+		SELECT r.user_id FROM registrations r
+		WHERE r.event_id = $1 AND r.status = 'going'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM checkins c
+		      WHERE c.event_id = r.event_id AND c.user_id = r.user_id AND c.zone_id IS NULL
+		  )`,
+	campaigns.SegmentFormRespondents: `
+		SELECT DISTINCT fr.user_id FROM form_responses fr
+		JOIN forms f ON f.id = fr.form_id
+		WHERE f.event_id = $1 AND fr.status = 'submitted'`,
+}
+
+// ResolveAudience returns the users in the segment together with their MAX
+// chat. Users who never linked MAX are included with a zero chat ID so the
+// failed delivery is still recorded.
+func (r *AudienceRepo) ResolveAudience(ctx context.Context, eventID shared.ID, segment campaigns.Segment) ([]registrations.Recipient, error) {
+	users, ok := segmentQueries[segment]
+	if !ok {
+		return nil, campaigns.ErrInvalidSegment
+	}
+
+	query := `
+		SELECT s.user_id, COALESCE(ui.provider_user_id, '')
+		FROM (` + users + `) s
+		LEFT JOIN user_identities ui ON ui.user_id = s.user_id AND ui.provider = 'max'
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []registrations.Recipient
+	for rows.Next() {
+		var userID shared.ID
+		var providerUserID string
+
+		if err := rows.Scan(&userID, &providerUserID); err != nil {
+			return nil, err
+		}
+
+		chatID, _ := strconv.ParseInt(providerUserID, 10, 64)
+		result = append(result, registrations.Recipient{
+			UserID: userID,
+			ChatID: chatID,
+		})
+	}
+
+	return result, rows.Err()
+}
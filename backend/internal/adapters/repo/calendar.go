@@ -24,7 +24,7 @@ func (r *CalendarEventRepo) GetByID(ctx context.Context, id shared.ID) (*calenda
 	`
 
 	var event calendar.Event
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&event.ID, &event.Title, &event.Description,
 		&event.StartsAt, &event.EndsAt, &event.Timezone,
 		&event.Location, &event.OnlineURL,
@@ -49,7 +49,38 @@ func (r *CalendarEventRepo) ListByUser(ctx context.Context, userID shared.ID) ([
 		ORDER BY e.starts_at DESC
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, userID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*calendar.Event
+	for rows.Next() {
+		var event calendar.Event
+		err := rows.Scan(
+			&event.ID, &event.Title, &event.Description,
+			&event.StartsAt, &event.EndsAt, &event.Timezone,
+			&event.Location, &event.OnlineURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &event)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *CalendarEventRepo) ListByIDs(ctx context.Context, ids []shared.ID) ([]*calendar.Event, error) {
+	query := `
+		SELECT id, title, description, starts_at, ends_at, tz, location, online_url
+		FROM events
+		WHERE id = ANY($1)
+		ORDER BY starts_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, ids)
 	if err != nil {
 		return nil, err
 	}
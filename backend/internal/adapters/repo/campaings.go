@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/campaigns"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
@@ -18,14 +19,15 @@ func NewCampaignRepo(db *DB) *CampaignRepo {
 
 func (r *CampaignRepo) Create(ctx context.Context, campaign *campaigns.Campaign) error {
 	query := `
-        INSERT INTO campaigns (id, event_id, name, segment, content, schedule_at, status, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        INSERT INTO campaigns (id, event_id, name, segment, channel, content, schedule_at, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
     `
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		campaign.ID,
 		campaign.EventID,
 		campaign.Name,
 		campaign.Segment,
+		campaign.Channel,
 		campaign.Content,
 		campaign.ScheduleAt,
 		campaign.Status,
@@ -37,17 +39,18 @@ func (r *CampaignRepo) Create(ctx context.Context, campaign *campaigns.Campaign)
 
 func (r *CampaignRepo) GetByID(ctx context.Context, id shared.ID) (*campaigns.Campaign, error) {
 	query := `
-        SELECT id, event_id, name, segment, content, schedule_at, status, created_at, updated_at
+        SELECT id, event_id, name, segment, channel, content, schedule_at, status, created_at, updated_at
         FROM campaigns
         WHERE id = $1
     `
 
 	var campaign campaigns.Campaign
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&campaign.ID,
 		&campaign.EventID,
 		&campaign.Name,
 		&campaign.Segment,
+		&campaign.Channel,
 		&campaign.Content,
 		&campaign.ScheduleAt,
 		&campaign.Status,
@@ -67,13 +70,13 @@ func (r *CampaignRepo) GetByID(ctx context.Context, id shared.ID) (*campaigns.Ca
 
 func (r *CampaignRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*campaigns.Campaign, error) {
 	query := `
-        SELECT id, event_id, name, segment, content, schedule_at, status, created_at, updated_at
+        SELECT id, event_id, name, segment, channel, content, schedule_at, status, created_at, updated_at
         FROM campaigns
         WHERE event_id = $1
         ORDER BY created_at DESC
     `
 
-	rows, err := r.db.pool.Query(ctx, query, eventID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +90,7 @@ func (r *CampaignRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*c
 			&campaign.EventID,
 			&campaign.Name,
 			&campaign.Segment,
+			&campaign.Channel,
 			&campaign.Content,
 			&campaign.ScheduleAt,
 			&campaign.Status,
@@ -102,12 +106,25 @@ func (r *CampaignRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*c
 	return result, rows.Err()
 }
 
+func (r *CampaignRepo) Claim(ctx context.Context, id shared.ID, staleBefore time.Time) (bool, error) {
+	query := `
+        UPDATE campaigns
+        SET status = 'sending', updated_at = NOW()
+        WHERE id = $1 AND (status = 'pending' OR (status = 'sending' AND updated_at < $2))
+    `
+	tag, err := r.db.conn(ctx).Exec(ctx, query, id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *CampaignRepo) Update(ctx context.Context, campaign *campaigns.Campaign) error {
 	query := `
         UPDATE campaigns
         SET status = $2, updated_at = $3
         WHERE id = $1
     `
-	_, err := r.db.pool.Exec(ctx, query, campaign.ID, campaign.Status, campaign.UpdatedAt)
+	_, err := r.db.conn(ctx).Exec(ctx, query, campaign.ID, campaign.Status, campaign.UpdatedAt)
 	return err
 }
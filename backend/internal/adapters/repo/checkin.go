@@ -2,6 +2,14 @@ package repo
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
@@ -18,109 +26,550 @@ func NewCheckinRepo(db *DB) *CheckinRepo {
 
 func (r *CheckinRepo) Create(ctx context.Context, c *checkin.Checkin) error {
 	query := `
-		INSERT INTO checkins (id, event_id, user_id, method, at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO checkins (id, event_id, user_id, zone_id, method, direction, reentry, at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.db.pool.Exec(ctx, query, c.ID, c.EventID, c.UserID, c.Method, c.At)
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		c.ID, c.EventID, c.UserID, c.ZoneID, c.Method, c.Direction, c.Reentry, c.At,
+	)
 	return err
 }
 
-func (r *CheckinRepo) GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*checkin.Checkin, error) {
+// ListByEventAndUser returns the attendee's passes through one door, the
+// main entrance when zoneID is nil, oldest first.
+func (r *CheckinRepo) ListByEventAndUser(ctx context.Context, eventID, userID shared.ID, zoneID *shared.ID) ([]*checkin.Checkin, error) {
+	query := `
+		SELECT id, event_id, user_id, zone_id, method, direction, reentry, at
+		FROM checkins
+		WHERE event_id = $1 AND user_id = $2 AND zone_id IS NOT DISTINCT FROM $3::uuid
+		ORDER BY at, id
+	`
+	return r.list(ctx, query, eventID, userID, zoneID)
+}
+
+func (r *CheckinRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Checkin, error) {
 	query := `
-		SELECT id, event_id, user_id, method, at
+		SELECT id, event_id, user_id, zone_id, method, direction, reentry, at
 		FROM checkins
-		WHERE event_id = $1 AND user_id = $2
+		WHERE event_id = $1
 		ORDER BY at DESC
-		LIMIT 1
 	`
+	return r.list(ctx, query, eventID)
+}
+
+func (r *CheckinRepo) list(ctx context.Context, query string, args ...any) ([]*checkin.Checkin, error) {
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*checkin.Checkin
+	for rows.Next() {
+		var c checkin.Checkin
+		err := rows.Scan(&c.ID, &c.EventID, &c.UserID, &c.ZoneID, &c.Method, &c.Direction, &c.Reentry, &c.At)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &c)
+	}
+
+	return result, rows.Err()
+}
+
+// Occupancy counts attendees whose latest pass through the door is an
+// entry, and everyone who has come in at least once.
+func (r *CheckinRepo) Occupancy(ctx context.Context, eventID shared.ID, zoneID *shared.ID) (inside, attended int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE direction = 'in'), COUNT(*)
+		FROM (
+			SELECT DISTINCT ON (user_id) user_id, direction
+			FROM checkins
+			WHERE event_id = $1 AND zone_id IS NOT DISTINCT FROM $2::uuid
+			ORDER BY user_id, at DESC, id DESC
+		) latest
+	`
+	err = r.db.conn(ctx).QueryRow(ctx, query, eventID, zoneID).Scan(&inside, &attended)
+	return inside, attended, err
+}
 
-	var c checkin.Checkin
-	err := r.db.pool.QueryRow(ctx, query, eventID, userID).Scan(
-		&c.ID, &c.EventID, &c.UserID, &c.Method, &c.At,
+type AttemptRepo struct {
+	db *DB
+}
+
+func NewAttemptRepo(db *DB) *AttemptRepo {
+	return &AttemptRepo{db: db}
+}
+
+// Create refuses a second upload of the same offline scan with
+// ErrAttemptExists.
+func (r *AttemptRepo) Create(ctx context.Context, a *checkin.Attempt) error {
+	query := `
+		INSERT INTO checkin_attempts (
+			id, event_id, scanner_id, device_id, zone_id, ip, user_agent, method, action,
+			ticket_id, user_id, accepted, reason, checkin_id, local_id, at, received_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (event_id, device_id, local_id) WHERE local_id <> '' DO NOTHING
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query,
+		a.ID, a.EventID, a.ScannerID, a.DeviceID, a.ZoneID, a.IP, a.UserAgent, a.Method, a.Action,
+		a.TicketID, a.UserID, a.Accepted, a.Reason, a.CheckinID, a.LocalID, a.At, a.ReceivedAt,
 	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return checkin.ErrAttemptExists
+	}
+	return nil
+}
 
+func (r *AttemptRepo) GetByLocalID(ctx context.Context, eventID shared.ID, deviceID, localID string) (*checkin.Attempt, error) {
+	query := `
+		SELECT ` + attemptColumns + `
+		FROM checkin_attempts
+		WHERE event_id = $1 AND device_id = $2 AND local_id = $3 AND local_id <> ''
+	`
+	a, err := r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, eventID, deviceID, localID))
 	if err == pgx.ErrNoRows {
-		return nil, nil
+		return nil, checkin.ErrAttemptNotFound
 	}
+	return a, err
+}
+
+func (r *AttemptRepo) ListByEvent(ctx context.Context, eventID shared.ID, limit, offset int) ([]*checkin.Attempt, error) {
+	query := `
+		SELECT ` + attemptColumns + `
+		FROM checkin_attempts
+		WHERE event_id = $1
+		ORDER BY received_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &c, nil
+	result := []*checkin.Attempt{}
+	for rows.Next() {
+		a, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+
+	return result, rows.Err()
 }
 
-func (r *CheckinRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Checkin, error) {
+const attemptColumns = `id, event_id, scanner_id, device_id, zone_id, ip, user_agent, method, action,
+		       ticket_id, user_id, accepted, reason, checkin_id, local_id, at, received_at`
+
+func (r *AttemptRepo) scanOne(row pgx.Row) (*checkin.Attempt, error) {
+	var a checkin.Attempt
+	err := row.Scan(
+		&a.ID, &a.EventID, &a.ScannerID, &a.DeviceID, &a.ZoneID, &a.IP, &a.UserAgent, &a.Method, &a.Action,
+		&a.TicketID, &a.UserID, &a.Accepted, &a.Reason, &a.CheckinID, &a.LocalID, &a.At, &a.ReceivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+type DeviceRepo struct {
+	db *DB
+}
+
+func NewDeviceRepo(db *DB) *DeviceRepo {
+	return &DeviceRepo{db: db}
+}
+
+func (r *DeviceRepo) Get(ctx context.Context, eventID shared.ID, deviceID string) (*checkin.Device, error) {
 	query := `
-		SELECT id, event_id, user_id, method, at
-		FROM checkins
+		SELECT ` + deviceColumns + `
+		FROM scanner_devices
+		WHERE event_id = $1 AND device_id = $2
+	`
+	d, err := r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, eventID, deviceID))
+	if err == pgx.ErrNoRows {
+		return nil, checkin.ErrDeviceNotFound
+	}
+	return d, err
+}
+
+// Upsert saves what the device reported. Times only move forward, so a
+// request that read the row before another one wrote it cannot undo it;
+// the scan counters are left to Tally.
+func (r *DeviceRepo) Upsert(ctx context.Context, d *checkin.Device) error {
+	query := `
+		INSERT INTO scanner_devices (
+			event_id, device_id, scanner_id, user_agent, manifest_cursor, manifest_at, uploaded_at,
+			last_scan_at, uploaded, accepted, conflicts, rejected, pending
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (event_id, device_id) DO UPDATE
+		SET scanner_id = EXCLUDED.scanner_id, user_agent = EXCLUDED.user_agent,
+		    manifest_cursor = GREATEST(scanner_devices.manifest_cursor, EXCLUDED.manifest_cursor),
+		    manifest_at = GREATEST(scanner_devices.manifest_at, EXCLUDED.manifest_at),
+		    uploaded_at = GREATEST(scanner_devices.uploaded_at, EXCLUDED.uploaded_at),
+		    pending = EXCLUDED.pending
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		d.EventID, d.DeviceID, d.ScannerID, d.UserAgent, d.ManifestCursor, d.ManifestAt, d.UploadedAt,
+		d.LastScanAt, d.Uploaded, d.Accepted, d.Conflicts, d.Rejected, d.Pending,
+	)
+	return err
+}
+
+// Tally counts one scan the device uploaded, settled with status. The
+// counters are only ever moved here, so concurrent uploads add up.
+func (r *DeviceRepo) Tally(ctx context.Context, eventID shared.ID, deviceID string, status checkin.SyncStatus, scannedAt time.Time) error {
+	query := `
+		UPDATE scanner_devices
+		SET uploaded = uploaded + 1,
+		    accepted = accepted + ($3 = 'accepted')::int,
+		    conflicts = conflicts + ($3 = 'conflict')::int,
+		    rejected = rejected + ($3 = 'rejected')::int,
+		    last_scan_at = GREATEST(last_scan_at, $4)
+		WHERE event_id = $1 AND device_id = $2
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query, eventID, deviceID, status, scannedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return checkin.ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (r *DeviceRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM scanner_devices
 		WHERE event_id = $1
-		ORDER BY at DESC
+		ORDER BY GREATEST(uploaded_at, manifest_at) DESC NULLS LAST, device_id
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, eventID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []*checkin.Checkin
+	result := []*checkin.Device{}
 	for rows.Next() {
-		var c checkin.Checkin
-		err := rows.Scan(&c.ID, &c.EventID, &c.UserID, &c.Method, &c.At)
+		d, err := r.scanOne(rows)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, &c)
+		result = append(result, d)
 	}
 
 	return result, rows.Err()
 }
 
-type QRTokenRepo struct {
+const deviceColumns = `event_id, device_id, scanner_id, user_agent, manifest_cursor, manifest_at, uploaded_at,
+		       last_scan_at, uploaded, accepted, conflicts, rejected, pending`
+
+func (r *DeviceRepo) scanOne(row pgx.Row) (*checkin.Device, error) {
+	var d checkin.Device
+	err := row.Scan(
+		&d.EventID, &d.DeviceID, &d.ScannerID, &d.UserAgent, &d.ManifestCursor, &d.ManifestAt, &d.UploadedAt,
+		&d.LastScanAt, &d.Uploaded, &d.Accepted, &d.Conflicts, &d.Rejected, &d.Pending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+type TicketRepo struct {
 	db *DB
 }
 
-func NewQRTokenRepo(db *DB) *QRTokenRepo {
-	return &QRTokenRepo{db: db}
+func NewTicketRepo(db *DB) *TicketRepo {
+	return &TicketRepo{db: db}
 }
 
-func (r *QRTokenRepo) Create(ctx context.Context, token *checkin.QRToken) error {
+// Create does nothing when the attendee already holds a live ticket.
+func (r *TicketRepo) Create(ctx context.Context, t *checkin.Ticket) error {
 	query := `
-		INSERT INTO qr_tokens (id, user_id, event_id, token_hash, expires_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO tickets (id, event_id, user_id, ticket_type_id, valid_from, valid_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (event_id, user_id) WHERE revoked_at IS NULL DO NOTHING
 	`
-	_, err := r.db.pool.Exec(ctx, query,
-		token.ID, token.UserID, token.EventID, token.TokenHash,
-		token.ExpiresAt, token.CreatedAt, token.UpdatedAt,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		t.ID, t.EventID, t.UserID, t.TicketTypeID, t.ValidFrom, t.ValidUntil, t.CreatedAt, t.UpdatedAt,
 	)
 	return err
 }
 
-func (r *QRTokenRepo) GetByHash(ctx context.Context, hash []byte) (*checkin.QRToken, error) {
+func (r *TicketRepo) Update(ctx context.Context, t *checkin.Ticket) error {
 	query := `
-		SELECT id, user_id, event_id, token_hash, expires_at, created_at, updated_at
-		FROM qr_tokens
-		WHERE token_hash = $1
+		UPDATE tickets
+		SET ticket_type_id = $2, valid_from = $3, valid_until = $4, revoked_at = $5, revoke_reason = $6, updated_at = $7
+		WHERE id = $1
 	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		t.ID, t.TicketTypeID, t.ValidFrom, t.ValidUntil, t.RevokedAt, t.RevokeReason, t.UpdatedAt,
+	)
+	return err
+}
+
+func (r *TicketRepo) GetByID(ctx context.Context, id shared.ID) (*checkin.Ticket, error) {
+	query := `
+		SELECT id, event_id, user_id, ticket_type_id, valid_from, valid_until, revoked_at, revoke_reason, created_at, updated_at
+		FROM tickets
+		WHERE id = $1
+	`
+	return r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+func (r *TicketRepo) GetLiveByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*checkin.Ticket, error) {
+	query := `
+		SELECT id, event_id, user_id, ticket_type_id, valid_from, valid_until, revoked_at, revoke_reason, created_at, updated_at
+		FROM tickets
+		WHERE event_id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	return r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, eventID, userID))
+}
 
-	var token checkin.QRToken
-	err := r.db.pool.QueryRow(ctx, query, hash).Scan(
-		&token.ID, &token.UserID, &token.EventID, &token.TokenHash,
-		&token.ExpiresAt, &token.CreatedAt, &token.UpdatedAt,
+func (r *TicketRepo) scanOne(row pgx.Row) (*checkin.Ticket, error) {
+	var t checkin.Ticket
+	err := row.Scan(
+		&t.ID, &t.EventID, &t.UserID, &t.TicketTypeID, &t.ValidFrom, &t.ValidUntil,
+		&t.RevokedAt, &t.RevokeReason, &t.CreatedAt, &t.UpdatedAt,
 	)
+	if err == pgx.ErrNoRows {
+		return nil, checkin.ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListRevocations covers tickets revoked outright and tickets whose
+// registration is gone or no longer "going". Expired tickets are left out.
+func (r *TicketRepo) ListRevocations(ctx context.Context, eventID shared.ID) ([]checkin.Revocation, error) {
+	query := `
+		SELECT t.id,
+		       COALESCE(t.revoked_at, reg.updated_at, t.updated_at),
+		       CASE
+		           WHEN t.revoked_at IS NOT NULL THEN COALESCE(NULLIF(t.revoke_reason, ''), 'revoked')
+		           WHEN reg.id IS NULL THEN 'registration cancelled'
+		           ELSE 'registration ' || reg.status
+		       END
+		FROM tickets t
+		LEFT JOIN registrations reg ON reg.event_id = t.event_id AND reg.user_id = t.user_id
+		WHERE t.event_id = $1 AND t.valid_until > NOW()
+		  AND (t.revoked_at IS NOT NULL OR reg.id IS NULL OR reg.status <> 'going')
+		ORDER BY 2
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []checkin.Revocation{}
+	for rows.Next() {
+		var rev checkin.Revocation
+		if err := rows.Scan(&rev.TicketID, &rev.RevokedAt, &rev.Reason); err != nil {
+			return nil, err
+		}
+		result = append(result, rev)
+	}
+
+	return result, rows.Err()
+}
+
+// ListManifest joins each attendee's registration with their latest ticket,
+// the live one if any, and whether their last pass was an entry. Passes
+// are compared with since by when the server received them, as a synced
+// offline pass can be hours older than its upload. Tickets whose
+// registration is gone are always included, since a deleted row leaves no
+// timestamp to compare with since.
+func (r *TicketRepo) ListManifest(ctx context.Context, eventID shared.ID, since *time.Time) ([]checkin.ManifestEntry, error) {
+	query := `
+		WITH regs AS (
+			SELECT user_id, status, ticket_type_id, updated_at
+			FROM registrations
+			WHERE event_id = $1
+		), tix AS (
+			SELECT DISTINCT ON (user_id) user_id, id, ticket_type_id, valid_from, valid_until, revoked_at, updated_at
+			FROM tickets
+			WHERE event_id = $1
+			ORDER BY user_id, revoked_at IS NULL DESC, created_at DESC
+		), passes AS (
+			SELECT DISTINCT ON (user_id) user_id, direction,
+			       MAX(received_at) OVER (PARTITION BY user_id) AS received_at
+			FROM checkins
+			WHERE event_id = $1 AND zone_id IS NULL
+			ORDER BY user_id, at DESC, id DESC
+		)
+		SELECT u.id, COALESCE(up.display_name, ''), COALESCE(reg.status, 'cancelled'),
+		       t.id, COALESCE(t.ticket_type_id, reg.ticket_type_id), t.valid_from, t.valid_until,
+		       t.revoked_at IS NOT NULL, COALESCE(p.direction = 'in', false),
+		       GREATEST(reg.updated_at, t.updated_at, p.received_at)
+		FROM regs reg
+		FULL JOIN tix t ON t.user_id = reg.user_id
+		JOIN users u ON u.id = COALESCE(reg.user_id, t.user_id)
+		LEFT JOIN user_profiles up ON up.user_id = u.id
+		LEFT JOIN passes p ON p.user_id = u.id
+		WHERE $2::timestamptz IS NULL
+		   OR GREATEST(reg.updated_at, t.updated_at, p.received_at) > $2
+		   OR reg.user_id IS NULL
+		ORDER BY u.id
+	`
 
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []checkin.ManifestEntry{}
+	for rows.Next() {
+		var e checkin.ManifestEntry
+		err := rows.Scan(
+			&e.UserID, &e.Name, &e.Status, &e.TicketID, &e.TicketTypeID, &e.ValidFrom, &e.ValidUntil,
+			&e.Revoked, &e.Inside, &e.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	return result, rows.Err()
+}
+
+// SigningKeyRepo keeps private keys sealed with AES-GCM under a key derived
+// from the server secret.
+type SigningKeyRepo struct {
+	db   *DB
+	aead cipher.AEAD
+}
+
+func NewSigningKeyRepo(db *DB, secret string) (*SigningKeyRepo, error) {
+	sum := sha256.Sum256([]byte("ticket-signing-keys:" + secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKeyRepo{db: db, aead: aead}, nil
+}
+
+// Create does nothing if another key became active in the meantime.
+func (r *SigningKeyRepo) Create(ctx context.Context, key *checkin.SigningKey) error {
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := r.aead.Seal(nonce, nonce, key.PrivateKey, []byte(key.ID))
+
+	query := `
+		INSERT INTO ticket_signing_keys (id, public_key, private_key, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, key.ID, []byte(key.PublicKey), sealed, key.CreatedAt)
+	return err
+}
+
+func (r *SigningKeyRepo) GetActive(ctx context.Context) (*checkin.SigningKey, error) {
+	query := `
+		SELECT id, public_key, private_key, created_at, signed_until
+		FROM ticket_signing_keys
+		WHERE retired_at IS NULL
+	`
+
+	var key checkin.SigningKey
+	var pub, sealed []byte
+	err := r.db.conn(ctx).QueryRow(ctx, query).Scan(&key.ID, &pub, &sealed, &key.CreatedAt, &key.SignedUntil)
 	if err == pgx.ErrNoRows {
-		return nil, checkin.ErrInvalidQRToken
+		return nil, checkin.ErrNoSigningKey
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &token, nil
+	n := r.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("corrupt ticket signing key")
+	}
+	priv, err := r.aead.Open(nil, sealed[:n], sealed[n:], []byte(key.ID))
+	if err != nil {
+		return nil, fmt.Errorf("open ticket signing key %s: %w", key.ID, err)
+	}
+
+	key.PublicKey = ed25519.PublicKey(pub)
+	key.PrivateKey = ed25519.PrivateKey(priv)
+	return &key, nil
+}
+
+// ListPublished returns the public halves of every key that still
+// verifies, newest first.
+func (r *SigningKeyRepo) ListPublished(ctx context.Context) ([]*checkin.SigningKey, error) {
+	query := `
+		SELECT id, public_key, created_at, retired_at, expires_at
+		FROM ticket_signing_keys
+		WHERE expires_at IS NULL OR expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*checkin.SigningKey
+	for rows.Next() {
+		var key checkin.SigningKey
+		var pub []byte
+		if err := rows.Scan(&key.ID, &pub, &key.CreatedAt, &key.RetiredAt, &key.ExpiresAt); err != nil {
+			return nil, err
+		}
+		key.PublicKey = ed25519.PublicKey(pub)
+		result = append(result, &key)
+	}
+
+	return result, rows.Err()
+}
+
+// RetireActive keeps the retired key verifying until expiresAt or until
+// the last ticket it signed runs out, whichever is later.
+func (r *SigningKeyRepo) RetireActive(ctx context.Context, createdBefore, expiresAt time.Time) error {
+	query := `
+		UPDATE ticket_signing_keys
+		SET retired_at = NOW(), expires_at = GREATEST($2, signed_until)
+		WHERE retired_at IS NULL AND created_at < $1
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, createdBefore, expiresAt)
+	return err
 }
 
-func (r *QRTokenRepo) DeleteExpired(ctx context.Context) error {
-	query := `DELETE FROM qr_tokens WHERE expires_at < NOW()`
-	_, err := r.db.pool.Exec(ctx, query)
+// Cover records that the key signed a ticket valid until the given time.
+// A key retired in the meantime has its expiry pushed back to match.
+func (r *SigningKeyRepo) Cover(ctx context.Context, id string, until time.Time) error {
+	query := `
+		UPDATE ticket_signing_keys
+		SET signed_until = GREATEST(signed_until, $2),
+		    expires_at = GREATEST(expires_at, $2)
+		WHERE id = $1
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, id, until)
 	return err
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -41,3 +44,18 @@ func (db *DB) Close() {
 func (db *DB) Pool() *pgxpool.Pool {
 	return db.pool
 }
+
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn returns the transaction bound to ctx by uow.WithTx, falling back to
+// the pool, so repositories join an open unit of work transparently.
+func (db *DB) conn(ctx context.Context) querier {
+	if tx, ok := uow.TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.pool
+}
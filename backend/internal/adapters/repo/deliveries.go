@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/notifications"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type DeliveryRepo struct {
+	db *DB
+}
+
+func NewDeliveryRepo(db *DB) *DeliveryRepo {
+	return &DeliveryRepo{db: db}
+}
+
+// RecordReminder stores the outcome of a reminder send. Retries of the same
+// reminder update the existing row and count the attempt.
+func (r *DeliveryRepo) RecordReminder(ctx context.Context, d *notifications.Delivery) error {
+	query := `
+		INSERT INTO deliveries (
+			id, reminder_id, channel, target_user_id, message_id, status, error, attempts, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8, $9, $10)
+		ON CONFLICT (reminder_id, target_user_id) WHERE reminder_id IS NOT NULL DO UPDATE SET
+			message_id = EXCLUDED.message_id,
+			status = EXCLUDED.status,
+			error = EXCLUDED.error,
+			attempts = deliveries.attempts + EXCLUDED.attempts,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		d.ID, d.ReminderID, d.Channel, d.TargetUser, d.MessageID, d.Status, d.Error,
+		d.Attempts, d.CreatedAt, d.UpdatedAt,
+	)
+	return err
+}
+
+// ListReminderRecipients returns the users a reminder has already reached.
+func (r *DeliveryRepo) ListReminderRecipients(ctx context.Context, reminderID shared.ID) ([]shared.ID, error) {
+	query := `SELECT target_user_id FROM deliveries WHERE reminder_id = $1 AND status = 'sent'`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []shared.ID
+	for rows.Next() {
+		var userID shared.ID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		result = append(result, userID)
+	}
+
+	return result, rows.Err()
+}
+
+// RecordCampaign stores the outcome of a campaign send to one user.
+func (r *DeliveryRepo) RecordCampaign(ctx context.Context, d *notifications.Delivery) error {
+	query := `
+		INSERT INTO deliveries (
+			id, campaign_id, channel, target_user_id, message_id, status, error, attempts, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8, $9, $10)
+		ON CONFLICT (campaign_id, target_user_id) WHERE campaign_id IS NOT NULL DO UPDATE SET
+			message_id = EXCLUDED.message_id,
+			status = EXCLUDED.status,
+			error = EXCLUDED.error,
+			attempts = deliveries.attempts + EXCLUDED.attempts,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		d.ID, d.CampaignID, d.Channel, d.TargetUser, d.MessageID, d.Status, d.Error,
+		d.Attempts, d.CreatedAt, d.UpdatedAt,
+	)
+	return err
+}
+
+// ListCampaignRecipients returns the users a campaign has already reached.
+func (r *DeliveryRepo) ListCampaignRecipients(ctx context.Context, campaignID shared.ID) ([]shared.ID, error) {
+	query := `SELECT target_user_id FROM deliveries WHERE campaign_id = $1 AND status = 'sent'`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []shared.ID
+	for rows.Next() {
+		var userID shared.ID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		result = append(result, userID)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *DeliveryRepo) ListByCampaign(ctx context.Context, campaignID shared.ID) ([]*notifications.Delivery, error) {
+	query := `
+		SELECT id, campaign_id, channel, target_user_id, COALESCE(message_id, ''), status,
+			COALESCE(error, ''), attempts, created_at, updated_at
+		FROM deliveries
+		WHERE campaign_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*notifications.Delivery
+	for rows.Next() {
+		var d notifications.Delivery
+		if err := rows.Scan(
+			&d.ID, &d.CampaignID, &d.Channel, &d.TargetUser, &d.MessageID, &d.Status,
+			&d.Error, &d.Attempts, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, &d)
+	}
+
+	return result, rows.Err()
+}
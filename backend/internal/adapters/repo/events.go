@@ -21,34 +21,63 @@ func (r *EventRepo) Create(ctx context.Context, event *events.Event) error {
 		INSERT INTO events (
 			id, owner_id, title, description, visibility, status,
 			starts_at, ends_at, tz, location, online_url,
-			capacity, waitlist_enabled, created_at, updated_at
+			capacity, waitlist_enabled, series_id, original_starts_at,
+			share_token, organization_id, settings, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), $17, $18, $19, $20
 		)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		event.ID, event.OwnerID, event.Title, event.Description,
 		event.Visibility, event.Status, event.StartsAt, event.EndsAt,
 		event.Timezone, event.Location, event.OnlineURL, event.Capacity,
-		event.Waitlist, event.CreatedAt, event.UpdatedAt,
+		event.Waitlist, event.SeriesID, event.OriginalStartsAt,
+		event.ShareToken, event.OrganizationID, event.Settings, event.CreatedAt, event.UpdatedAt,
 	)
 	return err
 }
 
+func (r *EventRepo) CreateOccurrence(ctx context.Context, event *events.Event) (bool, error) {
+	query := `
+		INSERT INTO events (
+			id, owner_id, title, description, visibility, status,
+			starts_at, ends_at, tz, location, online_url,
+			capacity, waitlist_enabled, series_id, original_starts_at,
+			share_token, organization_id, settings, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), $17, $18, $19, $20
+		)
+		ON CONFLICT (series_id, original_starts_at) DO NOTHING
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query,
+		event.ID, event.OwnerID, event.Title, event.Description,
+		event.Visibility, event.Status, event.StartsAt, event.EndsAt,
+		event.Timezone, event.Location, event.OnlineURL, event.Capacity,
+		event.Waitlist, event.SeriesID, event.OriginalStartsAt,
+		event.ShareToken, event.OrganizationID, event.Settings, event.CreatedAt, event.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *EventRepo) Update(ctx context.Context, event *events.Event) error {
 	query := `
 		UPDATE events SET
 			title = $2, description = $3, visibility = $4, status = $5,
 			starts_at = $6, ends_at = $7, tz = $8, location = $9,
 			online_url = $10, capacity = $11, waitlist_enabled = $12,
-			updated_at = $13
+			series_id = $13, original_starts_at = $14, share_token = NULLIF($15, ''),
+			organization_id = $16, owner_id = $17, settings = $18, updated_at = $19
 		WHERE id = $1
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		event.ID, event.Title, event.Description, event.Visibility,
 		event.Status, event.StartsAt, event.EndsAt, event.Timezone,
 		event.Location, event.OnlineURL, event.Capacity, event.Waitlist,
-		event.UpdatedAt,
+		event.SeriesID, event.OriginalStartsAt, event.ShareToken, event.OrganizationID, event.OwnerID,
+		event.Settings, event.UpdatedAt,
 	)
 	return err
 }
@@ -57,17 +86,52 @@ func (r *EventRepo) GetByID(ctx context.Context, id shared.ID) (*events.Event, e
 	query := `
 		SELECT id, owner_id, title, description, visibility, status,
 		       starts_at, ends_at, tz, location, online_url,
-		       capacity, waitlist_enabled, created_at, updated_at
+		       capacity, waitlist_enabled, series_id, original_starts_at,
+		       COALESCE(share_token, ''), organization_id,
+		       COALESCE(settings, '{}'::jsonb), created_at, updated_at
 		FROM events
 		WHERE id = $1
 	`
 
 	var event events.Event
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&event.ID, &event.OwnerID, &event.Title, &event.Description,
 		&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
 		&event.Timezone, &event.Location, &event.OnlineURL,
-		&event.Capacity, &event.Waitlist, &event.CreatedAt, &event.UpdatedAt,
+		&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+		&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, events.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Lock reads the event and holds its row until the transaction ends.
+func (r *EventRepo) Lock(ctx context.Context, id shared.ID) (*events.Event, error) {
+	query := `
+		SELECT id, owner_id, title, description, visibility, status,
+		       starts_at, ends_at, tz, location, online_url,
+		       capacity, waitlist_enabled, series_id, original_starts_at,
+		       COALESCE(share_token, ''), organization_id,
+		       COALESCE(settings, '{}'::jsonb), created_at, updated_at
+		FROM events
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var event events.Event
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&event.ID, &event.OwnerID, &event.Title, &event.Description,
+		&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
+		&event.Timezone, &event.Location, &event.OnlineURL,
+		&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+		&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -84,7 +148,7 @@ func (r *EventRepo) GetCapacity(ctx context.Context, eventID shared.ID) (int, er
 	query := `SELECT capacity FROM events WHERE id = $1`
 
 	var capacity int
-	err := r.db.pool.QueryRow(ctx, query, eventID).Scan(&capacity)
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(&capacity)
 	if err == pgx.ErrNoRows {
 		return 0, events.ErrEventNotFound
 	}
@@ -95,18 +159,36 @@ func (r *EventRepo) GetCapacity(ctx context.Context, eventID shared.ID) (int, er
 	return capacity, nil
 }
 
+func (r *EventRepo) LockCapacity(ctx context.Context, eventID shared.ID) (int, bool, error) {
+	query := `SELECT capacity, waitlist_enabled FROM events WHERE id = $1 FOR UPDATE`
+
+	var capacity int
+	var waitlist bool
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(&capacity, &waitlist)
+	if err == pgx.ErrNoRows {
+		return 0, false, events.ErrEventNotFound
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return capacity, waitlist, nil
+}
+
 func (r *EventRepo) ListPublic(ctx context.Context, limit, offset int) ([]*events.Event, error) {
 	query := `
 		SELECT id, owner_id, title, description, visibility, status,
 		       starts_at, ends_at, tz, location, online_url,
-		       capacity, waitlist_enabled, created_at, updated_at
+		       capacity, waitlist_enabled, series_id, original_starts_at,
+		       COALESCE(share_token, ''), organization_id,
+		       COALESCE(settings, '{}'::jsonb), created_at, updated_at
 		FROM events
-		WHERE status = 'published' AND visibility IN ('public', 'by_link')
+		WHERE status = 'published' AND visibility IN ('public', 'request')
 		ORDER BY starts_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.conn(ctx).Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +201,8 @@ func (r *EventRepo) ListPublic(ctx context.Context, limit, offset int) ([]*event
 			&event.ID, &event.OwnerID, &event.Title, &event.Description,
 			&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
 			&event.Timezone, &event.Location, &event.OnlineURL,
-			&event.Capacity, &event.Waitlist, &event.CreatedAt, &event.UpdatedAt,
+			&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+			&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -134,14 +217,16 @@ func (r *EventRepo) ListByUser(ctx context.Context, userID shared.ID) ([]*events
 	query := `
 		SELECT e.id, e.owner_id, e.title, e.description, e.visibility, e.status,
 		       e.starts_at, e.ends_at, e.tz, e.location, e.online_url,
-		       e.capacity, e.waitlist_enabled, e.created_at, e.updated_at
+		       e.capacity, e.waitlist_enabled, e.series_id, e.original_starts_at,
+		       COALESCE(e.share_token, ''), e.organization_id,
+		       COALESCE(e.settings, '{}'::jsonb), e.created_at, e.updated_at
 		FROM events e
 		JOIN registrations r ON r.event_id = e.id
 		WHERE r.user_id = $1 AND r.status = 'going'
 		ORDER BY e.starts_at DESC
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, userID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +239,82 @@ func (r *EventRepo) ListByUser(ctx context.Context, userID shared.ID) ([]*events
 			&event.ID, &event.OwnerID, &event.Title, &event.Description,
 			&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
 			&event.Timezone, &event.Location, &event.OnlineURL,
-			&event.Capacity, &event.Waitlist, &event.CreatedAt, &event.UpdatedAt,
+			&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+			&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &event)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *EventRepo) ListBySeries(ctx context.Context, seriesID shared.ID) ([]*events.Event, error) {
+	query := `
+		SELECT id, owner_id, title, description, visibility, status,
+		       starts_at, ends_at, tz, location, online_url,
+		       capacity, waitlist_enabled, series_id, original_starts_at,
+		       COALESCE(share_token, ''), organization_id,
+		       COALESCE(settings, '{}'::jsonb), created_at, updated_at
+		FROM events
+		WHERE series_id = $1
+		ORDER BY original_starts_at ASC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*events.Event
+	for rows.Next() {
+		var event events.Event
+		err := rows.Scan(
+			&event.ID, &event.OwnerID, &event.Title, &event.Description,
+			&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
+			&event.Timezone, &event.Location, &event.OnlineURL,
+			&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+			&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &event)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *EventRepo) ListByOrganization(ctx context.Context, orgID shared.ID) ([]*events.Event, error) {
+	query := `
+		SELECT id, owner_id, title, description, visibility, status,
+		       starts_at, ends_at, tz, location, online_url,
+		       capacity, waitlist_enabled, series_id, original_starts_at,
+		       COALESCE(share_token, ''), organization_id,
+		       COALESCE(settings, '{}'::jsonb), created_at, updated_at
+		FROM events
+		WHERE organization_id = $1 AND status <> 'draft'
+		ORDER BY starts_at ASC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*events.Event
+	for rows.Next() {
+		var event events.Event
+		err := rows.Scan(
+			&event.ID, &event.OwnerID, &event.Title, &event.Description,
+			&event.Visibility, &event.Status, &event.StartsAt, &event.EndsAt,
+			&event.Timezone, &event.Location, &event.OnlineURL,
+			&event.Capacity, &event.Waitlist, &event.SeriesID, &event.OriginalStartsAt,
+			&event.ShareToken, &event.OrganizationID, &event.Settings, &event.CreatedAt, &event.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -167,7 +327,7 @@ func (r *EventRepo) ListByUser(ctx context.Context, userID shared.ID) ([]*events
 
 func (r *EventRepo) Delete(ctx context.Context, id shared.ID) error {
 	query := `DELETE FROM events WHERE id = $1`
-	_, err := r.db.pool.Exec(ctx, query, id)
+	_, err := r.db.conn(ctx).Exec(ctx, query, id)
 	return err
 }
 
@@ -179,16 +339,20 @@ func NewRoleRepo(db *DB) *RoleRepo {
 	return &RoleRepo{db: db}
 }
 
+// Create grants the role, replacing any role the user already has.
 func (r *RoleRepo) Create(ctx context.Context, eventID, userID shared.ID, role events.Role) error {
-	query := `INSERT INTO event_roles (id, event_id, user_id, role) VALUES (gen_random_uuid(), $1, $2, $3)`
-	_, err := r.db.pool.Exec(ctx, query, eventID, userID, role)
+	query := `
+		INSERT INTO event_roles (id, event_id, user_id, role) VALUES (gen_random_uuid(), $1, $2, $3)
+		ON CONFLICT (event_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID, role)
 	return err
 }
 
 func (r *RoleRepo) GetUserRole(ctx context.Context, eventID, userID shared.ID) (events.Role, error) {
 	query := `SELECT role FROM event_roles WHERE event_id = $1 AND user_id = $2`
 	var role events.Role
-	err := r.db.pool.QueryRow(ctx, query, eventID, userID).Scan(&role)
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID).Scan(&role)
 	if err == pgx.ErrNoRows {
 		return "", nil
 	}
@@ -197,7 +361,7 @@ func (r *RoleRepo) GetUserRole(ctx context.Context, eventID, userID shared.ID) (
 
 func (r *RoleRepo) ListByEvent(ctx context.Context, eventID shared.ID) (map[shared.ID]events.Role, error) {
 	query := `SELECT user_id, role FROM event_roles WHERE event_id = $1`
-	rows, err := r.db.pool.Query(ctx, query, eventID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +382,148 @@ func (r *RoleRepo) ListByEvent(ctx context.Context, eventID shared.ID) (map[shar
 
 func (r *RoleRepo) Delete(ctx context.Context, eventID, userID shared.ID) error {
 	query := `DELETE FROM event_roles WHERE event_id = $1 AND user_id = $2`
-	_, err := r.db.pool.Exec(ctx, query, eventID, userID)
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID)
+	return err
+}
+
+type RoleInviteRepo struct {
+	db *DB
+}
+
+func NewRoleInviteRepo(db *DB) *RoleInviteRepo {
+	return &RoleInviteRepo{db: db}
+}
+
+func (r *RoleInviteRepo) Create(ctx context.Context, invite *events.RoleInvite) error {
+	query := `
+		INSERT INTO event_role_invites (id, event_id, role, token, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		invite.ID, invite.EventID, invite.Role, invite.Token, invite.CreatedBy, invite.ExpiresAt, invite.CreatedAt,
+	)
+	return err
+}
+
+// MarkUsed claims the invite for invite.UsedBy. It reports false when the
+// invite was claimed concurrently.
+func (r *RoleInviteRepo) MarkUsed(ctx context.Context, invite *events.RoleInvite) (bool, error) {
+	query := `
+		UPDATE event_role_invites SET used_by = $2, used_at = $3
+		WHERE id = $1 AND used_by IS NULL
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query, invite.ID, invite.UsedBy, invite.UsedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *RoleInviteRepo) GetByToken(ctx context.Context, token string) (*events.RoleInvite, error) {
+	query := `
+		SELECT id, event_id, role, token, created_by, expires_at, used_by, used_at, created_at
+		FROM event_role_invites
+		WHERE token = $1
+	`
+
+	var invite events.RoleInvite
+	err := r.db.conn(ctx).QueryRow(ctx, query, token).Scan(
+		&invite.ID, &invite.EventID, &invite.Role, &invite.Token, &invite.CreatedBy,
+		&invite.ExpiresAt, &invite.UsedBy, &invite.UsedAt, &invite.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, events.ErrRoleInviteInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *RoleInviteRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*events.RoleInvite, error) {
+	query := `
+		SELECT id, event_id, role, token, created_by, expires_at, used_by, used_at, created_at
+		FROM event_role_invites
+		WHERE event_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*events.RoleInvite
+	for rows.Next() {
+		var invite events.RoleInvite
+		err := rows.Scan(
+			&invite.ID, &invite.EventID, &invite.Role, &invite.Token, &invite.CreatedBy,
+			&invite.ExpiresAt, &invite.UsedBy, &invite.UsedAt, &invite.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &invite)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *RoleInviteRepo) Delete(ctx context.Context, eventID, id shared.ID) error {
+	query := `DELETE FROM event_role_invites WHERE event_id = $1 AND id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, id)
+	return err
+}
+
+type InviteRepo struct {
+	db *DB
+}
+
+func NewInviteRepo(db *DB) *InviteRepo {
+	return &InviteRepo{db: db}
+}
+
+func (r *InviteRepo) Create(ctx context.Context, eventID, userID, invitedBy shared.ID) error {
+	query := `
+		INSERT INTO event_invites (event_id, user_id, invited_by, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (event_id, user_id) DO NOTHING
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID, invitedBy)
+	return err
+}
+
+func (r *InviteRepo) Exists(ctx context.Context, eventID, userID shared.ID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM event_invites WHERE event_id = $1 AND user_id = $2)`
+	var exists bool
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *InviteRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]shared.ID, error) {
+	query := `SELECT user_id FROM event_invites WHERE event_id = $1 ORDER BY created_at`
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []shared.ID
+	for rows.Next() {
+		var userID shared.ID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		result = append(result, userID)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *InviteRepo) Delete(ctx context.Context, eventID, userID shared.ID) error {
+	query := `DELETE FROM event_invites WHERE event_id = $1 AND user_id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID)
 	return err
 }
@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+type FileRepo struct {
+	db *DB
+}
+
+func NewFileRepo(db *DB) *FileRepo {
+	return &FileRepo{db: db}
+}
+
+func (r *FileRepo) Create(ctx context.Context, file *forms.File) error {
+	query := `
+		INSERT INTO form_files (
+			id, event_id, form_id, user_id, response_id, field_id, name, content_type, size, storage_key, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		file.ID, file.EventID, file.FormID, file.UserID, file.ResponseID, file.FieldID,
+		file.Name, file.ContentType, file.Size, file.StorageKey, file.CreatedAt,
+	)
+	return err
+}
+
+func (r *FileRepo) GetByID(ctx context.Context, id shared.ID) (*forms.File, error) {
+	query := `
+		SELECT id, event_id, form_id, user_id, response_id, field_id, name, content_type, size, storage_key, created_at
+		FROM form_files
+		WHERE id = $1
+	`
+
+	file, err := r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, forms.ErrFileNotFound
+	}
+	return file, err
+}
+
+func (r *FileRepo) ListByResponse(ctx context.Context, responseID shared.ID) ([]*forms.File, error) {
+	query := `
+		SELECT id, event_id, form_id, user_id, response_id, field_id, name, content_type, size, storage_key, created_at
+		FROM form_files
+		WHERE response_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, responseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*forms.File
+	for rows.Next() {
+		file, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, file)
+	}
+
+	return result, rows.Err()
+}
+
+// LinkToResponse attaches files to the response that references them.
+func (r *FileRepo) LinkToResponse(ctx context.Context, ids []shared.ID, responseID shared.ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE form_files SET response_id = $2 WHERE id = ANY($1)`
+	_, err := r.db.conn(ctx).Exec(ctx, query, ids, responseID)
+	return err
+}
+
+// ListUnattached returns files uploaded before the cutoff that no response
+// or draft references.
+func (r *FileRepo) ListUnattached(ctx context.Context, before time.Time, limit int) ([]*forms.File, error) {
+	query := `
+		SELECT id, event_id, form_id, user_id, response_id, field_id, name, content_type, size, storage_key, created_at
+		FROM form_files
+		WHERE response_id IS NULL AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*forms.File
+	for rows.Next() {
+		file, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, file)
+	}
+
+	return result, rows.Err()
+}
+
+// MoveToResponse re-attaches the files of one response to another, such as
+// the copy made when a response is migrated to a new form version.
+func (r *FileRepo) MoveToResponse(ctx context.Context, fromID shared.ID, to *forms.Response) error {
+	query := `UPDATE form_files SET response_id = $2, form_id = $3 WHERE response_id = $1`
+	_, err := r.db.conn(ctx).Exec(ctx, query, fromID, to.ID, to.FormID)
+	return err
+}
+
+func (r *FileRepo) Delete(ctx context.Context, id shared.ID) error {
+	_, err := r.db.conn(ctx).Exec(ctx, `DELETE FROM form_files WHERE id = $1`, id)
+	return err
+}
+
+func (r *FileRepo) scanOne(row pgx.Row) (*forms.File, error) {
+	var file forms.File
+	err := row.Scan(
+		&file.ID, &file.EventID, &file.FormID, &file.UserID, &file.ResponseID, &file.FieldID,
+		&file.Name, &file.ContentType, &file.Size, &file.StorageKey, &file.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
 
+	appforms "github.com/Alexander-D-Karpov/kvorum/internal/app/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 	"github.com/jackc/pgx/v5"
@@ -21,7 +23,7 @@ func (r *FormRepo) Create(ctx context.Context, form *forms.Form) error {
 		INSERT INTO forms (id, event_id, version, schema, rules, active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		form.ID, form.EventID, form.Version, form.Schema, form.Rules,
 		form.Active, form.CreatedAt, form.UpdatedAt,
 	)
@@ -36,7 +38,7 @@ func (r *FormRepo) GetByID(ctx context.Context, id shared.ID) (*forms.Form, erro
 	`
 
 	var form forms.Form
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&form.ID, &form.EventID, &form.Version, &form.Schema, &form.Rules,
 		&form.Active, &form.CreatedAt, &form.UpdatedAt,
 	)
@@ -61,7 +63,7 @@ func (r *FormRepo) GetActiveByEvent(ctx context.Context, eventID shared.ID) (*fo
 	`
 
 	var form forms.Form
-	err := r.db.pool.QueryRow(ctx, query, eventID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(
 		&form.ID, &form.EventID, &form.Version, &form.Schema, &form.Rules,
 		&form.Active, &form.CreatedAt, &form.UpdatedAt,
 	)
@@ -82,12 +84,80 @@ func (r *FormRepo) Update(ctx context.Context, form *forms.Form) error {
 		SET schema = $2, rules = $3, active = $4, updated_at = $5
 		WHERE id = $1
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		form.ID, form.Schema, form.Rules, form.Active, form.UpdatedAt,
 	)
 	return err
 }
 
+func (r *FormRepo) GetByVersion(ctx context.Context, eventID shared.ID, version int) (*forms.Form, error) {
+	query := `
+		SELECT id, event_id, version, schema, rules, active, created_at, updated_at
+		FROM forms
+		WHERE event_id = $1 AND version = $2
+	`
+
+	var form forms.Form
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, version).Scan(
+		&form.ID, &form.EventID, &form.Version, &form.Schema, &form.Rules,
+		&form.Active, &form.CreatedAt, &form.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, forms.ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &form, nil
+}
+
+func (r *FormRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*forms.Form, error) {
+	query := `
+		SELECT id, event_id, version, schema, rules, active, created_at, updated_at
+		FROM forms
+		WHERE event_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*forms.Form
+	for rows.Next() {
+		var form forms.Form
+		if err := rows.Scan(
+			&form.ID, &form.EventID, &form.Version, &form.Schema, &form.Rules,
+			&form.Active, &form.CreatedAt, &form.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, &form)
+	}
+
+	return result, rows.Err()
+}
+
+// NextVersion returns the version number for the event's next form.
+func (r *FormRepo) NextVersion(ctx context.Context, eventID shared.ID) (int, error) {
+	query := `SELECT COALESCE(MAX(version), 0) + 1 FROM forms WHERE event_id = $1`
+
+	var version int
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(&version)
+	return version, err
+}
+
+// DeactivateByEvent retires every active form of the event.
+func (r *FormRepo) DeactivateByEvent(ctx context.Context, eventID shared.ID) error {
+	query := `UPDATE forms SET active = false, updated_at = NOW() WHERE event_id = $1 AND active = true`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID)
+	return err
+}
+
 type ResponseRepo struct {
 	db *DB
 }
@@ -98,27 +168,29 @@ func NewResponseRepo(db *DB) *ResponseRepo {
 
 func (r *ResponseRepo) Create(ctx context.Context, response *forms.Response) error {
 	query := `
-		INSERT INTO form_responses (id, form_id, user_id, status, answers, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO form_responses (
+			id, form_id, user_id, status, answers, previous_response_id, stale, revision, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		response.ID, response.FormID, response.UserID, response.Status,
-		response.Answers, response.CreatedAt, response.UpdatedAt,
+		response.Answers, response.PreviousID, response.Stale, response.Revision, response.CreatedAt, response.UpdatedAt,
 	)
 	return err
 }
 
 func (r *ResponseRepo) GetByID(ctx context.Context, id shared.ID) (*forms.Response, error) {
 	query := `
-		SELECT id, form_id, user_id, status, answers, created_at, updated_at
+		SELECT id, form_id, user_id, status, answers, previous_response_id, stale, revision, created_at, updated_at
 		FROM form_responses
 		WHERE id = $1
 	`
 
 	var response forms.Response
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&response.ID, &response.FormID, &response.UserID, &response.Status,
-		&response.Answers, &response.CreatedAt, &response.UpdatedAt,
+		&response.Answers, &response.PreviousID, &response.Stale, &response.Revision,
+		&response.CreatedAt, &response.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -133,15 +205,46 @@ func (r *ResponseRepo) GetByID(ctx context.Context, id shared.ID) (*forms.Respon
 
 func (r *ResponseRepo) GetByFormAndUser(ctx context.Context, formID, userID shared.ID) (*forms.Response, error) {
 	query := `
-		SELECT id, form_id, user_id, status, answers, created_at, updated_at
+		SELECT id, form_id, user_id, status, answers, previous_response_id, stale, revision, created_at, updated_at
 		FROM form_responses
 		WHERE form_id = $1 AND user_id = $2
 	`
 
 	var response forms.Response
-	err := r.db.pool.QueryRow(ctx, query, formID, userID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, formID, userID).Scan(
+		&response.ID, &response.FormID, &response.UserID, &response.Status,
+		&response.Answers, &response.PreviousID, &response.Stale, &response.Revision,
+		&response.CreatedAt, &response.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, forms.ErrResponseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetLatestByEventAndUser returns the user's most recent submitted response
+// to any version of the event's form.
+func (r *ResponseRepo) GetLatestByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*forms.Response, error) {
+	query := `
+		SELECT fr.id, fr.form_id, fr.user_id, fr.status, fr.answers, fr.previous_response_id, fr.stale,
+		       fr.revision, fr.created_at, fr.updated_at
+		FROM form_responses fr
+		JOIN forms f ON f.id = fr.form_id
+		WHERE f.event_id = $1 AND fr.user_id = $2 AND fr.status = $3
+		ORDER BY f.version DESC
+		LIMIT 1
+	`
+
+	var response forms.Response
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID, forms.ResponseStatusSubmitted).Scan(
 		&response.ID, &response.FormID, &response.UserID, &response.Status,
-		&response.Answers, &response.CreatedAt, &response.UpdatedAt,
+		&response.Answers, &response.PreviousID, &response.Stale, &response.Revision,
+		&response.CreatedAt, &response.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -154,27 +257,69 @@ func (r *ResponseRepo) GetByFormAndUser(ctx context.Context, formID, userID shar
 	return &response, nil
 }
 
+// CreateDraft inserts the user's first draft. It fails with
+// forms.ErrDraftConflict when another client created one first.
+func (r *ResponseRepo) CreateDraft(ctx context.Context, response *forms.Response) error {
+	query := `
+		INSERT INTO form_responses (
+			id, form_id, user_id, status, answers, previous_response_id, stale, revision, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (form_id, user_id) DO NOTHING
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query,
+		response.ID, response.FormID, response.UserID, response.Status,
+		response.Answers, response.PreviousID, response.Stale, response.Revision, response.CreatedAt, response.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return forms.ErrDraftConflict
+	}
+	return nil
+}
+
+// UpdateDraft stores the draft only if it is still at revision base, the
+// one the caller read, and has not been submitted in the meantime.
+func (r *ResponseRepo) UpdateDraft(ctx context.Context, response *forms.Response, base int) error {
+	query := `
+		UPDATE form_responses
+		SET answers = $2, revision = $3, updated_at = $4
+		WHERE id = $1 AND revision = $5 AND status = $6
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query,
+		response.ID, response.Answers, response.Revision, response.UpdatedAt, base, forms.ResponseStatusDraft,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return forms.ErrDraftConflict
+	}
+	return nil
+}
+
 func (r *ResponseRepo) Update(ctx context.Context, response *forms.Response) error {
 	query := `
 		UPDATE form_responses
-		SET status = $2, answers = $3, updated_at = $4
+		SET status = $2, answers = $3, stale = $4, previous_response_id = $5, updated_at = $6
 		WHERE id = $1
 	`
-	_, err := r.db.pool.Exec(ctx, query,
-		response.ID, response.Status, response.Answers, response.UpdatedAt,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		response.ID, response.Status, response.Answers, response.Stale, response.PreviousID, response.UpdatedAt,
 	)
 	return err
 }
 
 func (r *ResponseRepo) ListByForm(ctx context.Context, formID shared.ID) ([]*forms.Response, error) {
 	query := `
-		SELECT id, form_id, user_id, status, answers, created_at, updated_at
+		SELECT id, form_id, user_id, status, answers, previous_response_id, stale, revision, created_at, updated_at
 		FROM form_responses
 		WHERE form_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, formID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, formID)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +330,8 @@ func (r *ResponseRepo) ListByForm(ctx context.Context, formID shared.ID) ([]*for
 		var response forms.Response
 		err := rows.Scan(
 			&response.ID, &response.FormID, &response.UserID, &response.Status,
-			&response.Answers, &response.CreatedAt, &response.UpdatedAt,
+			&response.Answers, &response.PreviousID, &response.Stale, &response.Revision,
+			&response.CreatedAt, &response.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -195,3 +341,121 @@ func (r *ResponseRepo) ListByForm(ctx context.Context, formID shared.ID) ([]*for
 
 	return result, rows.Err()
 }
+
+// HasCompletedForm reports whether the user has a current submitted response
+// to the event's active form. Events without an active form count as done.
+func (r *ResponseRepo) HasCompletedForm(ctx context.Context, eventID, userID shared.ID) (bool, error) {
+	query := `
+		SELECT NOT EXISTS (SELECT 1 FROM forms WHERE event_id = $1 AND active)
+		    OR EXISTS (
+		        SELECT 1
+		        FROM form_responses fr
+		        JOIN forms f ON f.id = fr.form_id
+		        WHERE f.event_id = $1 AND f.active AND fr.user_id = $2
+		          AND fr.status = $3 AND NOT fr.stale
+		    )
+	`
+
+	var done bool
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID, forms.ResponseStatusSubmitted).Scan(&done)
+	return done, err
+}
+
+func (r *ResponseRepo) Delete(ctx context.Context, id shared.ID) error {
+	_, err := r.db.conn(ctx).Exec(ctx, `DELETE FROM form_responses WHERE id = $1`, id)
+	return err
+}
+
+// Submissions share one filter: every key of the $2 object must match the
+// answer exactly or be contained in it, and $3 narrows by registration
+// status unless empty.
+const submissionsFrom = `
+		FROM form_responses fr
+		JOIN forms f ON f.id = fr.form_id
+		LEFT JOIN user_profiles up ON up.user_id = fr.user_id
+		LEFT JOIN registrations reg ON reg.event_id = f.event_id AND reg.user_id = fr.user_id
+		WHERE fr.form_id = $1 AND fr.status = 'submitted'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM jsonb_each_text($2::jsonb) flt
+		      WHERE NOT (fr.answers->>flt.key = flt.value OR fr.answers->flt.key ? flt.value)
+		  )
+		  AND ($3 = '' OR reg.status = $3)
+`
+
+func (r *ResponseRepo) ListSubmissions(ctx context.Context, formID shared.ID, filter appforms.SubmissionFilter, limit, offset int) ([]*appforms.Submission, error) {
+	var result []*appforms.Submission
+	err := r.eachSubmission(ctx, formID, filter, limit, offset, func(s *appforms.Submission) error {
+		result = append(result, s)
+		return nil
+	})
+	return result, err
+}
+
+// EachSubmission calls fn for every matching submission, newest first,
+// reading them from the database as it goes.
+func (r *ResponseRepo) EachSubmission(ctx context.Context, formID shared.ID, filter appforms.SubmissionFilter, fn func(*appforms.Submission) error) error {
+	return r.eachSubmission(ctx, formID, filter, 0, 0, fn)
+}
+
+func (r *ResponseRepo) eachSubmission(
+	ctx context.Context,
+	formID shared.ID,
+	filter appforms.SubmissionFilter,
+	limit, offset int,
+	fn func(*appforms.Submission) error,
+) error {
+	answers, err := submissionAnswers(filter)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT fr.id, fr.user_id, COALESCE(up.display_name, ''), fr.answers, fr.stale, fr.updated_at,
+		       COALESCE(reg.status, ''),
+		       (SELECT MIN(c.at) FROM checkins c
+		        WHERE c.event_id = f.event_id AND c.user_id = fr.user_id AND c.zone_id IS NULL AND c.direction = 'in')
+	` + submissionsFrom + `
+		ORDER BY fr.updated_at DESC, fr.id
+		LIMIT NULLIF($4, 0) OFFSET $5
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, formID, answers, string(filter.Status), limit, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s appforms.Submission
+		err := rows.Scan(
+			&s.ID, &s.UserID, &s.UserName, &s.Answers, &s.Stale, &s.SubmittedAt,
+			&s.RegistrationStatus, &s.CheckedInAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *ResponseRepo) CountSubmissions(ctx context.Context, formID shared.ID, filter appforms.SubmissionFilter) (int, error) {
+	answers, err := submissionAnswers(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.db.conn(ctx).QueryRow(ctx, `SELECT COUNT(*)`+submissionsFrom, formID, answers, string(filter.Status)).Scan(&count)
+	return count, err
+}
+
+func submissionAnswers(filter appforms.SubmissionFilter) ([]byte, error) {
+	if filter.Answers == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(filter.Answers)
+}
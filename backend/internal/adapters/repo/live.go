@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+type LiveRepo struct {
+	db *DB
+}
+
+func NewLiveRepo(db *DB) *LiveRepo {
+	return &LiveRepo{db: db}
+}
+
+func (r *LiveRepo) Counts(ctx context.Context, eventID shared.ID) (*live.Counts, error) {
+	query := `
+		WITH regs AS (
+			SELECT
+				COALESCE(SUM(quantity) FILTER (WHERE status IN ('going', 'pending_form')), 0) AS seats,
+				COUNT(*) FILTER (WHERE status = 'going') AS going,
+				COUNT(*) FILTER (WHERE status = 'maybe') AS maybe,
+				COUNT(*) FILTER (WHERE status = 'not_going') AS not_going,
+				COUNT(*) FILTER (WHERE status = 'waitlist') AS waitlist,
+				COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+				COUNT(*) FILTER (WHERE status = 'pending_form') AS pending_form,
+				COUNT(*) FILTER (WHERE status = 'rejected') AS rejected
+			FROM registrations
+			WHERE event_id = $1
+		),
+		door AS (
+			SELECT COUNT(*) FILTER (WHERE direction = 'in') AS inside, COUNT(*) AS attended
+			FROM (
+				SELECT DISTINCT ON (user_id) user_id, direction
+				FROM checkins
+				WHERE event_id = $1 AND zone_id IS NULL
+				ORDER BY user_id, at DESC, id DESC
+			) latest
+		)
+		SELECT e.capacity, regs.seats, regs.going, regs.maybe, regs.not_going, regs.waitlist,
+		       regs.pending, regs.pending_form, regs.rejected, door.inside, door.attended
+		FROM events e, regs, door
+		WHERE e.id = $1
+	`
+
+	var c live.Counts
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(
+		&c.Capacity, &c.Seats, &c.Going, &c.Maybe, &c.NotGoing, &c.Waitlist,
+		&c.Pending, &c.PendingForm, &c.Rejected, &c.Inside, &c.Attended,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, events.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,300 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+type OrgRepo struct {
+	db *DB
+}
+
+func NewOrgRepo(db *DB) *OrgRepo {
+	return &OrgRepo{db: db}
+}
+
+func (r *OrgRepo) Create(ctx context.Context, org *orgs.Organization) error {
+	query := `
+		INSERT INTO organizations (id, name, description, owner_id, invite_token, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		org.ID, org.Name, org.Description, org.OwnerID, org.InviteToken, org.CreatedAt, org.UpdatedAt,
+	)
+	return err
+}
+
+func (r *OrgRepo) Update(ctx context.Context, org *orgs.Organization) error {
+	query := `
+		UPDATE organizations SET
+			name = $2, description = $3, invite_token = NULLIF($4, ''), updated_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, org.ID, org.Name, org.Description, org.InviteToken, org.UpdatedAt)
+	return err
+}
+
+func (r *OrgRepo) GetByID(ctx context.Context, id shared.ID) (*orgs.Organization, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), owner_id, COALESCE(invite_token, ''), created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`
+	return r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+func (r *OrgRepo) GetByInviteToken(ctx context.Context, token string) (*orgs.Organization, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), owner_id, COALESCE(invite_token, ''), created_at, updated_at
+		FROM organizations
+		WHERE invite_token = $1
+	`
+	return r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, token))
+}
+
+func (r *OrgRepo) scanOne(row pgx.Row) (*orgs.Organization, error) {
+	var org orgs.Organization
+	err := row.Scan(
+		&org.ID, &org.Name, &org.Description, &org.OwnerID, &org.InviteToken, &org.CreatedAt, &org.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, orgs.ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+func (r *OrgRepo) ListByUser(ctx context.Context, userID shared.ID) ([]*orgs.Organization, error) {
+	query := `
+		SELECT o.id, o.name, COALESCE(o.description, ''), o.owner_id, COALESCE(o.invite_token, ''), o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*orgs.Organization
+	for rows.Next() {
+		var org orgs.Organization
+		err := rows.Scan(
+			&org.ID, &org.Name, &org.Description, &org.OwnerID, &org.InviteToken, &org.CreatedAt, &org.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &org)
+	}
+
+	return result, rows.Err()
+}
+
+type OrgMemberRepo struct {
+	db *DB
+}
+
+func NewOrgMemberRepo(db *DB) *OrgMemberRepo {
+	return &OrgMemberRepo{db: db}
+}
+
+func (r *OrgMemberRepo) Add(ctx context.Context, member *orgs.Member) error {
+	query := `
+		INSERT INTO organization_members (org_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, member.OrgID, member.UserID, member.Role, member.JoinedAt)
+	return err
+}
+
+// GetRole returns an empty role for users outside the organization.
+func (r *OrgMemberRepo) GetRole(ctx context.Context, orgID, userID shared.ID) (orgs.Role, error) {
+	query := `SELECT role FROM organization_members WHERE org_id = $1 AND user_id = $2`
+	var role orgs.Role
+	err := r.db.conn(ctx).QueryRow(ctx, query, orgID, userID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *OrgMemberRepo) ListByOrg(ctx context.Context, orgID shared.ID) ([]*orgs.Member, error) {
+	query := `
+		SELECT org_id, user_id, role, joined_at
+		FROM organization_members
+		WHERE org_id = $1
+		ORDER BY joined_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*orgs.Member
+	for rows.Next() {
+		var member orgs.Member
+		if err := rows.Scan(&member.OrgID, &member.UserID, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, &member)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *OrgMemberRepo) UpdateRole(ctx context.Context, orgID, userID shared.ID, role orgs.Role) error {
+	query := `UPDATE organization_members SET role = $3 WHERE org_id = $1 AND user_id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, orgID, userID, role)
+	return err
+}
+
+func (r *OrgMemberRepo) Remove(ctx context.Context, orgID, userID shared.ID) error {
+	query := `DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, orgID, userID)
+	return err
+}
+
+// GetFeedToken returns an empty token for members who never asked for one.
+func (r *OrgMemberRepo) GetFeedToken(ctx context.Context, orgID, userID shared.ID) (string, error) {
+	query := `SELECT COALESCE(feed_token, '') FROM organization_members WHERE org_id = $1 AND user_id = $2`
+	var token string
+	err := r.db.conn(ctx).QueryRow(ctx, query, orgID, userID).Scan(&token)
+	if err == pgx.ErrNoRows {
+		return "", orgs.ErrNotMember
+	}
+	return token, err
+}
+
+func (r *OrgMemberRepo) SetFeedToken(ctx context.Context, orgID, userID shared.ID, token string) error {
+	query := `UPDATE organization_members SET feed_token = $3 WHERE org_id = $1 AND user_id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, orgID, userID, token)
+	return err
+}
+
+func (r *OrgMemberRepo) GetByFeedToken(ctx context.Context, orgID shared.ID, token string) (shared.ID, error) {
+	query := `SELECT user_id FROM organization_members WHERE org_id = $1 AND feed_token = $2`
+	var userID shared.ID
+	err := r.db.conn(ctx).QueryRow(ctx, query, orgID, token).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return "", orgs.ErrInvalidFeedToken
+	}
+	return userID, err
+}
+
+func (r *OrgMemberRepo) CountAdmins(ctx context.Context, orgID shared.ID) (int, error) {
+	query := `SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND role = 'admin'`
+	var count int
+	err := r.db.conn(ctx).QueryRow(ctx, query, orgID).Scan(&count)
+	return count, err
+}
+
+type JoinRequestRepo struct {
+	db *DB
+}
+
+func NewJoinRequestRepo(db *DB) *JoinRequestRepo {
+	return &JoinRequestRepo{db: db}
+}
+
+func (r *JoinRequestRepo) Create(ctx context.Context, req *orgs.JoinRequest) error {
+	query := `
+		INSERT INTO organization_join_requests (id, org_id, user_id, message, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, req.ID, req.OrgID, req.UserID, req.Message, req.Status, req.CreatedAt)
+	return err
+}
+
+func (r *JoinRequestRepo) Update(ctx context.Context, req *orgs.JoinRequest) error {
+	query := `
+		UPDATE organization_join_requests
+		SET status = $2, decided_by = $3, decided_at = $4
+		WHERE id = $1
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query, req.ID, req.Status, req.DecidedBy, req.DecidedAt)
+	return err
+}
+
+func (r *JoinRequestRepo) GetByID(ctx context.Context, id shared.ID) (*orgs.JoinRequest, error) {
+	query := `
+		SELECT id, org_id, user_id, COALESCE(message, ''), status, decided_by, decided_at, created_at
+		FROM organization_join_requests
+		WHERE id = $1
+	`
+
+	var req orgs.JoinRequest
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&req.ID, &req.OrgID, &req.UserID, &req.Message, &req.Status, &req.DecidedBy, &req.DecidedAt, &req.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, orgs.ErrJoinRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetPending returns nil, nil when the user has no open request.
+func (r *JoinRequestRepo) GetPending(ctx context.Context, orgID, userID shared.ID) (*orgs.JoinRequest, error) {
+	query := `
+		SELECT id, org_id, user_id, COALESCE(message, ''), status, decided_by, decided_at, created_at
+		FROM organization_join_requests
+		WHERE org_id = $1 AND user_id = $2 AND status = 'pending'
+	`
+
+	var req orgs.JoinRequest
+	err := r.db.conn(ctx).QueryRow(ctx, query, orgID, userID).Scan(
+		&req.ID, &req.OrgID, &req.UserID, &req.Message, &req.Status, &req.DecidedBy, &req.DecidedAt, &req.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func (r *JoinRequestRepo) ListPending(ctx context.Context, orgID shared.ID) ([]*orgs.JoinRequest, error) {
+	query := `
+		SELECT id, org_id, user_id, COALESCE(message, ''), status, decided_by, decided_at, created_at
+		FROM organization_join_requests
+		WHERE org_id = $1 AND status = 'pending'
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*orgs.JoinRequest
+	for rows.Next() {
+		var req orgs.JoinRequest
+		err := rows.Scan(
+			&req.ID, &req.OrgID, &req.UserID, &req.Message, &req.Status, &req.DecidedBy, &req.DecidedAt, &req.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &req)
+	}
+
+	return result, rows.Err()
+}
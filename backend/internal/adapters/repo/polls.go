@@ -21,7 +21,7 @@ func (r *PollRepo) Create(ctx context.Context, poll *polls.Poll) error {
 		INSERT INTO polls (id, event_id, question, options, type, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		poll.ID, poll.EventID, poll.Question, poll.Options, poll.Type, poll.CreatedAt,
 	)
 	return err
@@ -35,7 +35,7 @@ func (r *PollRepo) GetByID(ctx context.Context, id shared.ID) (*polls.Poll, erro
 	`
 
 	var poll polls.Poll
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&poll.ID, &poll.EventID, &poll.Question, &poll.Options, &poll.Type,
 		&poll.CreatedAt, &poll.UpdatedAt,
 	)
@@ -58,7 +58,7 @@ func (r *PollRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*polls
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, eventID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +93,7 @@ func (r *VoteRepo) Create(ctx context.Context, vote *polls.Vote) error {
 		INSERT INTO poll_votes (id, poll_id, user_id, option_key, created_at)
 		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		vote.ID, vote.PollID, vote.UserID, vote.OptionKey, vote.CreatedAt,
 	)
 	return err
@@ -107,7 +107,7 @@ func (r *VoteRepo) GetByPollAndUser(ctx context.Context, pollID, userID shared.I
 	`
 
 	var vote polls.Vote
-	err := r.db.pool.QueryRow(ctx, query, pollID, userID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, pollID, userID).Scan(
 		&vote.ID, &vote.PollID, &vote.UserID, &vote.OptionKey, &vote.CreatedAt,
 	)
 
@@ -129,7 +129,7 @@ func (r *VoteRepo) CountByOption(ctx context.Context, pollID shared.ID) (map[str
 		GROUP BY option_key
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, pollID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, pollID)
 	if err != nil {
 		return nil, err
 	}
@@ -19,25 +19,58 @@ func NewRegistrationRepo(db *DB) *RegistrationRepo {
 
 func (r *RegistrationRepo) Create(ctx context.Context, reg *registrations.Registration) error {
 	query := `
-		INSERT INTO registrations (id, event_id, user_id, status, source, utm, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO registrations (
+			id, event_id, user_id, ticket_type_id, quantity, status, source, utm, hold_until, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
-		reg.ID, reg.EventID, reg.UserID, reg.Status, reg.Source, reg.UTM, reg.CreatedAt, reg.UpdatedAt,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		reg.ID, reg.EventID, reg.UserID, reg.TicketTypeID, reg.Quantity,
+		reg.Status, reg.Source, reg.UTM, reg.HoldUntil, reg.CreatedAt, reg.UpdatedAt,
 	)
 	return err
 }
 
 func (r *RegistrationRepo) GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error) {
 	query := `
-		SELECT id, event_id, user_id, status, source, utm, created_at, updated_at
+		SELECT id, event_id, user_id, ticket_type_id, quantity, status, source, utm,
+		       decided_by, decided_at, COALESCE(decision_reason, ''), hold_until, created_at, updated_at
 		FROM registrations
 		WHERE event_id = $1 AND user_id = $2
 	`
 
 	var reg registrations.Registration
-	err := r.db.pool.QueryRow(ctx, query, eventID, userID).Scan(
-		&reg.ID, &reg.EventID, &reg.UserID, &reg.Status, &reg.Source, &reg.UTM, &reg.CreatedAt, &reg.UpdatedAt,
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID).Scan(
+		&reg.ID, &reg.EventID, &reg.UserID, &reg.TicketTypeID, &reg.Quantity,
+		&reg.Status, &reg.Source, &reg.UTM,
+		&reg.DecidedBy, &reg.DecidedAt, &reg.DecisionReason, &reg.HoldUntil, &reg.CreatedAt, &reg.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, registrations.ErrRegistrationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &reg, nil
+}
+
+// LockByEventAndUser reads the registration and locks it for the rest of
+// the transaction.
+func (r *RegistrationRepo) LockByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error) {
+	query := `
+		SELECT id, event_id, user_id, ticket_type_id, quantity, status, source, utm,
+		       decided_by, decided_at, COALESCE(decision_reason, ''), hold_until, created_at, updated_at
+		FROM registrations
+		WHERE event_id = $1 AND user_id = $2
+		FOR UPDATE
+	`
+
+	var reg registrations.Registration
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, userID).Scan(
+		&reg.ID, &reg.EventID, &reg.UserID, &reg.TicketTypeID, &reg.Quantity,
+		&reg.Status, &reg.Source, &reg.UTM,
+		&reg.DecidedBy, &reg.DecidedAt, &reg.DecisionReason, &reg.HoldUntil, &reg.CreatedAt, &reg.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -53,29 +86,46 @@ func (r *RegistrationRepo) GetByEventAndUser(ctx context.Context, eventID, userI
 func (r *RegistrationRepo) Update(ctx context.Context, reg *registrations.Registration) error {
 	query := `
 		UPDATE registrations
-		SET status = $3, updated_at = $4
+		SET status = $3, decided_by = $4, decided_at = $5, decision_reason = $6, hold_until = $7, updated_at = $8
 		WHERE event_id = $1 AND user_id = $2
 	`
-	_, err := r.db.pool.Exec(ctx, query, reg.EventID, reg.UserID, reg.Status, reg.UpdatedAt)
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		reg.EventID, reg.UserID, reg.Status, reg.DecidedBy, reg.DecidedAt, reg.DecisionReason,
+		reg.HoldUntil, reg.UpdatedAt,
+	)
 	return err
 }
 
 func (r *RegistrationRepo) CountByEvent(ctx context.Context, eventID shared.ID, status registrations.Status) (int, error) {
 	query := `SELECT COUNT(*) FROM registrations WHERE event_id = $1 AND status = $2`
 	var count int
-	err := r.db.pool.QueryRow(ctx, query, eventID, status).Scan(&count)
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, status).Scan(&count)
 	return count, err
 }
 
+// CountSeats sums ticket quantities; a nil ticketTypeID counts every type.
+func (r *RegistrationRepo) CountSeats(ctx context.Context, eventID shared.ID, ticketTypeID *shared.ID, statuses []registrations.Status) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM registrations
+		WHERE event_id = $1 AND status = ANY($2)
+		  AND ($3::uuid IS NULL OR ticket_type_id = $3)
+	`
+	var seats int
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID, statuses, ticketTypeID).Scan(&seats)
+	return seats, err
+}
+
 func (r *RegistrationRepo) ListByEvent(ctx context.Context, eventID shared.ID, statuses []registrations.Status) ([]*registrations.Registration, error) {
 	query := `
-		SELECT id, event_id, user_id, status, source, utm, created_at, updated_at
+		SELECT id, event_id, user_id, ticket_type_id, quantity, status, source, utm,
+		       decided_by, decided_at, COALESCE(decision_reason, ''), hold_until, created_at, updated_at
 		FROM registrations
 		WHERE event_id = $1 AND status = ANY($2)
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, eventID, statuses)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID, statuses)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +135,9 @@ func (r *RegistrationRepo) ListByEvent(ctx context.Context, eventID shared.ID, s
 	for rows.Next() {
 		var reg registrations.Registration
 		err := rows.Scan(
-			&reg.ID, &reg.EventID, &reg.UserID, &reg.Status, &reg.Source, &reg.UTM, &reg.CreatedAt, &reg.UpdatedAt,
+			&reg.ID, &reg.EventID, &reg.UserID, &reg.TicketTypeID, &reg.Quantity,
+			&reg.Status, &reg.Source, &reg.UTM,
+			&reg.DecidedBy, &reg.DecidedAt, &reg.DecisionReason, &reg.HoldUntil, &reg.CreatedAt, &reg.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -98,7 +150,7 @@ func (r *RegistrationRepo) ListByEvent(ctx context.Context, eventID shared.ID, s
 
 func (r *RegistrationRepo) Delete(ctx context.Context, eventID, userID shared.ID) error {
 	query := `DELETE FROM registrations WHERE event_id = $1 AND user_id = $2`
-	_, err := r.db.pool.Exec(ctx, query, eventID, userID)
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID)
 	return err
 }
 
@@ -112,16 +164,18 @@ func NewWaitlistRepo(db *DB) *WaitlistRepo {
 
 func (r *WaitlistRepo) Create(ctx context.Context, entry *registrations.Waitlist) error {
 	query := `
-		INSERT INTO waitlist (id, event_id, user_id, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO waitlist (id, event_id, user_id, ticket_type_id, quantity, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.db.pool.Exec(ctx, query, entry.ID, entry.EventID, entry.UserID, entry.CreatedAt)
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		entry.ID, entry.EventID, entry.UserID, entry.TicketTypeID, entry.Quantity, entry.CreatedAt,
+	)
 	return err
 }
 
 func (r *WaitlistRepo) GetNextByEvent(ctx context.Context, eventID shared.ID) (*registrations.Waitlist, error) {
 	query := `
-		SELECT id, event_id, user_id, created_at
+		SELECT id, event_id, user_id, ticket_type_id, quantity, created_at
 		FROM waitlist
 		WHERE event_id = $1
 		ORDER BY created_at ASC
@@ -129,8 +183,8 @@ func (r *WaitlistRepo) GetNextByEvent(ctx context.Context, eventID shared.ID) (*
 	`
 
 	var entry registrations.Waitlist
-	err := r.db.pool.QueryRow(ctx, query, eventID).Scan(
-		&entry.ID, &entry.EventID, &entry.UserID, &entry.CreatedAt,
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(
+		&entry.ID, &entry.EventID, &entry.UserID, &entry.TicketTypeID, &entry.Quantity, &entry.CreatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -143,41 +197,71 @@ func (r *WaitlistRepo) GetNextByEvent(ctx context.Context, eventID shared.ID) (*
 	return &entry, nil
 }
 
+func (r *WaitlistRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*registrations.Waitlist, error) {
+	query := `
+		SELECT id, event_id, user_id, ticket_type_id, quantity, created_at
+		FROM waitlist
+		WHERE event_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*registrations.Waitlist
+	for rows.Next() {
+		var entry registrations.Waitlist
+		err := rows.Scan(
+			&entry.ID, &entry.EventID, &entry.UserID, &entry.TicketTypeID, &entry.Quantity, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &entry)
+	}
+
+	return result, rows.Err()
+}
+
 func (r *WaitlistRepo) Delete(ctx context.Context, id shared.ID) error {
 	query := `DELETE FROM waitlist WHERE id = $1`
-	_, err := r.db.pool.Exec(ctx, query, id)
+	_, err := r.db.conn(ctx).Exec(ctx, query, id)
+	return err
+}
+
+func (r *WaitlistRepo) DeleteByEventAndUser(ctx context.Context, eventID, userID shared.ID) error {
+	query := `DELETE FROM waitlist WHERE event_id = $1 AND user_id = $2`
+	_, err := r.db.conn(ctx).Exec(ctx, query, eventID, userID)
 	return err
 }
 
 func (r *WaitlistRepo) CountByEvent(ctx context.Context, eventID shared.ID) (int, error) {
 	query := `SELECT COUNT(*) FROM waitlist WHERE event_id = $1`
 	var count int
-	err := r.db.pool.QueryRow(ctx, query, eventID).Scan(&count)
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(&count)
 	return count, err
 }
 
-func (r *RegistrationRepo) GetByEventWithChatIDs(ctx context.Context, eventID shared.ID) ([]struct {
-	UserID shared.ID
-	ChatID int64
-}, error) {
+// GetByEventWithChatIDs returns the going and maybe attendees who can be
+// reached through MAX.
+func (r *RegistrationRepo) GetByEventWithChatIDs(ctx context.Context, eventID shared.ID) ([]registrations.Recipient, error) {
 	query := `
 		SELECT r.user_id, ui.provider_user_id
 		FROM registrations r
 		JOIN user_identities ui ON ui.user_id = r.user_id AND ui.provider = 'max'
-		WHERE r.event_id = $1 AND r.status = 'going'
+		WHERE r.event_id = $1 AND r.status IN ('going', 'maybe')
 	`
 
-	rows, err := r.db.pool.Query(ctx, query, eventID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []struct {
-		UserID shared.ID
-		ChatID int64
-	}
-
+	var result []registrations.Recipient
 	for rows.Next() {
 		var userID shared.ID
 		var providerUserID string
@@ -189,10 +273,7 @@ func (r *RegistrationRepo) GetByEventWithChatIDs(ctx context.Context, eventID sh
 		var chatID int64
 		fmt.Sscanf(providerUserID, "%d", &chatID)
 
-		result = append(result, struct {
-			UserID shared.ID
-			ChatID int64
-		}{
+		result = append(result, registrations.Recipient{
 			UserID: userID,
 			ChatID: chatID,
 		})
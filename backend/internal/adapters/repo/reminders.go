@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReminderRepo struct {
+	db *DB
+}
+
+func NewReminderRepo(db *DB) *ReminderRepo {
+	return &ReminderRepo{db: db}
+}
+
+func (r *ReminderRepo) Create(ctx context.Context, reminder *events.Reminder) error {
+	query := `
+		INSERT INTO reminders (id, event_id, at, type, status, offset_seconds, task_id, created_at)
+		VALUES ($1, $2, $3, 'reminder', $4, $5, NULLIF($6, ''), $7)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		reminder.ID, reminder.EventID, reminder.At, reminder.Status,
+		int64(reminder.Offset/time.Second), reminder.TaskID, reminder.CreatedAt,
+	)
+	return err
+}
+
+func (r *ReminderRepo) UpdateStatus(ctx context.Context, id shared.ID, status events.ReminderStatus) error {
+	query := `UPDATE reminders SET status = $2 WHERE id = $1`
+	_, err := r.db.conn(ctx).Exec(ctx, query, id, status)
+	return err
+}
+
+func (r *ReminderRepo) GetByID(ctx context.Context, id shared.ID) (*events.Reminder, error) {
+	query := `
+		SELECT id, event_id, at, offset_seconds, COALESCE(task_id, ''), status, created_at
+		FROM reminders
+		WHERE id = $1
+	`
+
+	var reminder events.Reminder
+	var offsetSeconds int64
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&reminder.ID, &reminder.EventID, &reminder.At, &offsetSeconds,
+		&reminder.TaskID, &reminder.Status, &reminder.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, events.ErrReminderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	reminder.Offset = time.Duration(offsetSeconds) * time.Second
+
+	return &reminder, nil
+}
+
+func (r *ReminderRepo) ListPending(ctx context.Context, eventID shared.ID) ([]*events.Reminder, error) {
+	query := `
+		SELECT id, event_id, at, offset_seconds, COALESCE(task_id, ''), status, created_at
+		FROM reminders
+		WHERE event_id = $1 AND user_id IS NULL AND status = 'pending'
+		ORDER BY at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*events.Reminder
+	for rows.Next() {
+		var reminder events.Reminder
+		var offsetSeconds int64
+		err := rows.Scan(
+			&reminder.ID, &reminder.EventID, &reminder.At, &offsetSeconds,
+			&reminder.TaskID, &reminder.Status, &reminder.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reminder.Offset = time.Duration(offsetSeconds) * time.Second
+		result = append(result, &reminder)
+	}
+
+	return result, rows.Err()
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
@@ -21,13 +22,36 @@ func (r *SeriesRepo) Create(ctx context.Context, series *events.Series) error {
 		INSERT INTO event_series (id, event_id, rrule, exdates, until, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		series.ID, series.EventID, series.RRule, series.ExDates,
 		series.Until, series.CreatedAt, series.UpdatedAt,
 	)
 	return err
 }
 
+func (r *SeriesRepo) GetByID(ctx context.Context, id shared.ID) (*events.Series, error) {
+	query := `
+		SELECT id, event_id, rrule, exdates, until, created_at, updated_at
+		FROM event_series
+		WHERE id = $1
+	`
+
+	var series events.Series
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&series.ID, &series.EventID, &series.RRule, &series.ExDates,
+		&series.Until, &series.CreatedAt, &series.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
 func (r *SeriesRepo) GetByEventID(ctx context.Context, eventID shared.ID) (*events.Series, error) {
 	query := `
 		SELECT id, event_id, rrule, exdates, until, created_at, updated_at
@@ -36,7 +60,7 @@ func (r *SeriesRepo) GetByEventID(ctx context.Context, eventID shared.ID) (*even
 	`
 
 	var series events.Series
-	err := r.db.pool.QueryRow(ctx, query, eventID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, eventID).Scan(
 		&series.ID, &series.EventID, &series.RRule, &series.ExDates,
 		&series.Until, &series.CreatedAt, &series.UpdatedAt,
 	)
@@ -51,13 +75,58 @@ func (r *SeriesRepo) GetByEventID(ctx context.Context, eventID shared.ID) (*even
 	return &series, nil
 }
 
+func (r *SeriesRepo) Lock(ctx context.Context, id shared.ID) (*events.Series, error) {
+	query := `
+		SELECT id, event_id, rrule, exdates, until, created_at, updated_at
+		FROM event_series
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var series events.Series
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&series.ID, &series.EventID, &series.RRule, &series.ExDates,
+		&series.Until, &series.CreatedAt, &series.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+func (r *SeriesRepo) ListOpen(ctx context.Context, at time.Time) ([]shared.ID, error) {
+	query := `SELECT id FROM event_series WHERE until IS NULL OR until > $1`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []shared.ID
+	for rows.Next() {
+		var id shared.ID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+
+	return result, rows.Err()
+}
+
 func (r *SeriesRepo) Update(ctx context.Context, series *events.Series) error {
 	query := `
 		UPDATE event_series
 		SET rrule = $2, exdates = $3, until = $4, updated_at = $5
 		WHERE id = $1
 	`
-	_, err := r.db.pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		series.ID, series.RRule, series.ExDates, series.Until, series.UpdatedAt,
 	)
 	return err
@@ -65,6 +134,6 @@ func (r *SeriesRepo) Update(ctx context.Context, series *events.Series) error {
 
 func (r *SeriesRepo) Delete(ctx context.Context, id shared.ID) error {
 	query := `DELETE FROM event_series WHERE id = $1`
-	_, err := r.db.pool.Exec(ctx, query, id)
+	_, err := r.db.conn(ctx).Exec(ctx, query, id)
 	return err
 }
@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/tickets"
+	"github.com/jackc/pgx/v5"
+)
+
+type TicketTypeRepo struct {
+	db *DB
+}
+
+func NewTicketTypeRepo(db *DB) *TicketTypeRepo {
+	return &TicketTypeRepo{db: db}
+}
+
+func (r *TicketTypeRepo) Create(ctx context.Context, t *tickets.TicketType) error {
+	query := `
+		INSERT INTO ticket_types (
+			id, event_id, name, description, price, currency, price_tiers,
+			capacity, sales_start, sales_end, hidden, unlock_code,
+			per_user_limit, sort_order, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		t.ID, t.EventID, t.Name, t.Description, t.Price, t.Currency, t.PriceTiers,
+		t.Capacity, t.SalesStart, t.SalesEnd, t.Hidden, t.UnlockCode,
+		t.PerUserLimit, t.SortOrder, t.CreatedAt, t.UpdatedAt,
+	)
+	return err
+}
+
+func (r *TicketTypeRepo) Update(ctx context.Context, t *tickets.TicketType) error {
+	query := `
+		UPDATE ticket_types SET
+			name = $2, description = $3, price = $4, currency = $5, price_tiers = $6,
+			capacity = $7, sales_start = $8, sales_end = $9, hidden = $10,
+			unlock_code = $11, per_user_limit = $12, sort_order = $13, updated_at = $14
+		WHERE id = $1
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		t.ID, t.Name, t.Description, t.Price, t.Currency, t.PriceTiers,
+		t.Capacity, t.SalesStart, t.SalesEnd, t.Hidden,
+		t.UnlockCode, t.PerUserLimit, t.SortOrder, t.UpdatedAt,
+	)
+	return err
+}
+
+func (r *TicketTypeRepo) GetByID(ctx context.Context, id shared.ID) (*tickets.TicketType, error) {
+	query := `
+		SELECT id, event_id, name, COALESCE(description, ''), price, currency, price_tiers,
+		       capacity, sales_start, sales_end, hidden, COALESCE(unlock_code, ''),
+		       per_user_limit, sort_order, created_at, updated_at
+		FROM ticket_types
+		WHERE id = $1
+	`
+
+	var t tickets.TicketType
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&t.ID, &t.EventID, &t.Name, &t.Description, &t.Price, &t.Currency, &t.PriceTiers,
+		&t.Capacity, &t.SalesStart, &t.SalesEnd, &t.Hidden, &t.UnlockCode,
+		&t.PerUserLimit, &t.SortOrder, &t.CreatedAt, &t.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, tickets.ErrTicketTypeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *TicketTypeRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*tickets.TicketType, error) {
+	query := `
+		SELECT id, event_id, name, COALESCE(description, ''), price, currency, price_tiers,
+		       capacity, sales_start, sales_end, hidden, COALESCE(unlock_code, ''),
+		       per_user_limit, sort_order, created_at, updated_at
+		FROM ticket_types
+		WHERE event_id = $1
+		ORDER BY sort_order ASC, created_at ASC
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*tickets.TicketType
+	for rows.Next() {
+		var t tickets.TicketType
+		err := rows.Scan(
+			&t.ID, &t.EventID, &t.Name, &t.Description, &t.Price, &t.Currency, &t.PriceTiers,
+			&t.Capacity, &t.SalesStart, &t.SalesEnd, &t.Hidden, &t.UnlockCode,
+			&t.PerUserLimit, &t.SortOrder, &t.CreatedAt, &t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &t)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TicketTypeRepo) Delete(ctx context.Context, id shared.ID) error {
+	query := `DELETE FROM ticket_types WHERE id = $1`
+	_, err := r.db.conn(ctx).Exec(ctx, query, id)
+	return err
+}
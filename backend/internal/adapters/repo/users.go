@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/app/identity"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
@@ -21,7 +22,7 @@ func (r *UserRepo) Create(ctx context.Context, user *identity.User) error {
 		INSERT INTO users (id, created_at, updated_at)
 		VALUES ($1, $2, $3)
 	`
-	_, err := r.db.pool.Exec(ctx, query, user.ID, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.conn(ctx).Exec(ctx, query, user.ID, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -30,7 +31,7 @@ func (r *UserRepo) Create(ctx context.Context, user *identity.User) error {
 		INSERT INTO user_identities (id, user_id, provider, provider_user_id)
 		VALUES (gen_random_uuid(), $1, $2, $3)
 	`
-	_, err = r.db.pool.Exec(ctx, identityQuery, user.ID, user.Provider, user.ProviderID)
+	_, err = r.db.conn(ctx).Exec(ctx, identityQuery, user.ID, user.Provider, user.ProviderID)
 	if err != nil {
 		return err
 	}
@@ -39,7 +40,7 @@ func (r *UserRepo) Create(ctx context.Context, user *identity.User) error {
 		INSERT INTO user_profiles (user_id, display_name, email, phone, tz, locale)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err = r.db.pool.Exec(ctx, profileQuery, user.ID, user.DisplayName, user.Email, user.Phone, user.Timezone, user.Locale)
+	_, err = r.db.conn(ctx).Exec(ctx, profileQuery, user.ID, user.DisplayName, user.Email, user.Phone, user.Timezone, user.Locale)
 	return err
 }
 
@@ -47,7 +48,8 @@ func (r *UserRepo) GetByID(ctx context.Context, id shared.ID) (*identity.User, e
 	query := `
 		SELECT u.id, u.created_at, u.updated_at, 
 		       ui.provider, ui.provider_user_id,
-		       up.display_name, up.email, up.phone, up.tz, up.locale
+		       up.display_name, up.email, up.phone, up.tz, up.locale,
+		       COALESCE(up.saved_fields, '{}'::jsonb)
 		FROM users u
 		JOIN user_identities ui ON ui.user_id = u.id
 		LEFT JOIN user_profiles up ON up.user_id = u.id
@@ -56,10 +58,10 @@ func (r *UserRepo) GetByID(ctx context.Context, id shared.ID) (*identity.User, e
 	`
 
 	var user identity.User
-	err := r.db.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.CreatedAt, &user.UpdatedAt,
 		&user.Provider, &user.ProviderID,
-		&user.DisplayName, &user.Email, &user.Phone, &user.Timezone, &user.Locale,
+		&user.DisplayName, &user.Email, &user.Phone, &user.Timezone, &user.Locale, &user.SavedFields,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -76,7 +78,8 @@ func (r *UserRepo) GetByProvider(ctx context.Context, provider, providerID strin
 	query := `
 		SELECT u.id, u.created_at, u.updated_at,
 		       ui.provider, ui.provider_user_id,
-		       up.display_name, up.email, up.phone, up.tz, up.locale
+		       up.display_name, up.email, up.phone, up.tz, up.locale,
+		       COALESCE(up.saved_fields, '{}'::jsonb)
 		FROM users u
 		JOIN user_identities ui ON ui.user_id = u.id
 		LEFT JOIN user_profiles up ON up.user_id = u.id
@@ -85,10 +88,10 @@ func (r *UserRepo) GetByProvider(ctx context.Context, provider, providerID strin
 	`
 
 	var user identity.User
-	err := r.db.pool.QueryRow(ctx, query, provider, providerID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, provider, providerID).Scan(
 		&user.ID, &user.CreatedAt, &user.UpdatedAt,
 		&user.Provider, &user.ProviderID,
-		&user.DisplayName, &user.Email, &user.Phone, &user.Timezone, &user.Locale,
+		&user.DisplayName, &user.Email, &user.Phone, &user.Timezone, &user.Locale, &user.SavedFields,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -104,9 +107,38 @@ func (r *UserRepo) GetByProvider(ctx context.Context, provider, providerID strin
 func (r *UserRepo) Update(ctx context.Context, user *identity.User) error {
 	query := `
 		UPDATE user_profiles
-		SET display_name = $2, email = $3, phone = $4, tz = $5, locale = $6
+		SET display_name = $2, email = $3, phone = $4, tz = $5, locale = $6, saved_fields = $7
 		WHERE user_id = $1
 	`
-	_, err := r.db.pool.Exec(ctx, query, user.ID, user.DisplayName, user.Email, user.Phone, user.Timezone, user.Locale)
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		user.ID, user.DisplayName, user.Email, user.Phone, user.Timezone, user.Locale, user.SavedFields,
+	)
 	return err
 }
+
+// GetMaxChatID returns the MAX dialog id of the user, or 0 if the user never
+// signed in through MAX. For dialogs with the bot it equals the MAX user id.
+func (r *UserRepo) GetMaxChatID(ctx context.Context, userID shared.ID) (int64, error) {
+	query := `
+		SELECT provider_user_id
+		FROM user_identities
+		WHERE user_id = $1 AND provider = 'max'
+		LIMIT 1
+	`
+
+	var providerUserID string
+	err := r.db.conn(ctx).QueryRow(ctx, query, userID).Scan(&providerUserID)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	chatID, err := strconv.ParseInt(providerUserID, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+
+	return chatID, nil
+}
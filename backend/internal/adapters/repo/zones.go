@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type ZoneRepo struct {
+	db *DB
+}
+
+func NewZoneRepo(db *DB) *ZoneRepo {
+	return &ZoneRepo{db: db}
+}
+
+func (r *ZoneRepo) Create(ctx context.Context, z *checkin.Zone) error {
+	query := `
+		INSERT INTO checkin_zones (id, event_id, name, ticket_type_ids, roles, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, COALESCE($4, '{}'::uuid[]), COALESCE($5, '{}'::text[]), $6, $7, $8)
+	`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		z.ID, z.EventID, z.Name, z.TicketTypeIDs, z.Roles, z.SortOrder, z.CreatedAt, z.UpdatedAt,
+	)
+	return err
+}
+
+func (r *ZoneRepo) Update(ctx context.Context, z *checkin.Zone) error {
+	query := `
+		UPDATE checkin_zones
+		SET name = $2, ticket_type_ids = COALESCE($3, '{}'::uuid[]), roles = COALESCE($4, '{}'::text[]),
+		    sort_order = $5, updated_at = $6
+		WHERE id = $1
+	`
+	tag, err := r.db.conn(ctx).Exec(ctx, query, z.ID, z.Name, z.TicketTypeIDs, z.Roles, z.SortOrder, z.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return checkin.ErrZoneNotFound
+	}
+	return nil
+}
+
+// GetByID takes zone IDs straight from scanners, so a malformed one is just
+// a zone that does not exist.
+func (r *ZoneRepo) GetByID(ctx context.Context, id shared.ID) (*checkin.Zone, error) {
+	if _, err := uuid.Parse(string(id)); err != nil {
+		return nil, checkin.ErrZoneNotFound
+	}
+
+	query := `
+		SELECT ` + zoneColumns + `
+		FROM checkin_zones
+		WHERE id = $1
+	`
+	z, err := r.scanOne(r.db.conn(ctx).QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, checkin.ErrZoneNotFound
+	}
+	return z, err
+}
+
+func (r *ZoneRepo) ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Zone, error) {
+	query := `
+		SELECT ` + zoneColumns + `
+		FROM checkin_zones
+		WHERE event_id = $1
+		ORDER BY sort_order, created_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*checkin.Zone{}
+	for rows.Next() {
+		z, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, z)
+	}
+
+	return result, rows.Err()
+}
+
+// Delete refuses with ErrZoneInUse once passes were recorded through the
+// zone; its attendance would be lost.
+func (r *ZoneRepo) Delete(ctx context.Context, id shared.ID) error {
+	tag, err := r.db.conn(ctx).Exec(ctx, `DELETE FROM checkin_zones WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return checkin.ErrZoneInUse
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return checkin.ErrZoneNotFound
+	}
+	return nil
+}
+
+const zoneColumns = `id, event_id, name, ticket_type_ids, roles, sort_order, created_at, updated_at`
+
+func (r *ZoneRepo) scanOne(row pgx.Row) (*checkin.Zone, error) {
+	var z checkin.Zone
+	err := row.Scan(&z.ID, &z.EventID, &z.Name, &z.TicketTypeIDs, &z.Roles, &z.SortOrder, &z.CreatedAt, &z.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &z, nil
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"strings"
 	"time"
 )
 
@@ -13,26 +15,21 @@ type Storage interface {
 	GetPresignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
 }
 
-type LocalStorage struct {
-	basePath string
-}
-
-func NewLocalStorage(basePath string) *LocalStorage {
-	return &LocalStorage{basePath: basePath}
-}
-
-func (s *LocalStorage) Upload(ctx context.Context, key string, r io.Reader, contentType string) error {
-	return nil
-}
-
-func (s *LocalStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
-	return nil, nil
-}
+var (
+	ErrNotFound         = errors.New("object not found")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
 
-func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+// checkKey rejects keys that could escape the storage root or bucket.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
 	return nil
 }
-
-func (s *LocalStorage) GetPresignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
-	return "", nil
-}
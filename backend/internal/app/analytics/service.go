@@ -11,16 +11,42 @@ import (
 )
 
 type EventAnalytics struct {
-	EventID            shared.ID        `json:"event_id"`
-	PeriodFrom         time.Time        `json:"period_from"`
-	PeriodTo           time.Time        `json:"period_to"`
-	TotalRegistrations int              `json:"total_registrations"`
-	Going              int              `json:"going"`
-	NotGoing           int              `json:"not_going"`
-	Maybe              int              `json:"maybe"`
-	Waitlist           int              `json:"waitlist"`
-	CheckedIn          int              `json:"checked_in"`
-	BySource           map[string]int64 `json:"by_source"`
+	EventID            shared.ID         `json:"event_id"`
+	PeriodFrom         time.Time         `json:"period_from"`
+	PeriodTo           time.Time         `json:"period_to"`
+	TotalRegistrations int               `json:"total_registrations"`
+	Going              int               `json:"going"`
+	NotGoing           int               `json:"not_going"`
+	Maybe              int               `json:"maybe"`
+	Waitlist           int               `json:"waitlist"`
+	Pending            int               `json:"pending"`
+	Approved           int               `json:"approved"`
+	Rejected           int               `json:"rejected"`
+	CheckedIn          int               `json:"checked_in"`
+	BySource           map[string]int64  `json:"by_source"`
+	ByTicketType       []TicketTypeStats `json:"by_ticket_type"`
+	ByZone             []ZoneStats       `json:"by_zone"`
+}
+
+type TicketTypeStats struct {
+	TicketTypeID shared.ID `json:"ticket_type_id"`
+	Name         string    `json:"name"`
+	Capacity     int       `json:"capacity"`
+	Going        int       `json:"going"`
+	Waitlist     int       `json:"waitlist"`
+}
+
+// ZoneStats is attendance behind one zone's door. A stay runs from an
+// entry to the attendee's next pass there; one still open runs until now
+// or the end of the event. AvgDwellSeconds is per attendee, not per stay.
+type ZoneStats struct {
+	ZoneID            shared.ID `json:"zone_id"`
+	Name              string    `json:"name"`
+	Attended          int       `json:"attended"`
+	Entries           int       `json:"entries"`
+	Inside            int       `json:"inside"`
+	TotalDwellSeconds int64     `json:"total_dwell_seconds"`
+	AvgDwellSeconds   int64     `json:"avg_dwell_seconds"`
 }
 
 type AnalyticsRepo interface {
@@ -63,6 +89,9 @@ func (s *Service) ExportEventAnalyticsCSV(ctx context.Context, eventID shared.ID
 		"not_going",
 		"maybe",
 		"waitlist",
+		"pending",
+		"approved",
+		"rejected",
 		"checked_in",
 	})
 
@@ -75,6 +104,9 @@ func (s *Service) ExportEventAnalyticsCSV(ctx context.Context, eventID shared.ID
 		strconv.Itoa(analytics.NotGoing),
 		strconv.Itoa(analytics.Maybe),
 		strconv.Itoa(analytics.Waitlist),
+		strconv.Itoa(analytics.Pending),
+		strconv.Itoa(analytics.Approved),
+		strconv.Itoa(analytics.Rejected),
 		strconv.Itoa(analytics.CheckedIn),
 	})
 
@@ -85,6 +117,35 @@ func (s *Service) ExportEventAnalyticsCSV(ctx context.Context, eventID shared.ID
 		writer.Write([]string{source, strconv.FormatInt(count, 10)})
 	}
 
+	if len(analytics.ByTicketType) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Ticket Type", "Capacity", "Going", "Waitlist"})
+
+		for _, t := range analytics.ByTicketType {
+			writer.Write([]string{
+				t.Name,
+				strconv.Itoa(t.Capacity),
+				strconv.Itoa(t.Going),
+				strconv.Itoa(t.Waitlist),
+			})
+		}
+	}
+
+	if len(analytics.ByZone) > 0 {
+		writer.Write([]string{})
+		writer.Write([]string{"Zone", "Attended", "Entries", "Inside", "Avg Dwell Seconds"})
+
+		for _, z := range analytics.ByZone {
+			writer.Write([]string{
+				z.Name,
+				strconv.Itoa(z.Attended),
+				strconv.Itoa(z.Entries),
+				strconv.Itoa(z.Inside),
+				strconv.FormatInt(z.AvgDwellSeconds, 10),
+			})
+		}
+	}
+
 	writer.Flush()
 	return buf.Bytes(), writer.Error()
 }
@@ -24,6 +24,7 @@ type Event struct {
 type EventRepo interface {
 	GetByID(ctx context.Context, id shared.ID) (*Event, error)
 	ListByUser(ctx context.Context, userID shared.ID) ([]*Event, error)
+	ListByIDs(ctx context.Context, ids []shared.ID) ([]*Event, error)
 }
 
 type Service struct {
@@ -52,6 +53,17 @@ func (s *Service) GenerateUserICS(ctx context.Context, userID shared.ID) ([]byte
 	return generateICS(events), nil
 }
 
+// GenerateEventsICS builds one feed from the given events. Callers are
+// responsible for filtering out events the reader may not see.
+func (s *Service) GenerateEventsICS(ctx context.Context, eventIDs []shared.ID) ([]byte, error) {
+	events, err := s.eventRepo.ListByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateICS(events), nil
+}
+
 func (s *Service) GetGoogleCalendarLink(ctx context.Context, eventID shared.ID) (string, error) {
 	event, err := s.eventRepo.GetByID(ctx, eventID)
 	if err != nil {
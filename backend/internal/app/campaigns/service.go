@@ -3,85 +3,185 @@ package campaigns
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/notifications"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
 )
 
+// claimLease is how long a delivery may hold a campaign in sending before
+// another worker may take it over. It outlasts the worker's task timeout,
+// so a live delivery is never taken over.
+const claimLease = time.Hour
+
 type Campaign struct {
 	ID         shared.ID
 	EventID    shared.ID
 	Name       string
-	Segment    string
+	Segment    Segment
 	Channel    string
 	Content    json.RawMessage
 	ScheduleAt *time.Time
-	Status     string
+	Status     Status
 	shared.Timestamp
 }
 
-type Delivery struct {
-	ID         shared.ID
-	CampaignID *shared.ID
-	Channel    string
-	TargetUser shared.ID
-	MessageID  string
-	Status     string
-	Error      string
-	shared.Timestamp
+// Segment names the part of an event's audience a campaign goes to.
+type Segment string
+
+const (
+	SegmentGoing           Segment = "going"
+	SegmentMaybe           Segment = "maybe"
+	SegmentWaitlist        Segment = "waitlist"
+	SegmentCheckedIn       Segment = "checked_in"
+	SegmentNotCheckedIn    Segment = "not_checked_in"
+	SegmentFormRespondents Segment = "form_respondents"
+)
+
+func (s Segment) Valid() bool {
+	switch s {
+	case SegmentGoing, SegmentMaybe, SegmentWaitlist, SegmentCheckedIn, SegmentNotCheckedIn, SegmentFormRespondents:
+		return true
+	}
+	return false
 }
 
+// Status moves pending → sending → sent or failed. A campaign fails only
+// when it reached nobody although it had recipients.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSending Status = "sending"
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed"
+)
+
+var (
+	ErrCampaignNotFound   = errors.New("campaign not found")
+	ErrInvalidSegment     = errors.New("unknown campaign segment")
+	ErrUnsupportedChannel = errors.New("unsupported campaign channel")
+	ErrEmptyMessage       = errors.New("campaign message is required")
+	// ErrTransientFailures means some recipients could not be reached for
+	// now; delivering again retries just them.
+	ErrTransientFailures = errors.New("transient send failures")
+	errNoChat            = errors.New("user has no MAX chat")
+)
+
 type CampaignRepo interface {
 	Create(ctx context.Context, campaign *Campaign) error
 	GetByID(ctx context.Context, id shared.ID) (*Campaign, error)
 	ListByEvent(ctx context.Context, eventID shared.ID) ([]*Campaign, error)
 	Update(ctx context.Context, campaign *Campaign) error
+	// Claim moves a pending campaign, or one whose sending claim is older
+	// than staleBefore, to sending and reports whether it did.
+	Claim(ctx context.Context, id shared.ID, staleBefore time.Time) (bool, error)
 }
 
 type DeliveryRepo interface {
-	Create(ctx context.Context, delivery *Delivery) error
-	ListByCampaign(ctx context.Context, campaignID shared.ID) ([]*Delivery, error)
+	RecordCampaign(ctx context.Context, d *notifications.Delivery) error
+	ListCampaignRecipients(ctx context.Context, campaignID shared.ID) ([]shared.ID, error)
+	ListByCampaign(ctx context.Context, campaignID shared.ID) ([]*notifications.Delivery, error)
+}
+
+type AudienceResolver interface {
+	ResolveAudience(ctx context.Context, eventID shared.ID, segment Segment) ([]registrations.Recipient, error)
 }
 
 type BotSender interface {
-	SendMessage(ctx context.Context, userID shared.ID, content json.RawMessage) (string, error)
+	SendText(ctx context.Context, chatID int64, text string) (string, error)
+	Transient(err error) bool
+}
+
+type Scheduler interface {
+	ScheduleCampaign(ctx context.Context, campaignID string, at time.Time) error
+}
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
 }
 
 type Service struct {
+	uow          UnitOfWork
 	campaignRepo CampaignRepo
 	deliveryRepo DeliveryRepo
+	audience     AudienceResolver
 	botSender    BotSender
+	scheduler    Scheduler
 }
 
-func NewService(campaignRepo CampaignRepo, deliveryRepo DeliveryRepo, botSender BotSender) *Service {
+func NewService(
+	uow UnitOfWork,
+	campaignRepo CampaignRepo,
+	deliveryRepo DeliveryRepo,
+	audience AudienceResolver,
+	botSender BotSender,
+	scheduler Scheduler,
+) *Service {
 	return &Service{
+		uow:          uow,
 		campaignRepo: campaignRepo,
 		deliveryRepo: deliveryRepo,
+		audience:     audience,
 		botSender:    botSender,
+		scheduler:    scheduler,
 	}
 }
 
+// CreateCampaign stores the campaign and queues it for scheduleAt, or for
+// immediate delivery when no future time is given. The row is only kept if
+// the task was queued.
 func (s *Service) CreateCampaign(
 	ctx context.Context,
 	eventID shared.ID,
 	name, segment, channel, message string,
 	scheduleAt *time.Time,
 ) (*Campaign, error) {
+	if !Segment(segment).Valid() {
+		return nil, ErrInvalidSegment
+	}
+	if channel == "" {
+		channel = notifications.ChannelMax
+	}
+	if channel != notifications.ChannelMax {
+		return nil, ErrUnsupportedChannel
+	}
+	if strings.TrimSpace(message) == "" {
+		return nil, ErrEmptyMessage
+	}
+
 	content, _ := json.Marshal(map[string]string{"message": message})
 
 	campaign := &Campaign{
 		ID:         shared.NewID(),
 		EventID:    eventID,
 		Name:       name,
-		Segment:    segment,
+		Segment:    Segment(segment),
 		Channel:    channel,
 		Content:    content,
 		ScheduleAt: scheduleAt,
-		Status:     "pending",
+		Status:     StatusPending,
 		Timestamp:  shared.NewTimestamp(),
 	}
 
-	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+	at := time.Now()
+	if scheduleAt != nil && scheduleAt.After(at) {
+		at = *scheduleAt
+	}
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+			return err
+		}
+		return s.scheduler.ScheduleCampaign(ctx, campaign.ID.String(), at)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -91,3 +191,129 @@ func (s *Service) CreateCampaign(
 func (s *Service) GetCampaigns(ctx context.Context, eventID shared.ID) ([]*Campaign, error) {
 	return s.campaignRepo.ListByEvent(ctx, eventID)
 }
+
+// ListDeliveries returns the campaign's per-recipient results. The campaign
+// must belong to eventID.
+func (s *Service) ListDeliveries(ctx context.Context, eventID, campaignID shared.ID) ([]*notifications.Delivery, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil || campaign.EventID != eventID {
+		return nil, ErrCampaignNotFound
+	}
+
+	return s.deliveryRepo.ListByCampaign(ctx, campaignID)
+}
+
+// Deliver sends the campaign to its segment. Only one worker at a time may
+// deliver a campaign; the others return without sending. Recipients already
+// reached are skipped, so a delivery interrupted half way can be run again
+// safely. Any failure after the claim, including transient send failures,
+// puts the campaign back to pending for the caller to retry. On the last
+// attempt transient failures settle the campaign with whoever was reached,
+// and other failures mark it failed.
+func (s *Service) Deliver(ctx context.Context, campaignID shared.ID, last bool) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign == nil {
+		return ErrCampaignNotFound
+	}
+
+	claimed, err := s.campaignRepo.Claim(ctx, campaign.ID, time.Now().Add(-claimLease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	campaign.Status = StatusSending
+
+	if err := s.deliver(ctx, campaign, last); err != nil {
+		// Left in sending, the campaign would stay claimed for the whole
+		// lease and the retry would find nothing to do.
+		campaign.Status = StatusPending
+		if last {
+			campaign.Status = StatusFailed
+		}
+		campaign.Timestamp.Touch()
+		if uerr := s.campaignRepo.Update(ctx, campaign); uerr != nil {
+			return fmt.Errorf("%w (releasing campaign: %v)", err, uerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// deliver sends a claimed campaign and settles it.
+func (s *Service) deliver(ctx context.Context, campaign *Campaign, last bool) error {
+	recipients, err := s.audience.ResolveAudience(ctx, campaign.EventID, campaign.Segment)
+	if err != nil {
+		return err
+	}
+
+	reached, err := s.deliveryRepo.ListCampaignRecipients(ctx, campaign.ID)
+	if err != nil {
+		return err
+	}
+	done := make(map[shared.ID]bool, len(reached))
+	for _, userID := range reached {
+		done[userID] = true
+	}
+
+	text := campaign.Message()
+	sent, failed, transient := len(reached), 0, 0
+	for _, rec := range recipients {
+		if done[rec.UserID] {
+			continue
+		}
+		done[rec.UserID] = true
+
+		delivery := notifications.NewCampaignDelivery(campaign.ID, rec.UserID, campaign.Channel)
+		if rec.ChatID == 0 {
+			delivery.MarkFailed(errNoChat)
+		} else {
+			delivery.Attempts = 1
+			messageID, err := s.botSender.SendText(ctx, rec.ChatID, text)
+			if err != nil {
+				delivery.MarkFailed(err)
+				if s.botSender.Transient(err) {
+					transient++
+				}
+			} else {
+				delivery.MarkSent(messageID)
+			}
+		}
+
+		if delivery.Status == notifications.DeliverySent {
+			sent++
+		} else {
+			failed++
+		}
+
+		if err := s.deliveryRepo.RecordCampaign(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	if transient > 0 && !last {
+		return fmt.Errorf("campaign %s: %w for %d recipients", campaign.ID, ErrTransientFailures, transient)
+	}
+
+	campaign.Status = StatusSent
+	if sent == 0 && failed > 0 {
+		campaign.Status = StatusFailed
+	}
+	campaign.Timestamp.Touch()
+	return s.campaignRepo.Update(ctx, campaign)
+}
+
+func (c *Campaign) Message() string {
+	var content struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(c.Content, &content)
+	return content.Message
+}
@@ -2,85 +2,394 @@ package checkin
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"crypto/ed25519"
+	"errors"
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/tickets"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
 )
 
-// CheckinRepo handles checkin records
+// CheckinRepo handles checkin records. A nil zone stands for the event's
+// main entrance.
 type CheckinRepo interface {
 	Create(ctx context.Context, c *checkin.Checkin) error
-	GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*checkin.Checkin, error)
+	ListByEventAndUser(ctx context.Context, eventID, userID shared.ID, zoneID *shared.ID) ([]*checkin.Checkin, error)
 	ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Checkin, error)
+	Occupancy(ctx context.Context, eventID shared.ID, zoneID *shared.ID) (inside, attended int, err error)
 }
 
-// QRTokenRepo handles QR tokens
-type QRTokenRepo interface {
-	Create(ctx context.Context, token *checkin.QRToken) error
-	GetByHash(ctx context.Context, hash []byte) (*checkin.QRToken, error)
-	DeleteExpired(ctx context.Context) error
+// TicketRepo handles issued tickets
+type TicketRepo interface {
+	Create(ctx context.Context, t *checkin.Ticket) error
+	Update(ctx context.Context, t *checkin.Ticket) error
+	GetByID(ctx context.Context, id shared.ID) (*checkin.Ticket, error)
+	GetLiveByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*checkin.Ticket, error)
+	ListRevocations(ctx context.Context, eventID shared.ID) ([]checkin.Revocation, error)
+	// ListManifest returns every attendee changed after since, or all of
+	// them when since is nil.
+	ListManifest(ctx context.Context, eventID shared.ID, since *time.Time) ([]checkin.ManifestEntry, error)
+}
+
+// KeyRepo stores ticket signing keys. Private keys never leave it
+// unencrypted except through GetActive.
+type KeyRepo interface {
+	Create(ctx context.Context, key *checkin.SigningKey) error
+	GetActive(ctx context.Context) (*checkin.SigningKey, error)
+	ListPublished(ctx context.Context) ([]*checkin.SigningKey, error)
+	RetireActive(ctx context.Context, createdBefore, expiresAt time.Time) error
+	Cover(ctx context.Context, id string, until time.Time) error
+}
+
+// AttemptRepo is the check-in audit trail. Create fails with
+// ErrAttemptExists when a device uploads the same offline scan twice.
+type AttemptRepo interface {
+	Create(ctx context.Context, a *checkin.Attempt) error
+	GetByLocalID(ctx context.Context, eventID shared.ID, deviceID, localID string) (*checkin.Attempt, error)
+	ListByEvent(ctx context.Context, eventID shared.ID, limit, offset int) ([]*checkin.Attempt, error)
+}
+
+// DeviceRepo keeps the sync state of scanner devices
+type DeviceRepo interface {
+	Get(ctx context.Context, eventID shared.ID, deviceID string) (*checkin.Device, error)
+	Upsert(ctx context.Context, d *checkin.Device) error
+	Tally(ctx context.Context, eventID shared.ID, deviceID string, status checkin.SyncStatus, scannedAt time.Time) error
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Device, error)
+}
+
+// ZoneRepo stores event zones. Delete fails with ErrZoneInUse once passes
+// were recorded through the zone.
+type ZoneRepo interface {
+	Create(ctx context.Context, z *checkin.Zone) error
+	Update(ctx context.Context, z *checkin.Zone) error
+	GetByID(ctx context.Context, id shared.ID) (*checkin.Zone, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*checkin.Zone, error)
+	Delete(ctx context.Context, id shared.ID) error
+}
+
+type TicketTypeLister interface {
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*tickets.TicketType, error)
+}
+
+type RoleLookup interface {
+	GetUserRole(ctx context.Context, eventID, userID shared.ID) (events.Role, error)
+}
+
+type RegistrationLookup interface {
+	GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error)
+	// LockByEventAndUser serializes check-ins of one attendee.
+	LockByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error)
+}
+
+type EventGetter interface {
+	GetByID(ctx context.Context, id shared.ID) (*events.Event, error)
+}
+
+// LiveFeed tells the event's live dashboard about committed changes.
+type LiveFeed interface {
+	Publish(ctx context.Context, eventID shared.ID, updates ...*live.Update)
+}
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
+}
+
+// KeyPolicy controls signing key rotation. A key signs for RotateAfter
+// and keeps verifying for Grace after it is replaced, or longer if a ticket
+// it signed is still valid by then.
+type KeyPolicy struct {
+	RotateAfter time.Duration
+	Grace       time.Duration
 }
 
 type Service struct {
-	checkinRepo CheckinRepo
-	qrRepo      QRTokenRepo
-	hmacSecret  []byte
+	uow           UnitOfWork
+	checkinRepo   CheckinRepo
+	attemptRepo   AttemptRepo
+	deviceRepo    DeviceRepo
+	zoneRepo      ZoneRepo
+	ticketRepo    TicketRepo
+	ticketTypes   TicketTypeLister
+	keyRepo       KeyRepo
+	registrations RegistrationLookup
+	roles         RoleLookup
+	eventRepo     EventGetter
+	live          LiveFeed
+	keys          KeyPolicy
 }
 
-func NewService(checkinRepo CheckinRepo, qrRepo QRTokenRepo, hmacSecret string) *Service {
+func NewService(
+	uow UnitOfWork,
+	checkinRepo CheckinRepo,
+	attemptRepo AttemptRepo,
+	deviceRepo DeviceRepo,
+	zoneRepo ZoneRepo,
+	ticketRepo TicketRepo,
+	ticketTypes TicketTypeLister,
+	keyRepo KeyRepo,
+	registrations RegistrationLookup,
+	roles RoleLookup,
+	eventRepo EventGetter,
+	live LiveFeed,
+	keys KeyPolicy,
+) *Service {
 	return &Service{
-		checkinRepo: checkinRepo,
-		qrRepo:      qrRepo,
-		hmacSecret:  []byte(hmacSecret),
+		uow:           uow,
+		checkinRepo:   checkinRepo,
+		attemptRepo:   attemptRepo,
+		deviceRepo:    deviceRepo,
+		zoneRepo:      zoneRepo,
+		ticketRepo:    ticketRepo,
+		ticketTypes:   ticketTypes,
+		keyRepo:       keyRepo,
+		registrations: registrations,
+		roles:         roles,
+		eventRepo:     eventRepo,
+		live:          live,
+		keys:          keys,
 	}
 }
 
-func (s *Service) GenerateQRToken(ctx context.Context, userID, eventID shared.ID) (string, error) {
-	data := userID.String() + ":" + eventID.String() + ":" + time.Now().Format(time.RFC3339)
-	mac := hmac.New(sha256.New, s.hmacSecret)
-	mac.Write([]byte(data))
-	hash := mac.Sum(nil)
+// ValidateAndCheckin checks the ticket and records action for its holder
+// at eventID. Every attempt, accepted or not, lands in the audit trail.
+func (s *Service) ValidateAndCheckin(
+	ctx context.Context,
+	eventID shared.ID,
+	scanner checkin.Scanner,
+	token string,
+	action checkin.Action,
+) (*checkin.Checkin, error) {
+	attempt := checkin.NewAttempt(eventID, scanner, checkin.MethodQR, action)
 
-	token := checkin.NewQRToken(userID, eventID, hash, 24*time.Hour)
-	if err := s.qrRepo.Create(ctx, token); err != nil {
-		return "", err
+	ticket, err := s.checkTicket(ctx, eventID, token, attempt)
+	if err != nil {
+		return nil, s.reject(ctx, attempt, err)
 	}
 
-	tokenStr := base64.URLEncoding.EncodeToString(hash)
-	return tokenStr, nil
+	c, err := s.record(ctx, eventID, ticket.UserID, attempt)
+	if errors.Is(err, checkin.ErrNoConfirmedSeat) {
+		err = checkin.ErrTicketRevoked
+	}
+	if err != nil {
+		return nil, s.reject(ctx, attempt, err)
+	}
+	s.live.Publish(ctx, eventID, liveUpdate(c))
+	return c, nil
 }
 
-func (s *Service) ValidateAndCheckin(ctx context.Context, tokenStr string, method checkin.Method) (*checkin.Checkin, error) {
-	hash, err := base64.URLEncoding.DecodeString(tokenStr)
+// ManualCheckin records action for an attendee found by hand, e.g. by
+// name on the guest list.
+func (s *Service) ManualCheckin(
+	ctx context.Context,
+	eventID shared.ID,
+	scanner checkin.Scanner,
+	userID shared.ID,
+	action checkin.Action,
+) (*checkin.Checkin, error) {
+	attempt := checkin.NewAttempt(eventID, scanner, checkin.MethodManual, action)
+	attempt.UserID = &userID
+
+	c, err := s.record(ctx, eventID, userID, attempt)
 	if err != nil {
-		return nil, checkin.ErrInvalidQRToken
+		return nil, s.reject(ctx, attempt, err)
 	}
+	s.live.Publish(ctx, eventID, liveUpdate(c))
+	return c, nil
+}
 
-	qrToken, err := s.qrRepo.GetByHash(ctx, hash)
+// Deny audits an attempt by someone who may not check attendees in at the
+// event. userID is the attendee of a manual check-in, empty for a scan.
+func (s *Service) Deny(
+	ctx context.Context,
+	eventID shared.ID,
+	scanner checkin.Scanner,
+	method checkin.Method,
+	action checkin.Action,
+	userID shared.ID,
+) error {
+	attempt := checkin.NewAttempt(eventID, scanner, method, action)
+	if userID != "" {
+		attempt.UserID = &userID
+	}
+	attempt.Reject(checkin.ErrScannerDenied)
+	return s.attemptRepo.Create(ctx, attempt)
+}
+
+// ListAttempts pages through the event's audit trail, newest first.
+func (s *Service) ListAttempts(ctx context.Context, eventID shared.ID, limit, offset int) ([]*checkin.Attempt, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.attemptRepo.ListByEvent(ctx, eventID, limit, offset)
+}
+
+// checkTicket verifies the token as of attempt.At, which is in the past for
+// scans uploaded after the fact.
+func (s *Service) checkTicket(ctx context.Context, eventID shared.ID, token string, attempt *checkin.Attempt) (*checkin.Ticket, error) {
+	claims, err := s.verify(ctx, token)
 	if err != nil {
-		return nil, checkin.ErrInvalidQRToken
+		return nil, err
 	}
+	attempt.TicketID = &claims.TicketID
+	attempt.UserID = &claims.UserID
 
-	if qrToken.IsExpired() {
+	if claims.EventID != eventID {
+		return nil, checkin.ErrWrongEvent
+	}
+	if !claims.ValidAt(attempt.At) {
+		return nil, checkin.ErrTicketOutsideWindow
+	}
+
+	ticket, err := s.ticketRepo.GetByID(ctx, claims.TicketID)
+	if errors.Is(err, checkin.ErrTicketNotFound) {
 		return nil, checkin.ErrInvalidQRToken
 	}
+	if err != nil {
+		return nil, err
+	}
+	if ticket.RevokedAt != nil {
+		return nil, checkin.ErrTicketRevoked
+	}
+	return ticket, nil
+}
 
-	c := checkin.NewCheckin(qrToken.EventID, qrToken.UserID, method)
-	if err := s.checkinRepo.Create(ctx, c); err != nil {
+// record stores one pass, dated attempt.At, under a lock on the attendee's
+// registration, so two gates scanning the same ticket cannot both let it
+// in. Entries must pass admit; exits are always allowed. Each zone keeps
+// its own history, apart from the main entrance. The accepted attempt is
+// written in the same transaction.
+func (s *Service) record(ctx context.Context, eventID, userID shared.ID, attempt *checkin.Attempt) (*checkin.Checkin, error) {
+	if attempt.Action == "" {
+		attempt.Action = checkin.ActionEntry
+	}
+	if !attempt.Action.Valid() {
+		return nil, checkin.ErrInvalidAction
+	}
+	zone, err := s.zoneOf(ctx, eventID, attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *checkin.Checkin
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		reg, err := s.registrations.LockByEventAndUser(ctx, eventID, userID)
+		if errors.Is(err, registrations.ErrRegistrationNotFound) {
+			if zone == nil {
+				return checkin.ErrNoConfirmedSeat
+			}
+			reg, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+		if attempt.Action != checkin.ActionExit {
+			if err := s.admit(ctx, zone, userID, reg); err != nil {
+				return err
+			}
+		}
+
+		history, err := s.checkinRepo.ListByEventAndUser(ctx, eventID, userID, attempt.ZoneID)
+		if err != nil {
+			return err
+		}
+		c, err = checkin.Record(history, eventID, userID, attempt.Method, attempt.Action)
+		if err != nil {
+			return err
+		}
+		c.ZoneID = attempt.ZoneID
+		c.At = attempt.At
+		if err := s.checkinRepo.Create(ctx, c); err != nil {
+			return err
+		}
+
+		attempt.Accept(c)
+		return s.attemptRepo.Create(ctx, attempt)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return c, nil
 }
 
-func (s *Service) ManualCheckin(ctx context.Context, eventID, userID shared.ID) (*checkin.Checkin, error) {
-	c := checkin.NewCheckin(eventID, userID, checkin.MethodManual)
-	if err := s.checkinRepo.Create(ctx, c); err != nil {
+func liveUpdate(c *checkin.Checkin) *live.Update {
+	u := live.NewUpdate(live.KindCheckin, c.EventID).For(c.UserID)
+	u.ZoneID = c.ZoneID
+	u.Status = string(c.Direction)
+	u.Reentry = c.Reentry
+	u.At = c.At
+	return u
+}
+
+// reject writes the refused attempt to the audit trail and returns err,
+// joined with the audit failure if there was one.
+func (s *Service) reject(ctx context.Context, attempt *checkin.Attempt, err error) error {
+	attempt.Reject(err)
+	if aerr := s.attemptRepo.Create(ctx, attempt); aerr != nil {
+		return errors.Join(err, aerr)
+	}
+	return err
+}
+
+// Occupancy is how many attendees are inside right now, at the event or
+// in one of its zones. Zones carry no capacity of their own.
+type Occupancy struct {
+	EventID   shared.ID  `json:"event_id"`
+	ZoneID    *shared.ID `json:"zone_id,omitempty"`
+	Inside    int        `json:"inside"`
+	Attended  int        `json:"attended"`
+	Capacity  int        `json:"capacity"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (s *Service) Occupancy(ctx context.Context, eventID shared.ID, zoneID *shared.ID) (*Occupancy, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
 		return nil, err
 	}
-	return c, nil
+	capacity := event.Capacity
+	if zoneID != nil {
+		if _, err := s.GetZone(ctx, eventID, *zoneID); err != nil {
+			return nil, err
+		}
+		capacity = 0
+	}
+	inside, attended, err := s.checkinRepo.Occupancy(ctx, eventID, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Occupancy{
+		EventID:   eventID,
+		ZoneID:    zoneID,
+		Inside:    inside,
+		Attended:  attended,
+		Capacity:  capacity,
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+func (s *Service) verify(ctx context.Context, token string) (*checkin.Claims, error) {
+	keys, err := s.keyRepo.ListPublished(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkin.ParseToken(token, func(id string) (ed25519.PublicKey, bool) {
+		for _, k := range keys {
+			if k.ID == id {
+				return k.PublicKey, true
+			}
+		}
+		return nil, false
+	})
 }
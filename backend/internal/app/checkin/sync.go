@@ -0,0 +1,288 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+// ManifestOverlap is how far back a delta cursor reaches behind the time
+// the manifest was taken, so rows committed late by slow transactions are
+// not skipped. Devices apply entries by user ID and can take repeats.
+const ManifestOverlap = time.Minute
+
+// MaxSyncBatch caps the scans uploaded in one request.
+const MaxSyncBatch = 500
+
+// Manifest is what a scanner device needs to check attendees in offline.
+// Pass Cursor back as since to get only what changed. Zones always come
+// in full, so a device can apply their ticket type rules itself.
+type Manifest struct {
+	EventID shared.ID               `json:"event_id"`
+	Full    bool                    `json:"full"`
+	Cursor  time.Time               `json:"cursor"`
+	Zones   []*checkin.Zone         `json:"zones"`
+	Entries []checkin.ManifestEntry `json:"entries"`
+}
+
+// OfflineScan is a pass a device recorded on its own. It carries either
+// the scanned token or, for a check-in by name, the attendee's user ID.
+// ZoneID overrides the zone of the upload for this scan.
+type OfflineScan struct {
+	LocalID   string         `json:"local_id"`
+	Token     string         `json:"token,omitempty"`
+	UserID    shared.ID      `json:"user_id,omitempty"`
+	ZoneID    *shared.ID     `json:"zone_id,omitempty"`
+	Action    checkin.Action `json:"action"`
+	ScannedAt time.Time      `json:"scanned_at"`
+}
+
+type SyncResult struct {
+	LocalID   string             `json:"local_id"`
+	Status    checkin.SyncStatus `json:"status"`
+	Reason    string             `json:"reason,omitempty"`
+	CheckinID *shared.ID         `json:"checkin_id,omitempty"`
+	// FirstCheckinAt is when the attendee was first let in, for conflicts
+	// found in this upload.
+	FirstCheckinAt *time.Time `json:"first_checkin_at,omitempty"`
+}
+
+var (
+	ErrSyncBatchTooLarge = errors.New("too many scans in one upload")
+	ErrMissingLocalID    = errors.New("every scan needs a local_id")
+)
+
+// Manifest returns the event's attendees for the scanner's device, all of
+// them or those changed after since.
+func (s *Service) Manifest(ctx context.Context, eventID shared.ID, scanner checkin.Scanner, since *time.Time) (*Manifest, error) {
+	if scanner.DeviceID == "" {
+		return nil, checkin.ErrDeviceRequired
+	}
+
+	now := time.Now().UTC()
+	entries, err := s.ticketRepo.ListManifest(ctx, eventID, since)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []checkin.ManifestEntry{}
+	}
+	zones, err := s.zoneRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		EventID: eventID,
+		Full:    since == nil,
+		Cursor:  now.Add(-ManifestOverlap),
+		Zones:   zones,
+		Entries: entries,
+	}
+
+	device, err := s.device(ctx, eventID, scanner)
+	if err != nil {
+		return nil, err
+	}
+	device.ManifestCursor = &m.Cursor
+	device.ManifestAt = &now
+	if err := s.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// SyncScans settles scans a device made offline, in the order they were
+// made. Uploads are idempotent per device and local ID, so a device that
+// lost the response can send the same batch again. The first pass to
+// reach the server wins; a later one that disagrees with it, such as a
+// second entry on the same ticket from another gate, comes back as a
+// conflict and is left out of attendance.
+func (s *Service) SyncScans(ctx context.Context, eventID shared.ID, scanner checkin.Scanner, scans []OfflineScan, pending int) ([]SyncResult, error) {
+	if scanner.DeviceID == "" {
+		return nil, checkin.ErrDeviceRequired
+	}
+	if len(scans) > MaxSyncBatch {
+		return nil, ErrSyncBatchTooLarge
+	}
+	for _, scan := range scans {
+		if scan.LocalID == "" {
+			return nil, ErrMissingLocalID
+		}
+	}
+
+	order := make([]int, len(scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scans[order[a]].ScannedAt.Before(scans[order[b]].ScannedAt)
+	})
+
+	device, err := s.device(ctx, eventID, scanner)
+	if err != nil {
+		return nil, err
+	}
+	// The row has to exist for scans to be tallied against it.
+	if err := s.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+
+	results := make([]SyncResult, len(scans))
+	var updates []*live.Update
+	for _, i := range order {
+		res, c, err := s.syncScan(ctx, eventID, scanner, scans[i])
+		if c != nil {
+			updates = append(updates, liveUpdate(c))
+		}
+		if err != nil {
+			log.Printf("checkin sync: event %s device %s scan %s: %v", eventID, scanner.DeviceID, scans[i].LocalID, err)
+			res = SyncResult{LocalID: scans[i].LocalID, Status: checkin.SyncRetry}
+		}
+		results[i] = res
+	}
+
+	now := time.Now().UTC()
+	device.UploadedAt = &now
+	device.Pending = max(pending, 0)
+	s.live.Publish(ctx, eventID, updates...)
+	if err := s.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ListDevices reports the sync state of every device that has scanned at
+// the event.
+func (s *Service) ListDevices(ctx context.Context, eventID shared.ID) ([]*checkin.Device, error) {
+	return s.deviceRepo.ListByEvent(ctx, eventID)
+}
+
+func (s *Service) device(ctx context.Context, eventID shared.ID, scanner checkin.Scanner) (*checkin.Device, error) {
+	device, err := s.deviceRepo.Get(ctx, eventID, scanner.DeviceID)
+	if errors.Is(err, checkin.ErrDeviceNotFound) {
+		return checkin.NewDevice(eventID, scanner), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	device.Seen(scanner)
+	return device, nil
+}
+
+// syncScan settles one offline scan and returns the pass it recorded, if
+// this upload recorded one. A scan settled here is tallied against the
+// device in the same transaction; one settled before is only answered
+// again. Errors are ours, not the scan's: the device should upload it
+// again later.
+func (s *Service) syncScan(ctx context.Context, eventID shared.ID, scanner checkin.Scanner, scan OfflineScan) (SyncResult, *checkin.Checkin, error) {
+	prev, err := s.attemptRepo.GetByLocalID(ctx, eventID, scanner.DeviceID, scan.LocalID)
+	if err == nil {
+		return resultOf(prev), nil, nil
+	}
+	if !errors.Is(err, checkin.ErrAttemptNotFound) {
+		return SyncResult{}, nil, err
+	}
+
+	method := checkin.MethodQR
+	if scan.Token == "" {
+		method = checkin.MethodManual
+	}
+	attempt := checkin.NewAttempt(eventID, scanner, method, scan.Action)
+	attempt.LocalID = scan.LocalID
+	if scan.ZoneID != nil && *scan.ZoneID != "" {
+		attempt.ZoneID = scan.ZoneID
+	}
+	// Device clocks drift; a scan cannot have happened after its upload.
+	if !scan.ScannedAt.IsZero() && scan.ScannedAt.Before(attempt.ReceivedAt) {
+		attempt.At = scan.ScannedAt.UTC()
+	}
+
+	var c *checkin.Checkin
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		var err error
+		switch {
+		case method == checkin.MethodQR:
+			var ticket *checkin.Ticket
+			ticket, err = s.checkTicket(ctx, eventID, scan.Token, attempt)
+			if err == nil {
+				c, err = s.record(ctx, eventID, ticket.UserID, attempt)
+				if errors.Is(err, checkin.ErrNoConfirmedSeat) {
+					err = checkin.ErrTicketRevoked
+				}
+			}
+		case scan.UserID == "":
+			err = checkin.ErrInvalidQRToken
+		default:
+			attempt.UserID = &scan.UserID
+			c, err = s.record(ctx, eventID, scan.UserID, attempt)
+		}
+		if err != nil {
+			return err
+		}
+		return s.tally(ctx, attempt)
+	})
+
+	if errors.Is(err, checkin.ErrAttemptExists) {
+		res, err := s.replay(ctx, eventID, scanner.DeviceID, scan.LocalID)
+		return res, nil, err
+	}
+	if err != nil {
+		if checkin.RejectReason(err) == checkin.ReasonInternal {
+			return SyncResult{}, nil, err
+		}
+		attempt.Reject(err)
+		aerr := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+			if err := s.attemptRepo.Create(ctx, attempt); err != nil {
+				return err
+			}
+			return s.tally(ctx, attempt)
+		})
+		if errors.Is(aerr, checkin.ErrAttemptExists) {
+			res, err := s.replay(ctx, eventID, scanner.DeviceID, scan.LocalID)
+			return res, nil, err
+		} else if aerr != nil {
+			return SyncResult{}, nil, aerr
+		}
+
+		res := resultOf(attempt)
+		var dup *checkin.DuplicateError
+		if errors.As(err, &dup) {
+			res.FirstCheckinAt = &dup.FirstAt
+		}
+		return res, nil, nil
+	}
+
+	return resultOf(attempt), c, nil
+}
+
+// tally counts a settled scan against the device that uploaded it.
+func (s *Service) tally(ctx context.Context, a *checkin.Attempt) error {
+	return s.deviceRepo.Tally(ctx, a.EventID, a.DeviceID, a.Status(), a.At)
+}
+
+// replay answers a scan uploaded concurrently by another request.
+func (s *Service) replay(ctx context.Context, eventID shared.ID, deviceID, localID string) (SyncResult, error) {
+	prev, err := s.attemptRepo.GetByLocalID(ctx, eventID, deviceID, localID)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return resultOf(prev), nil
+}
+
+func resultOf(a *checkin.Attempt) SyncResult {
+	return SyncResult{
+		LocalID:   a.LocalID,
+		Status:    a.Status(),
+		Reason:    a.Reason,
+		CheckinID: a.CheckinID,
+	}
+}
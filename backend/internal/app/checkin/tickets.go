@@ -0,0 +1,175 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+// IssueTicket returns the attendee's ticket for the event with a freshly
+// signed token. The ticket is created on first use and then kept; its
+// window follows the event if it is rescheduled.
+func (s *Service) IssueTicket(ctx context.Context, eventID, userID shared.ID) (*checkin.Ticket, string, error) {
+	pass, err := s.IssuePass(ctx, eventID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return pass.Ticket, pass.Token, nil
+}
+
+// Pass is a ticket with everything needed to print it.
+type Pass struct {
+	Ticket *checkin.Ticket
+	Token  string
+	Event  *events.Event
+}
+
+// IssuePass is IssueTicket for rendering: it also returns the event the
+// ticket is for.
+func (s *Service) IssuePass(ctx context.Context, eventID, userID shared.ID) (*Pass, error) {
+	reg, err := s.confirmedRegistration(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.ticketRepo.GetLiveByEventAndUser(ctx, eventID, userID)
+	switch {
+	case errors.Is(err, checkin.ErrTicketNotFound):
+		ticket = checkin.NewTicket(eventID, userID, reg.TicketTypeID, event.StartsAt, event.EndsAt)
+		if err := s.ticketRepo.Create(ctx, ticket); err != nil {
+			return nil, err
+		}
+		// A concurrent request may have created it first.
+		if ticket, err = s.ticketRepo.GetLiveByEventAndUser(ctx, eventID, userID); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		changed := ticket.Reschedule(event.StartsAt, event.EndsAt)
+		if !sameID(ticket.TicketTypeID, reg.TicketTypeID) {
+			ticket.TicketTypeID = reg.TicketTypeID
+			changed = true
+		}
+		if changed {
+			if err := s.ticketRepo.Update(ctx, ticket); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	token, err := s.sign(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pass{Ticket: ticket, Token: token, Event: event}, nil
+}
+
+// sign issues a token for the ticket and makes sure the key that signed it
+// stays published for as long as the token is valid, even once rotated.
+func (s *Service) sign(ctx context.Context, ticket *checkin.Ticket) (string, error) {
+	key, err := s.signingKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !key.Covers(ticket.ValidUntil) {
+		if err := s.keyRepo.Cover(ctx, key.ID, ticket.ValidUntil); err != nil {
+			return "", err
+		}
+	}
+	return ticket.Claims().Sign(key)
+}
+
+// RevokeTicket invalidates a ticket, e.g. one that leaked. The attendee
+// gets a new ticket the next time they open it.
+func (s *Service) RevokeTicket(ctx context.Context, eventID, ticketID shared.ID, reason string) (*checkin.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.EventID != eventID {
+		return nil, checkin.ErrTicketNotFound
+	}
+	if ticket.RevokedAt != nil {
+		return ticket, nil
+	}
+
+	ticket.Revoke(reason)
+	if err := s.ticketRepo.Update(ctx, ticket); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// RevocationList is what scanners must refuse for the event even though
+// the signature checks out.
+func (s *Service) RevocationList(ctx context.Context, eventID shared.ID) ([]checkin.Revocation, error) {
+	return s.ticketRepo.ListRevocations(ctx, eventID)
+}
+
+// PublicKeys lists the keys scanners should accept.
+func (s *Service) PublicKeys(ctx context.Context) ([]*checkin.SigningKey, error) {
+	return s.keyRepo.ListPublished(ctx)
+}
+
+// signingKey returns the current signing key, rotating it once it is older
+// than the policy allows.
+func (s *Service) signingKey(ctx context.Context) (*checkin.SigningKey, error) {
+	key, err := s.keyRepo.GetActive(ctx)
+	if err != nil && !errors.Is(err, checkin.ErrNoSigningKey) {
+		return nil, err
+	}
+	if key != nil && time.Since(key.CreatedAt) < s.keys.RotateAfter {
+		return key, nil
+	}
+
+	next, err := checkin.NewSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		if err := s.keyRepo.RetireActive(ctx, now.Add(-s.keys.RotateAfter), now.Add(s.keys.Grace)); err != nil {
+			return err
+		}
+		// Loses quietly to another instance rotating at the same time.
+		return s.keyRepo.Create(ctx, next)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.keyRepo.GetActive(ctx)
+}
+
+func (s *Service) confirmedRegistration(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error) {
+	reg, err := s.registrations.GetByEventAndUser(ctx, eventID, userID)
+	if errors.Is(err, registrations.ErrRegistrationNotFound) {
+		return nil, checkin.ErrNoConfirmedSeat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reg.Status != registrations.StatusGoing {
+		return nil, checkin.ErrNoConfirmedSeat
+	}
+	return reg, nil
+}
+
+func sameID(a, b *shared.ID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
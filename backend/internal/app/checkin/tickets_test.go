@@ -0,0 +1,135 @@
+package checkin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+)
+
+type fakeUOW struct{}
+
+func (fakeUOW) WithTx(ctx context.Context, fn uow.TxFunc) error {
+	return fn(ctx, nil)
+}
+
+// fakeKeys mirrors SigningKeyRepo, with a clock of its own for deciding
+// which keys are still published.
+type fakeKeys struct {
+	now  time.Time
+	keys []*checkin.SigningKey
+}
+
+func (f *fakeKeys) Create(_ context.Context, key *checkin.SigningKey) error {
+	if active, _ := f.GetActive(context.Background()); active != nil {
+		return nil
+	}
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func (f *fakeKeys) GetActive(context.Context) (*checkin.SigningKey, error) {
+	for _, k := range f.keys {
+		if k.RetiredAt == nil {
+			copied := *k
+			return &copied, nil
+		}
+	}
+	return nil, checkin.ErrNoSigningKey
+}
+
+func (f *fakeKeys) ListPublished(context.Context) ([]*checkin.SigningKey, error) {
+	var result []*checkin.SigningKey
+	for _, k := range f.keys {
+		if k.ExpiresAt == nil || k.ExpiresAt.After(f.now) {
+			result = append(result, k)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeKeys) RetireActive(_ context.Context, createdBefore, expiresAt time.Time) error {
+	for _, k := range f.keys {
+		if k.RetiredAt == nil && k.CreatedAt.Before(createdBefore) {
+			now := time.Now()
+			k.RetiredAt = &now
+			k.ExpiresAt = latest(&expiresAt, k.SignedUntil)
+		}
+	}
+	return nil
+}
+
+func (f *fakeKeys) Cover(_ context.Context, id string, until time.Time) error {
+	for _, k := range f.keys {
+		if k.ID == id {
+			k.SignedUntil = latest(k.SignedUntil, &until)
+			if k.ExpiresAt != nil {
+				k.ExpiresAt = latest(k.ExpiresAt, &until)
+			}
+		}
+	}
+	return nil
+}
+
+// latest behaves like GREATEST: NULLs are ignored.
+func latest(a, b *time.Time) *time.Time {
+	if a == nil || b != nil && b.After(*a) {
+		return b
+	}
+	return a
+}
+
+func TestSignRotateVerify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	keys := &fakeKeys{now: now}
+	s := &Service{
+		uow:     fakeUOW{},
+		keyRepo: keys,
+		keys:    KeyPolicy{RotateAfter: 90 * 24 * time.Hour, Grace: 30 * 24 * time.Hour},
+	}
+
+	// Issued well ahead of an event that is longer away than the grace
+	// period.
+	startsAt := now.Add(60 * 24 * time.Hour)
+	ticket := checkin.NewTicket(shared.NewID(), shared.NewID(), nil, startsAt, startsAt.Add(3*time.Hour))
+	token, err := s.sign(ctx, ticket)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	first := keys.keys[0].ID
+
+	// The key comes of age and the next ticket rotates it.
+	keys.keys[0].CreatedAt = now.Add(-91 * 24 * time.Hour)
+	other := checkin.NewTicket(shared.NewID(), shared.NewID(), nil, now, now.Add(time.Hour))
+	if _, err := s.sign(ctx, other); err != nil {
+		t.Fatalf("sign after rotation: %v", err)
+	}
+	if active, _ := keys.GetActive(ctx); active == nil || active.ID == first {
+		t.Fatalf("key was not rotated")
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"within grace", now.Add(time.Hour), true},
+		{"past grace, at the event", startsAt, true},
+		{"after the ticket ran out", ticket.ValidUntil.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		keys.now = tt.at
+		claims, err := s.verify(ctx, token)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: verify err = %v, want ok = %v", tt.name, err, tt.ok)
+			continue
+		}
+		if tt.ok && claims.TicketID != ticket.ID {
+			t.Errorf("%s: ticket = %s, want %s", tt.name, claims.TicketID, ticket.ID)
+		}
+	}
+}
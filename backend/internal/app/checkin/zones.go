@@ -0,0 +1,148 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/checkin"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+func (s *Service) ListZones(ctx context.Context, eventID shared.ID) ([]*checkin.Zone, error) {
+	return s.zoneRepo.ListByEvent(ctx, eventID)
+}
+
+func (s *Service) GetZone(ctx context.Context, eventID, id shared.ID) (*checkin.Zone, error) {
+	z, err := s.zoneRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if z.EventID != eventID {
+		return nil, checkin.ErrZoneNotFound
+	}
+	return z, nil
+}
+
+func (s *Service) CreateZone(ctx context.Context, z *checkin.Zone) (*checkin.Zone, error) {
+	if err := s.validateZone(ctx, z); err != nil {
+		return nil, err
+	}
+	if err := s.zoneRepo.Create(ctx, z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// UpdateZone replaces the zone's name and rules. New rules apply to the
+// next scan; passes already recorded stay.
+func (s *Service) UpdateZone(ctx context.Context, eventID, id shared.ID, updates *checkin.Zone) (*checkin.Zone, error) {
+	z, err := s.GetZone(ctx, eventID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	z.Name = updates.Name
+	z.TicketTypeIDs = updates.TicketTypeIDs
+	z.Roles = updates.Roles
+	z.SortOrder = updates.SortOrder
+	z.UpdatedAt = time.Now().UTC()
+
+	if err := s.validateZone(ctx, z); err != nil {
+		return nil, err
+	}
+	if err := s.zoneRepo.Update(ctx, z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+func (s *Service) DeleteZone(ctx context.Context, eventID, id shared.ID) error {
+	if _, err := s.GetZone(ctx, eventID, id); err != nil {
+		return err
+	}
+	return s.zoneRepo.Delete(ctx, id)
+}
+
+// validateZone also checks that every listed ticket type is the event's.
+func (s *Service) validateZone(ctx context.Context, z *checkin.Zone) error {
+	if err := z.Validate(); err != nil {
+		return err
+	}
+	if len(z.TicketTypeIDs) == 0 {
+		return nil
+	}
+
+	types, err := s.ticketTypes.ListByEvent(ctx, z.EventID)
+	if err != nil {
+		return err
+	}
+	known := make(map[shared.ID]bool, len(types))
+	for _, t := range types {
+		known[t.ID] = true
+	}
+	for _, id := range z.TicketTypeIDs {
+		if !known[id] {
+			return checkin.ErrInvalidZone
+		}
+	}
+	return nil
+}
+
+// zoneOf loads the zone the attempt is for, nil at the main entrance. An
+// unknown zone is dropped from the attempt so that the audit record still
+// saves.
+func (s *Service) zoneOf(ctx context.Context, eventID shared.ID, attempt *checkin.Attempt) (*checkin.Zone, error) {
+	if attempt.ZoneID == nil {
+		return nil, nil
+	}
+	z, err := s.GetZone(ctx, eventID, *attempt.ZoneID)
+	if errors.Is(err, checkin.ErrZoneNotFound) {
+		attempt.ZoneID = nil
+	}
+	return z, err
+}
+
+// admit decides whether the attendee may come in through the zone's door,
+// or the main entrance when zone is nil. reg is nil when they have no
+// registration, which only staff let in by role get past.
+func (s *Service) admit(ctx context.Context, zone *checkin.Zone, userID shared.ID, reg *registrations.Registration) error {
+	seated := reg != nil && reg.Status == registrations.StatusGoing
+	if zone == nil || zone.Open() {
+		if !seated {
+			return checkin.ErrNoConfirmedSeat
+		}
+		return nil
+	}
+	if seated && zone.AdmitsTicketType(reg.TicketTypeID) {
+		return nil
+	}
+
+	if len(zone.Roles) > 0 {
+		role, err := s.roleAt(ctx, zone.EventID, userID)
+		if err != nil {
+			return err
+		}
+		if zone.AdmitsRole(role) {
+			return nil
+		}
+	}
+
+	if !seated {
+		return checkin.ErrNoConfirmedSeat
+	}
+	return checkin.ErrZoneDenied
+}
+
+func (s *Service) roleAt(ctx context.Context, eventID, userID shared.ID) (events.Role, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	if event.OwnerID == userID {
+		return events.RoleOwner, nil
+	}
+	return s.roles.GetUserRole(ctx, eventID, userID)
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// ViewEvent returns the event if the user may see it. Events the user may not
+// see are reported as not found so their existence does not leak.
+func (s *Service) ViewEvent(ctx context.Context, eventID, userID shared.ID, shareToken string) (*events.Event, error) {
+	event, err := s.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := s.viewer(ctx, event, userID, shareToken)
+	if err != nil {
+		return nil, err
+	}
+	if !events.CanUserView(event, viewer) {
+		return nil, events.ErrEventNotFound
+	}
+
+	return event, nil
+}
+
+func (s *Service) viewer(ctx context.Context, event *events.Event, userID shared.ID, shareToken string) (events.Viewer, error) {
+	viewer := events.Viewer{UserID: userID, ShareToken: shareToken}
+	if userID == "" || event.IsListed() {
+		return viewer, nil
+	}
+
+	role, err := s.roleRepo.GetUserRole(ctx, event.ID, userID)
+	if err != nil {
+		return viewer, err
+	}
+	viewer.Role = role
+
+	invited, err := s.inviteRepo.Exists(ctx, event.ID, userID)
+	if err != nil {
+		return viewer, err
+	}
+	viewer.Invited = invited
+
+	if event.OrganizationID != nil {
+		orgRole, err := s.orgMembers.GetRole(ctx, *event.OrganizationID, userID)
+		if err != nil {
+			return viewer, err
+		}
+		viewer.Member = orgRole != ""
+	}
+
+	return viewer, nil
+}
+
+// ListOrganizationEvents returns the organization's non-draft events that
+// the user may see.
+func (s *Service) ListOrganizationEvents(ctx context.Context, orgID, userID shared.ID) ([]*events.Event, error) {
+	list, err := s.eventRepo.ListByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*events.Event, 0, len(list))
+	for _, event := range list {
+		viewer, err := s.viewer(ctx, event, userID, "")
+		if err != nil {
+			return nil, err
+		}
+		if events.CanUserView(event, viewer) {
+			result = append(result, event)
+		}
+	}
+
+	return result, nil
+}
+
+// GetShareLink returns the current share token, creating one on first use.
+func (s *Service) GetShareLink(ctx context.Context, userID, eventID shared.ID) (string, error) {
+	event, err := s.getEditable(ctx, userID, eventID)
+	if err != nil {
+		return "", err
+	}
+
+	if event.ShareToken != "" {
+		return event.ShareToken, nil
+	}
+
+	return s.rotateShareToken(ctx, event)
+}
+
+func (s *Service) RotateShareLink(ctx context.Context, userID, eventID shared.ID) (string, error) {
+	event, err := s.getEditable(ctx, userID, eventID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.rotateShareToken(ctx, event)
+}
+
+func (s *Service) RevokeShareLink(ctx context.Context, userID, eventID shared.ID) error {
+	event, err := s.getEditable(ctx, userID, eventID)
+	if err != nil {
+		return err
+	}
+
+	event.RevokeShareToken()
+	if err := s.eventRepo.Update(ctx, event); err != nil {
+		return err
+	}
+
+	s.cache.InvalidateEvent(ctx, eventID)
+	return nil
+}
+
+func (s *Service) rotateShareToken(ctx context.Context, event *events.Event) (string, error) {
+	if err := event.RotateShareToken(); err != nil {
+		return "", err
+	}
+	if err := s.eventRepo.Update(ctx, event); err != nil {
+		return "", err
+	}
+
+	s.cache.InvalidateEvent(ctx, event.ID)
+	return event.ShareToken, nil
+}
+
+func (s *Service) InviteUsers(ctx context.Context, userID, eventID shared.ID, inviteeIDs []shared.ID) error {
+	if _, err := s.getEditable(ctx, userID, eventID); err != nil {
+		return err
+	}
+
+	for _, inviteeID := range inviteeIDs {
+		if err := s.inviteRepo.Create(ctx, eventID, inviteeID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) ListInvites(ctx context.Context, userID, eventID shared.ID) ([]shared.ID, error) {
+	if _, err := s.getEditable(ctx, userID, eventID); err != nil {
+		return nil, err
+	}
+
+	return s.inviteRepo.ListByEvent(ctx, eventID)
+}
+
+func (s *Service) RevokeInvite(ctx context.Context, userID, eventID, inviteeID shared.ID) error {
+	if _, err := s.getEditable(ctx, userID, eventID); err != nil {
+		return err
+	}
+
+	return s.inviteRepo.Delete(ctx, eventID, inviteeID)
+}
+
+func (s *Service) getEditable(ctx context.Context, userID, eventID shared.ID) (*events.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.CanUserEdit(event, userID, role) {
+		return nil, events.ErrUnauthorized
+	}
+
+	return event, nil
+}
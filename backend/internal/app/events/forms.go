@@ -0,0 +1,29 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// SetFormRequirement makes submitting the event's active form a condition
+// of a confirmed seat. Attendees who register without it keep their seat
+// for hold; a zero hold uses the default.
+func (s *Service) SetFormRequirement(ctx context.Context, userID, eventID shared.ID, required bool, hold time.Duration) (*events.Event, error) {
+	event, err := s.Authorize(ctx, eventID, userID, events.PermManageForms)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := event.SetFormRequirement(required, hold); err != nil {
+		return nil, err
+	}
+	if err := s.eventRepo.Update(ctx, event); err != nil {
+		return nil, err
+	}
+
+	s.cache.InvalidateEvent(ctx, eventID)
+	return event, nil
+}
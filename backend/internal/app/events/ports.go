@@ -5,20 +5,33 @@ import (
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
 )
 
 type EventRepo interface {
 	Create(ctx context.Context, event *events.Event) error
 	Update(ctx context.Context, event *events.Event) error
 	GetByID(ctx context.Context, id shared.ID) (*events.Event, error)
+	Lock(ctx context.Context, id shared.ID) (*events.Event, error)
 	ListPublic(ctx context.Context, limit, offset int) ([]*events.Event, error)
+	ListBySeries(ctx context.Context, seriesID shared.ID) ([]*events.Event, error)
+	// CreateOccurrence inserts an occurrence unless its series already has
+	// one at the same original start, and reports whether it did.
+	CreateOccurrence(ctx context.Context, event *events.Event) (bool, error)
+	ListByOrganization(ctx context.Context, orgID shared.ID) ([]*events.Event, error)
 	Delete(ctx context.Context, id shared.ID) error
 }
 
 type SeriesRepo interface {
 	Create(ctx context.Context, series *events.Series) error
+	GetByID(ctx context.Context, id shared.ID) (*events.Series, error)
 	GetByEventID(ctx context.Context, eventID shared.ID) (*events.Series, error)
+	// Lock returns the series and holds its row until the transaction ends.
+	Lock(ctx context.Context, id shared.ID) (*events.Series, error)
+	// ListOpen returns the series that may still have occurrences after at.
+	ListOpen(ctx context.Context, at time.Time) ([]shared.ID, error)
 	Update(ctx context.Context, series *events.Series) error
 	Delete(ctx context.Context, id shared.ID) error
 }
@@ -30,8 +43,34 @@ type RoleRepo interface {
 	Delete(ctx context.Context, eventID, userID shared.ID) error
 }
 
+type InviteRepo interface {
+	Create(ctx context.Context, eventID, userID, invitedBy shared.ID) error
+	Exists(ctx context.Context, eventID, userID shared.ID) (bool, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]shared.ID, error)
+	Delete(ctx context.Context, eventID, userID shared.ID) error
+}
+
+type RoleInviteRepo interface {
+	Create(ctx context.Context, invite *events.RoleInvite) error
+	MarkUsed(ctx context.Context, invite *events.RoleInvite) (bool, error)
+	GetByToken(ctx context.Context, token string) (*events.RoleInvite, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*events.RoleInvite, error)
+	Delete(ctx context.Context, eventID, id shared.ID) error
+}
+
+type OrgMembership interface {
+	GetRole(ctx context.Context, orgID, userID shared.ID) (orgs.Role, error)
+}
+
+type ReminderRepo interface {
+	Create(ctx context.Context, reminder *events.Reminder) error
+	UpdateStatus(ctx context.Context, id shared.ID, status events.ReminderStatus) error
+	ListPending(ctx context.Context, eventID shared.ID) ([]*events.Reminder, error)
+}
+
 type Scheduler interface {
-	ScheduleReminder(ctx context.Context, at time.Time, payload interface{}) (string, error)
+	ScheduleReminder(ctx context.Context, reminder *events.Reminder) (string, error)
+	CancelReminder(ctx context.Context, taskID string) error
 }
 
 type Cache interface {
@@ -40,20 +79,45 @@ type Cache interface {
 	InvalidateEvent(ctx context.Context, id shared.ID)
 }
 
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
+}
+
 type Service struct {
-	eventRepo  EventRepo
-	seriesRepo SeriesRepo
-	roleRepo   RoleRepo
-	scheduler  Scheduler
-	cache      Cache
+	uow            UnitOfWork
+	eventRepo      EventRepo
+	seriesRepo     SeriesRepo
+	roleRepo       RoleRepo
+	roleInviteRepo RoleInviteRepo
+	inviteRepo     InviteRepo
+	orgMembers     OrgMembership
+	reminderRepo   ReminderRepo
+	scheduler      Scheduler
+	cache          Cache
 }
 
-func NewService(eventRepo EventRepo, seriesRepo SeriesRepo, roleRepo RoleRepo, scheduler Scheduler, cache Cache) *Service {
+func NewService(
+	uow UnitOfWork,
+	eventRepo EventRepo,
+	seriesRepo SeriesRepo,
+	roleRepo RoleRepo,
+	roleInviteRepo RoleInviteRepo,
+	inviteRepo InviteRepo,
+	orgMembers OrgMembership,
+	reminderRepo ReminderRepo,
+	scheduler Scheduler,
+	cache Cache,
+) *Service {
 	return &Service{
-		eventRepo:  eventRepo,
-		seriesRepo: seriesRepo,
-		roleRepo:   roleRepo,
-		scheduler:  scheduler,
-		cache:      cache,
+		uow:            uow,
+		eventRepo:      eventRepo,
+		seriesRepo:     seriesRepo,
+		roleRepo:       roleRepo,
+		roleInviteRepo: roleInviteRepo,
+		inviteRepo:     inviteRepo,
+		orgMembers:     orgMembers,
+		reminderRepo:   reminderRepo,
+		scheduler:      scheduler,
+		cache:          cache,
 	}
 }
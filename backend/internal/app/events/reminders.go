@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListReminders returns the event's reminder offsets and the reminders that
+// are still waiting to go out.
+func (s *Service) ListReminders(ctx context.Context, userID, eventID shared.ID) ([]time.Duration, []*events.Reminder, error) {
+	event, err := s.Authorize(ctx, eventID, userID, events.PermEditEvent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending, err := s.reminderRepo.ListPending(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return event.ReminderOffsets(), pending, nil
+}
+
+// SetReminderOffsets changes when the event's reminders go out and
+// reschedules them. A nil slice restores the defaults.
+func (s *Service) SetReminderOffsets(ctx context.Context, userID, eventID shared.ID, offsets []time.Duration) error {
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		event, err := s.eventRepo.Lock(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		role, err := s.roleRepo.GetUserRole(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+		if !events.HasPermission(event, userID, role, events.PermEditEvent) {
+			return events.ErrUnauthorized
+		}
+
+		if err := event.SetReminderOffsets(offsets); err != nil {
+			return err
+		}
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		return s.syncReminders(ctx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateEvent(ctx, eventID)
+	return nil
+}
+
+// syncReminders makes the scheduled reminders match the event. Pending
+// reminders that no longer fit its start time or offsets are cancelled, and
+// missing ones are scheduled while the event is published. Calling it again
+// without changes is a no-op, so republishing never duplicates reminders.
+// Callers hold the event's row, so concurrent syncs cannot both see the
+// same reminder missing.
+func (s *Service) syncReminders(ctx context.Context, event *events.Event) error {
+	pending, err := s.reminderRepo.ListPending(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	wanted := make(map[time.Duration]time.Time)
+	if event.Status == events.StatusPublished {
+		for _, offset := range event.ReminderOffsets() {
+			if at := event.StartsAt.Add(-offset); at.After(now) {
+				wanted[offset] = at
+			}
+		}
+	}
+
+	for _, reminder := range pending {
+		if at, ok := wanted[reminder.Offset]; ok && at.Equal(reminder.At) {
+			delete(wanted, reminder.Offset)
+			continue
+		}
+
+		// A task that cannot be removed, for example because it is already
+		// running, is left alone; the worker only sends pending reminders.
+		if reminder.TaskID != "" {
+			_ = s.scheduler.CancelReminder(ctx, reminder.TaskID)
+		}
+		if err := s.reminderRepo.UpdateStatus(ctx, reminder.ID, events.ReminderCancelled); err != nil {
+			return err
+		}
+	}
+
+	for offset := range wanted {
+		reminder := events.NewReminder(event.ID, event.StartsAt, offset)
+
+		taskID, err := s.scheduler.ScheduleReminder(ctx, reminder)
+		if err != nil {
+			return err
+		}
+		reminder.TaskID = taskID
+
+		if err := s.reminderRepo.Create(ctx, reminder); err != nil {
+			_ = s.scheduler.CancelReminder(ctx, taskID)
+			return err
+		}
+	}
+
+	return nil
+}
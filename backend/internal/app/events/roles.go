@@ -0,0 +1,264 @@
+package events
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+type RoleAssignment struct {
+	UserID shared.ID
+	Role   events.Role
+}
+
+// Authorize loads the event and checks that the user holds perm on it.
+func (s *Service) Authorize(ctx context.Context, eventID, userID shared.ID, perm events.Permission) (*events.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !events.HasPermission(event, userID, role, perm) {
+		return nil, events.ErrUnauthorized
+	}
+
+	return event, nil
+}
+
+// MyPermissions returns the user's role on the event and what it allows.
+func (s *Service) MyPermissions(ctx context.Context, eventID, userID shared.ID) (events.Role, []events.Permission, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	role, err := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if event.OwnerID == userID {
+		role = events.RoleOwner
+	}
+
+	return role, events.Permissions(event, userID, role), nil
+}
+
+// ListRoles returns the event team. Only members of the team may see it.
+func (s *Service) ListRoles(ctx context.Context, userID, eventID shared.ID) ([]RoleAssignment, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.OwnerID != userID && roles[userID] == "" {
+		return nil, events.ErrUnauthorized
+	}
+	roles[event.OwnerID] = events.RoleOwner
+
+	result := make([]RoleAssignment, 0, len(roles))
+	for id, role := range roles {
+		result = append(result, RoleAssignment{UserID: id, Role: role})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Role != result[j].Role {
+			return result[i].Role.Outranks(result[j].Role)
+		}
+		return result[i].UserID < result[j].UserID
+	})
+
+	return result, nil
+}
+
+// GrantRole gives targetID a role on the event, replacing any role they hold.
+func (s *Service) GrantRole(ctx context.Context, userID, eventID, targetID shared.ID, role events.Role) error {
+	if !role.Grantable() {
+		return events.ErrInvalidRole
+	}
+
+	event, granterRole, err := s.getWithRole(ctx, eventID, userID)
+	if err != nil {
+		return err
+	}
+	if targetID == event.OwnerID {
+		return events.ErrCannotChangeOwner
+	}
+
+	current, err := s.roleRepo.GetUserRole(ctx, eventID, targetID)
+	if err != nil {
+		return err
+	}
+	if !events.CanGrant(event, userID, granterRole, role) ||
+		(current != "" && !events.CanGrant(event, userID, granterRole, current)) {
+		return events.ErrUnauthorized
+	}
+
+	return s.roleRepo.Create(ctx, eventID, targetID, role)
+}
+
+// RevokeRole removes targetID from the event team. Anyone but the owner may
+// also drop their own role.
+func (s *Service) RevokeRole(ctx context.Context, userID, eventID, targetID shared.ID) error {
+	event, granterRole, err := s.getWithRole(ctx, eventID, userID)
+	if err != nil {
+		return err
+	}
+	if targetID == event.OwnerID {
+		return events.ErrCannotChangeOwner
+	}
+
+	if userID != targetID {
+		current, err := s.roleRepo.GetUserRole(ctx, eventID, targetID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return nil
+		}
+		if !events.CanGrant(event, userID, granterRole, current) {
+			return events.ErrUnauthorized
+		}
+	}
+
+	return s.roleRepo.Delete(ctx, eventID, targetID)
+}
+
+// CreateRoleInvite creates a single-use link granting role. A zero ttl
+// creates a link that does not expire.
+func (s *Service) CreateRoleInvite(ctx context.Context, userID, eventID shared.ID, role events.Role, ttl time.Duration) (*events.RoleInvite, error) {
+	event, granterRole, err := s.getWithRole(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := events.NewRoleInvite(eventID, userID, role, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !events.CanGrant(event, userID, granterRole, role) {
+		return nil, events.ErrUnauthorized
+	}
+
+	if err := s.roleInviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+func (s *Service) ListRoleInvites(ctx context.Context, userID, eventID shared.ID) ([]*events.RoleInvite, error) {
+	if _, err := s.Authorize(ctx, eventID, userID, events.PermManageRoles); err != nil {
+		return nil, err
+	}
+
+	return s.roleInviteRepo.ListByEvent(ctx, eventID)
+}
+
+func (s *Service) RevokeRoleInvite(ctx context.Context, userID, eventID, inviteID shared.ID) error {
+	if _, err := s.Authorize(ctx, eventID, userID, events.PermManageRoles); err != nil {
+		return err
+	}
+
+	return s.roleInviteRepo.Delete(ctx, eventID, inviteID)
+}
+
+// AcceptRoleInvite grants the invite's role to the user. Users who already
+// hold the same or a higher role keep it and leave the invite unused.
+func (s *Service) AcceptRoleInvite(ctx context.Context, userID shared.ID, token string) (*events.RoleInvite, error) {
+	invite, err := s.roleInviteRepo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	event, current, err := s.getWithRole(ctx, invite.EventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.OwnerID == userID || (current != "" && !invite.Role.Outranks(current)) {
+		return invite, nil
+	}
+
+	if err := invite.Accept(userID); err != nil {
+		return nil, err
+	}
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		claimed, err := s.roleInviteRepo.MarkUsed(ctx, invite)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return events.ErrRoleInviteInvalid
+		}
+		return s.roleRepo.Create(ctx, invite.EventID, userID, invite.Role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// TransferOwnership hands the event to newOwnerID. The previous owner stays
+// on the team as an organizer.
+func (s *Service) TransferOwnership(ctx context.Context, userID, eventID, newOwnerID shared.ID) (*events.Event, error) {
+	var (
+		event   *events.Event
+		changed bool
+	)
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		var err error
+		event, err = s.eventRepo.Lock(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if event.OwnerID != userID {
+			return events.ErrUnauthorized
+		}
+		if newOwnerID == "" || newOwnerID == userID {
+			return nil
+		}
+
+		if err := s.roleRepo.Create(ctx, eventID, newOwnerID, events.RoleOwner); err != nil {
+			return err
+		}
+		event.TransferOwnership(newOwnerID)
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		changed = true
+		return s.roleRepo.Create(ctx, eventID, userID, events.RoleOrganizer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		s.cache.InvalidateEvent(ctx, eventID)
+	}
+	return event, nil
+}
+
+func (s *Service) getWithRole(ctx context.Context, eventID, userID shared.ID) (*events.Event, events.Role, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	role, err := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return event, role, nil
+}
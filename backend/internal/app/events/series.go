@@ -0,0 +1,522 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	occurrenceHorizon = 90 * 24 * time.Hour
+	maxOccurrences    = 100
+)
+
+func (s *Service) CreateSeries(ctx context.Context, userID, eventID shared.ID, rrule string, until *time.Time) (*events.Series, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.CanUserEdit(event, userID, role) {
+		return nil, events.ErrUnauthorized
+	}
+
+	if event.IsOccurrence() {
+		return nil, events.ErrSeriesExists
+	}
+	if event.StartsAt.IsZero() {
+		return nil, events.ErrSeriesRequiresStart
+	}
+
+	series := &events.Series{
+		ID:        shared.NewID(),
+		EventID:   eventID,
+		RRule:     rrule,
+		Until:     until,
+		Timestamp: shared.NewTimestamp(),
+	}
+
+	if _, err := series.Recurrence(); err != nil {
+		return nil, err
+	}
+
+	original := event.StartsAt
+	event.SeriesID = &series.ID
+	event.OriginalStartsAt = &original
+	event.Timestamp.Touch()
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		if err := s.seriesRepo.Create(ctx, series); err != nil {
+			return err
+		}
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		return s.withSeries(ctx, series.ID, func(ctx context.Context, series *events.Series) error {
+			_, err := s.materializeOccurrences(ctx, series)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.InvalidateEvent(ctx, eventID)
+	return series, nil
+}
+
+// ListOccurrences returns the stored occurrences of the event's series.
+// Reads never materialize; writes to the series and ExtendSeries do.
+func (s *Service) ListOccurrences(ctx context.Context, eventID shared.ID) ([]*events.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.IsOccurrence() {
+		return []*events.Event{event}, nil
+	}
+
+	return s.eventRepo.ListBySeries(ctx, *event.SeriesID)
+}
+
+// ExtendSeries materializes the occurrences that entered the horizon since
+// each open series was last written. The worker runs it periodically.
+func (s *Service) ExtendSeries(ctx context.Context) error {
+	ids, err := s.seriesRepo.ListOpen(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, id := range ids {
+		err := s.withSeries(ctx, id, func(ctx context.Context, series *events.Series) error {
+			_, err := s.materializeOccurrences(ctx, series)
+			return err
+		})
+		if err != nil {
+			log.Printf("extend series %s: %v", id, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("extend series: %d of %d failed", failed, len(ids))
+	}
+	return nil
+}
+
+func (s *Service) ListUpcomingOccurrences(ctx context.Context, eventID shared.ID) ([]*events.Event, error) {
+	occurrences, err := s.ListOccurrences(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []*events.Event
+	for _, occ := range occurrences {
+		if occ.Status == events.StatusPublished && occ.StartsAt.After(now) {
+			result = append(result, occ)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Service) UpdateOccurrences(ctx context.Context, userID, eventID shared.ID, updates *events.Event, scope events.EditScope) error {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if scope == events.ScopeThis {
+		return s.UpdateEvent(ctx, userID, eventID, updates)
+	}
+	if !event.IsOccurrence() {
+		return events.ErrNotSeriesEvent
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.CanUserEdit(event, userID, role) {
+		return events.ErrUnauthorized
+	}
+
+	var delta time.Duration
+	if !updates.StartsAt.IsZero() {
+		delta = updates.StartsAt.Sub(event.StartsAt)
+	}
+
+	var endOffset time.Duration
+	if !updates.EndsAt.IsZero() {
+		endOffset = updates.EndsAt.Sub(event.StartsAt.Add(delta))
+		if endOffset < 0 {
+			return events.ErrInvalidTimeRange
+		}
+	}
+
+	var touched []shared.ID
+	err = s.withSeries(ctx, *event.SeriesID, func(ctx context.Context, series *events.Series) error {
+		occurrences, err := s.materializeOccurrences(ctx, series)
+		if err != nil {
+			return err
+		}
+		targets := selectOccurrences(occurrences, event, scope)
+
+		targetSeries := series
+		if delta != 0 {
+			targetSeries, err = s.shiftSeries(ctx, series, event, scope, delta)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Shifting within a series moves occurrences onto each other's
+		// original starts; go against the shift so each slot is vacated
+		// before it is taken.
+		if delta > 0 {
+			slices.Reverse(targets)
+		}
+		for _, occ := range targets {
+			if err := s.updateOccurrence(ctx, occ, updates, targetSeries, delta, endOffset); err != nil {
+				return err
+			}
+			touched = append(touched, occ.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range touched {
+		s.cache.InvalidateEvent(ctx, id)
+	}
+	return nil
+}
+
+func (s *Service) updateOccurrence(ctx context.Context, occ, updates *events.Event, targetSeries *events.Series, delta, endOffset time.Duration) error {
+	applyEventUpdates(occ, updates)
+
+	if delta != 0 {
+		occ.StartsAt = occ.StartsAt.Add(delta)
+		if !occ.EndsAt.IsZero() {
+			occ.EndsAt = occ.EndsAt.Add(delta)
+		}
+		original := occ.OriginalStartsAt.Add(delta)
+		occ.OriginalStartsAt = &original
+		occ.SeriesID = &targetSeries.ID
+	}
+	if endOffset > 0 {
+		occ.EndsAt = occ.StartsAt.Add(endOffset)
+	}
+
+	if err := occ.ValidateTimeRange(); err != nil {
+		return err
+	}
+	if err := occ.ValidateCapacity(); err != nil {
+		return err
+	}
+	if err := occ.ValidateVisibility(); err != nil {
+		return err
+	}
+
+	occ.Timestamp.Touch()
+	if err := s.eventRepo.Update(ctx, occ); err != nil {
+		return err
+	}
+	return s.syncReminders(ctx, occ)
+}
+
+// shiftSeries moves the recurrence anchor by delta. Moving every occurrence
+// keeps the series and shifts its exclusions; moving "this and following"
+// ends the current series before the edited occurrence and starts a new one
+// anchored at it, as RFC 5545 clients do.
+func (s *Service) shiftSeries(ctx context.Context, series *events.Series, from *events.Event, scope events.EditScope, delta time.Duration) (*events.Series, error) {
+	if scope == events.ScopeAll || from.ID == series.EventID {
+		for i := range series.ExDates {
+			series.ExDates[i] = series.ExDates[i].Add(delta)
+		}
+		series.Timestamp.Touch()
+		return series, s.seriesRepo.Update(ctx, series)
+	}
+
+	rule, err := series.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Count > 0 {
+		master, err := s.eventRepo.GetByID(ctx, series.EventID)
+		if err != nil {
+			return nil, err
+		}
+		loc := eventLocation(master)
+		before := rule.Expand(seriesAnchor(master), loc, from.OriginalStartsAt.Add(-time.Second), 0)
+		rule.Count -= len(before)
+		if rule.Count < 1 {
+			rule.Count = 1
+		}
+	}
+
+	split := &events.Series{
+		ID:        shared.NewID(),
+		EventID:   from.ID,
+		RRule:     rule.String(),
+		Until:     series.Until,
+		Timestamp: shared.NewTimestamp(),
+	}
+	for _, ex := range series.ExDates {
+		if !ex.Before(*from.OriginalStartsAt) {
+			split.ExDates = append(split.ExDates, ex.Add(delta))
+		}
+	}
+
+	if err := s.seriesRepo.Create(ctx, split); err != nil {
+		return nil, err
+	}
+
+	series.EndBefore(*from.OriginalStartsAt)
+	if err := s.seriesRepo.Update(ctx, series); err != nil {
+		return nil, err
+	}
+
+	return split, nil
+}
+
+func (s *Service) CancelOccurrences(ctx context.Context, userID, eventID shared.ID, scope events.EditScope) error {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if !event.IsOccurrence() {
+		if scope != events.ScopeThis {
+			return events.ErrNotSeriesEvent
+		}
+		return s.CancelEvent(ctx, userID, eventID)
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.CanUserPublish(event, userID, role) {
+		return events.ErrUnauthorized
+	}
+
+	var touched []shared.ID
+	err = s.withSeries(ctx, *event.SeriesID, func(ctx context.Context, series *events.Series) error {
+		occurrences, err := s.materializeOccurrences(ctx, series)
+		if err != nil {
+			return err
+		}
+
+		switch scope {
+		case events.ScopeThis:
+			series.Exclude(*event.OriginalStartsAt)
+		case events.ScopeFollowing:
+			series.EndBefore(*event.OriginalStartsAt)
+		case events.ScopeAll:
+			series.EndBefore(*occurrences[0].OriginalStartsAt)
+		}
+
+		if err := s.seriesRepo.Update(ctx, series); err != nil {
+			return err
+		}
+
+		for _, occ := range selectOccurrences(occurrences, event, scope) {
+			if occ.Status == events.StatusCancelled {
+				continue
+			}
+			occ.Cancel()
+			if err := s.eventRepo.Update(ctx, occ); err != nil {
+				return err
+			}
+			if err := s.syncReminders(ctx, occ); err != nil {
+				return err
+			}
+			touched = append(touched, occ.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range touched {
+		s.cache.InvalidateEvent(ctx, id)
+	}
+	return nil
+}
+
+// publishSeries publishes the draft occurrences of a series whose master
+// was just published, and returns the ones it changed.
+func (s *Service) publishSeries(ctx context.Context, master *events.Event) ([]shared.ID, error) {
+	var touched []shared.ID
+	err := s.withSeries(ctx, *master.SeriesID, func(ctx context.Context, series *events.Series) error {
+		if series.EventID != master.ID {
+			return nil
+		}
+
+		occurrences, err := s.materializeOccurrences(ctx, series)
+		if err != nil {
+			return err
+		}
+
+		for _, occ := range occurrences {
+			if occ.ID == master.ID || occ.Status != events.StatusDraft {
+				continue
+			}
+			if err := occ.Publish(); err != nil {
+				return err
+			}
+			if err := s.eventRepo.Update(ctx, occ); err != nil {
+				return err
+			}
+			if err := s.syncReminders(ctx, occ); err != nil {
+				return err
+			}
+			touched = append(touched, occ.ID)
+		}
+		return nil
+	})
+	return touched, err
+}
+
+// materializeOccurrences stores every occurrence inside the horizon as its own
+// event row, so registrations, reminders, check-in and calendar feeds work per
+// occurrence without knowing about recurrence. New rows are cloned from the
+// latest live occurrence so "this and following" edits carry forward. It
+// runs under withSeries; the unique (series_id, original_starts_at) index
+// backs up the lock.
+func (s *Service) materializeOccurrences(ctx context.Context, series *events.Series) ([]*events.Event, error) {
+	master, err := s.eventRepo.GetByID(ctx, series.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.eventRepo.ListBySeries(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]bool, len(existing))
+	var template *events.Event
+	for _, occ := range existing {
+		if occ.OriginalStartsAt != nil {
+			known[occ.OriginalStartsAt.Unix()] = true
+		}
+		if occ.Status != events.StatusCancelled {
+			template = occ
+		}
+	}
+	if template == nil {
+		return existing, nil
+	}
+
+	starts, err := series.Occurrences(seriesAnchor(master), eventLocation(master), time.Now().Add(occurrenceHorizon), maxOccurrences)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles map[shared.ID]events.Role
+	result := existing
+	for _, start := range starts {
+		if known[start.Unix()] {
+			continue
+		}
+
+		if roles == nil {
+			roles, err = s.roleRepo.ListByEvent(ctx, master.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		occ := events.NewOccurrence(template, series.ID, start)
+		created, err := s.eventRepo.CreateOccurrence(ctx, occ)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			continue
+		}
+
+		for userID, role := range roles {
+			if err := s.roleRepo.Create(ctx, occ.ID, userID, role); err != nil {
+				return nil, err
+			}
+		}
+
+		if occ.Status == events.StatusPublished {
+			if err := s.syncReminders(ctx, occ); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, occ)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OriginalStartsAt.Before(*result[j].OriginalStartsAt)
+	})
+
+	return result, nil
+}
+
+// withSeries runs fn in a transaction holding the series row, so edits and
+// materialization of one series take turns.
+func (s *Service) withSeries(ctx context.Context, seriesID shared.ID, fn func(context.Context, *events.Series) error) error {
+	return s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		series, err := s.seriesRepo.Lock(ctx, seriesID)
+		if err != nil {
+			return err
+		}
+		if series == nil {
+			return events.ErrNotSeriesEvent
+		}
+		return fn(ctx, series)
+	})
+}
+
+func selectOccurrences(occurrences []*events.Event, from *events.Event, scope events.EditScope) []*events.Event {
+	switch scope {
+	case events.ScopeAll:
+		return occurrences
+	case events.ScopeFollowing:
+		var result []*events.Event
+		for _, occ := range occurrences {
+			if !occ.OriginalStartsAt.Before(*from.OriginalStartsAt) {
+				result = append(result, occ)
+			}
+		}
+		return result
+	default:
+		for _, occ := range occurrences {
+			if occ.ID == from.ID {
+				return []*events.Event{occ}
+			}
+		}
+		return nil
+	}
+}
+
+func seriesAnchor(master *events.Event) time.Time {
+	if master.OriginalStartsAt != nil {
+		return *master.OriginalStartsAt
+	}
+	return master.StartsAt
+}
+
+func eventLocation(event *events.Event) *time.Location {
+	loc, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -5,12 +5,27 @@ import (
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
 )
 
-func (s *Service) CreateEvent(ctx context.Context, userID shared.ID, title, description string) (*events.Event, error) {
+// CreateEvent creates a draft owned by userID. With orgID set the event is
+// published under that organization, which only its admins may do.
+func (s *Service) CreateEvent(ctx context.Context, userID shared.ID, title, description string, orgID *shared.ID) (*events.Event, error) {
 	event := events.NewEvent(userID, title, description)
 
+	if orgID != nil {
+		role, err := s.orgMembers.GetRole(ctx, *orgID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role != orgs.RoleAdmin {
+			return nil, orgs.ErrNotAdmin
+		}
+		event.OrganizationID = orgID
+	}
+
 	if err := s.eventRepo.Create(ctx, event); err != nil {
 		return nil, err
 	}
@@ -32,7 +47,7 @@ func (s *Service) GetEvent(ctx context.Context, id shared.ID) (*events.Event, er
 		return nil, err
 	}
 
-	if event.Status == events.StatusPublished {
+	if event.IsListed() {
 		s.cache.SetEventPublic(ctx, id, event, 3*time.Minute)
 	}
 
@@ -40,51 +55,43 @@ func (s *Service) GetEvent(ctx context.Context, id shared.ID) (*events.Event, er
 }
 
 func (s *Service) UpdateEvent(ctx context.Context, userID, eventID shared.ID, updates *events.Event) error {
-	event, err := s.eventRepo.GetByID(ctx, eventID)
-	if err != nil {
-		return err
-	}
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		event, err := s.eventRepo.Lock(ctx, eventID)
+		if err != nil {
+			return err
+		}
 
-	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
-	if !events.CanUserEdit(event, userID, role) {
-		return events.ErrUnauthorized
-	}
+		role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+		if !events.CanUserEdit(event, userID, role) {
+			return events.ErrUnauthorized
+		}
 
-	if updates.Title != "" {
-		event.Title = updates.Title
-	}
-	if updates.Description != "" {
-		event.Description = updates.Description
-	}
-	if !updates.StartsAt.IsZero() {
-		event.StartsAt = updates.StartsAt
-	}
-	if !updates.EndsAt.IsZero() {
-		event.EndsAt = updates.EndsAt
-	}
-	if updates.Location != "" {
-		event.Location = updates.Location
-	}
-	if updates.OnlineURL != "" {
-		event.OnlineURL = updates.OnlineURL
-	}
-	if updates.Capacity != 0 {
-		event.Capacity = updates.Capacity
-	}
-	if updates.Visibility != "" {
-		event.Visibility = updates.Visibility
-	}
+		applyEventUpdates(event, updates)
+		if !updates.StartsAt.IsZero() {
+			event.StartsAt = updates.StartsAt
+		}
+		if !updates.EndsAt.IsZero() {
+			event.EndsAt = updates.EndsAt
+		}
 
-	if err := event.ValidateTimeRange(); err != nil {
-		return err
-	}
-	if err := event.ValidateCapacity(); err != nil {
-		return err
-	}
+		if err := event.ValidateTimeRange(); err != nil {
+			return err
+		}
+		if err := event.ValidateCapacity(); err != nil {
+			return err
+		}
+		if err := event.ValidateVisibility(); err != nil {
+			return err
+		}
 
-	event.Timestamp.Touch()
+		event.Timestamp.Touch()
 
-	if err := s.eventRepo.Update(ctx, event); err != nil {
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		return s.syncReminders(ctx, event)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -92,47 +99,69 @@ func (s *Service) UpdateEvent(ctx context.Context, userID, eventID shared.ID, up
 	return nil
 }
 
+// PublishEvent holds the event's row while it reschedules reminders, as
+// do the other changes that sync them, so two of them never both schedule
+// the same reminder.
 func (s *Service) PublishEvent(ctx context.Context, userID, eventID shared.ID) error {
-	event, err := s.eventRepo.GetByID(ctx, eventID)
-	if err != nil {
-		return err
-	}
+	var touched []shared.ID
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		event, err := s.eventRepo.Lock(ctx, eventID)
+		if err != nil {
+			return err
+		}
 
-	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
-	if !events.CanUserPublish(event, userID, role) {
-		return events.ErrUnauthorized
-	}
+		role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+		if !events.CanUserPublish(event, userID, role) {
+			return events.ErrUnauthorized
+		}
 
-	if err := event.Publish(); err != nil {
-		return err
-	}
+		if err := event.Publish(); err != nil {
+			return err
+		}
 
-	if err := s.eventRepo.Update(ctx, event); err != nil {
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		if err := s.syncReminders(ctx, event); err != nil {
+			return err
+		}
+		if !event.IsOccurrence() {
+			return nil
+		}
+		touched, err = s.publishSeries(ctx, event)
 		return err
-	}
-
-	if err := s.scheduleReminders(ctx, event); err != nil {
+	})
+	if err != nil {
 		return err
 	}
 
 	s.cache.InvalidateEvent(ctx, eventID)
+	for _, id := range touched {
+		s.cache.InvalidateEvent(ctx, id)
+	}
 	return nil
 }
 
 func (s *Service) CancelEvent(ctx context.Context, userID, eventID shared.ID) error {
-	event, err := s.eventRepo.GetByID(ctx, eventID)
-	if err != nil {
-		return err
-	}
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		event, err := s.eventRepo.Lock(ctx, eventID)
+		if err != nil {
+			return err
+		}
 
-	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
-	if !events.CanUserPublish(event, userID, role) {
-		return events.ErrUnauthorized
-	}
+		role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+		if !events.CanUserPublish(event, userID, role) {
+			return events.ErrUnauthorized
+		}
 
-	event.Cancel()
+		event.Cancel()
 
-	if err := s.eventRepo.Update(ctx, event); err != nil {
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		return s.syncReminders(ctx, event)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -140,45 +169,23 @@ func (s *Service) CancelEvent(ctx context.Context, userID, eventID shared.ID) er
 	return nil
 }
 
-func (s *Service) scheduleReminders(ctx context.Context, event *events.Event) error {
-	reminders := []time.Duration{
-		24 * time.Hour,
-		3 * time.Hour,
-		30 * time.Minute,
+func applyEventUpdates(event, updates *events.Event) {
+	if updates.Title != "" {
+		event.Title = updates.Title
 	}
-
-	for _, d := range reminders {
-		at := event.StartsAt.Add(-d)
-		if at.After(time.Now()) {
-			_, _ = s.scheduler.ScheduleReminder(ctx, at, map[string]interface{}{
-				"event_id": event.ID,
-				"type":     "reminder",
-				"before":   d.String(),
-			})
-		}
+	if updates.Description != "" {
+		event.Description = updates.Description
 	}
-
-	return nil
-}
-
-func (s *Service) CreateSeries(ctx context.Context, userID, eventID shared.ID, rrule string, until *time.Time) error {
-	event, err := s.eventRepo.GetByID(ctx, eventID)
-	if err != nil {
-		return err
+	if updates.Location != "" {
+		event.Location = updates.Location
 	}
-
-	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
-	if !events.CanUserEdit(event, userID, role) {
-		return events.ErrUnauthorized
+	if updates.OnlineURL != "" {
+		event.OnlineURL = updates.OnlineURL
 	}
-
-	series := &events.Series{
-		ID:        shared.NewID(),
-		EventID:   eventID,
-		RRule:     rrule,
-		Until:     until,
-		Timestamp: shared.NewTimestamp(),
+	if updates.Capacity != 0 {
+		event.Capacity = updates.Capacity
+	}
+	if updates.Visibility != "" {
+		event.Visibility = updates.Visibility
 	}
-
-	return s.seriesRepo.Create(ctx, series)
 }
@@ -0,0 +1,197 @@
+package forms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+const (
+	fileURLTTL = 15 * time.Minute
+	// unattachedTTL is how long an upload may wait to be referenced by a
+	// draft or a submitted response before it is deleted.
+	unattachedTTL    = 24 * time.Hour
+	cleanupBatchSize = 500
+)
+
+// Upload is a file sent for a file field. Size must be the exact length
+// of Body.
+type Upload struct {
+	FieldID     string
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// UploadFile stores a file for one of the form's file fields after
+// checking the field's size and type limits. The returned ID is what the
+// answer to that field should contain.
+func (s *Service) UploadFile(ctx context.Context, formID, userID shared.ID, up Upload) (*forms.File, error) {
+	form, err := s.formRepo.GetByID(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.Active {
+		return nil, forms.ErrFormInactive
+	}
+
+	schema, err := forms.ParseSchema(form.Schema)
+	if err != nil {
+		return nil, err
+	}
+	field, ok := schema.Field(up.FieldID)
+	if !ok {
+		return nil, forms.ErrNotFileField
+	}
+	if err := field.CheckUpload(up.ContentType, up.Size); err != nil {
+		return nil, err
+	}
+
+	file := forms.NewFile(form, userID, up.FieldID, up.Name, up.ContentType, up.Size)
+	if err := s.storage.Upload(ctx, file.StorageKey, up.Body, up.ContentType); err != nil {
+		return nil, err
+	}
+	if err := s.fileRepo.Create(ctx, file); err != nil {
+		_ = s.storage.Delete(ctx, file.StorageKey)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (s *Service) GetFile(ctx context.Context, fileID shared.ID) (*forms.File, error) {
+	return s.fileRepo.GetByID(ctx, fileID)
+}
+
+// FileURL returns a short-lived download link for the file.
+func (s *Service) FileURL(ctx context.Context, file *forms.File) (string, time.Time, error) {
+	url, err := s.storage.GetPresignedURL(ctx, file.StorageKey, fileURLTTL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return url, time.Now().Add(fileURLTTL), nil
+}
+
+// DeleteResponse withdraws the user's response to the form, including a
+// draft, and deletes the files attached to it.
+func (s *Service) DeleteResponse(ctx context.Context, formID, userID shared.ID) error {
+	response, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.fileRepo.ListByResponse(ctx, response.ID)
+	if err != nil {
+		return err
+	}
+	// Objects go first: if that fails the response is still there and the
+	// call can be retried, since deleting a missing object succeeds.
+	for _, file := range files {
+		if err := s.storage.Delete(ctx, file.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	if err := s.responseRepo.Delete(ctx, response.ID); err != nil {
+		return err
+	}
+	s.cache.InvalidateDraft(ctx, formID, userID)
+	return nil
+}
+
+// resolveFiles checks that every referenced file was uploaded by the user
+// for the same field of this event's form. It returns the usable IDs and a
+// *forms.ValidationError for the rest.
+func (s *Service) resolveFiles(ctx context.Context, form *forms.Form, userID shared.ID, refs map[string]shared.ID) ([]shared.ID, error) {
+	var ids []shared.ID
+	verr := &forms.ValidationError{}
+
+	for fieldID, fileID := range refs {
+		file, err := s.fileRepo.GetByID(ctx, fileID)
+		if err != nil && !errors.Is(err, forms.ErrFileNotFound) {
+			return nil, err
+		}
+		if file == nil || file.UserID != userID || file.EventID != form.EventID || file.FieldID != fieldID {
+			verr.Fields = append(verr.Fields, forms.FieldError{
+				Field:   fieldID,
+				Code:    forms.CodeFile,
+				Message: "must reference a file uploaded for this field",
+			})
+			continue
+		}
+		ids = append(ids, file.ID)
+	}
+
+	if len(verr.Fields) > 0 {
+		sort.Slice(verr.Fields, func(i, j int) bool { return verr.Fields[i].Field < verr.Fields[j].Field })
+		return ids, verr
+	}
+	return ids, nil
+}
+
+// syncFiles attaches the referenced files to the response and deletes the
+// ones it no longer references, such as a replaced upload.
+func (s *Service) syncFiles(ctx context.Context, responseID shared.ID, keep []shared.ID) error {
+	if err := s.fileRepo.LinkToResponse(ctx, keep, responseID); err != nil {
+		return err
+	}
+
+	linked, err := s.fileRepo.ListByResponse(ctx, responseID)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[shared.ID]bool, len(keep))
+	for _, id := range keep {
+		kept[id] = true
+	}
+	for _, file := range linked {
+		if kept[file.ID] {
+			continue
+		}
+		if err := s.storage.Delete(ctx, file.StorageKey); err != nil {
+			return err
+		}
+		if err := s.fileRepo.Delete(ctx, file.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CleanupFiles deletes uploads that were never attached to a response,
+// such as files picked and then abandoned with the form. Objects go first,
+// so a failed delete leaves the row for the next run.
+func (s *Service) CleanupFiles(ctx context.Context) error {
+	files, err := s.fileRepo.ListUnattached(ctx, time.Now().Add(-unattachedTTL), cleanupBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, file := range files {
+		if err := s.storage.Delete(ctx, file.StorageKey); err != nil {
+			log.Printf("delete file %s: %v", file.ID, err)
+			failed++
+			continue
+		}
+		if err := s.fileRepo.Delete(ctx, file.ID); err != nil {
+			log.Printf("delete file %s: %v", file.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("cleanup files: %d of %d failed", failed, len(files))
+	}
+	return nil
+}
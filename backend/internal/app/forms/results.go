@@ -0,0 +1,180 @@
+package forms
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Submission is a submitted response joined with its author's
+// registration and check-in.
+type Submission struct {
+	ID                 shared.ID            `json:"id"`
+	UserID             shared.ID            `json:"user_id"`
+	UserName           string               `json:"user_name"`
+	Answers            json.RawMessage      `json:"answers"`
+	Stale              bool                 `json:"stale"`
+	SubmittedAt        time.Time            `json:"submitted_at"`
+	RegistrationStatus registrations.Status `json:"registration_status,omitempty"`
+	CheckedInAt        *time.Time           `json:"checked_in_at,omitempty"`
+}
+
+// SubmissionFilter narrows submissions to exact answer values and,
+// optionally, a registration status. A multiselect answer matches when it
+// contains the value.
+type SubmissionFilter struct {
+	Answers map[string]string
+	Status  registrations.Status
+}
+
+type SubmissionPage struct {
+	Version int           `json:"version"`
+	Total   int           `json:"total"`
+	Items   []*Submission `json:"items"`
+}
+
+// RowWriter receives an export row by row.
+type RowWriter interface {
+	WriteRow(cells []string) error
+}
+
+type Summary struct {
+	Version int                  `json:"version"`
+	Total   int                  `json:"total"`
+	Fields  []forms.FieldSummary `json:"fields"`
+}
+
+// ListSubmissions pages through the submitted responses to a form
+// version, newest first. Version 0 means the active form.
+func (s *Service) ListSubmissions(
+	ctx context.Context,
+	eventID shared.ID,
+	version int,
+	filter SubmissionFilter,
+	limit, offset int,
+) (*SubmissionPage, error) {
+	form, _, err := s.resultsForm(ctx, eventID, version, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	total, err := s.responseRepo.CountSubmissions(ctx, form.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.responseRepo.ListSubmissions(ctx, form.ID, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*Submission{}
+	}
+
+	return &SubmissionPage{Version: form.Version, Total: total, Items: items}, nil
+}
+
+// ExportSubmissions writes a header row and then every matching
+// submission with one column per schema field. Nothing is written when the
+// form can't be found or the filter doesn't fit it.
+func (s *Service) ExportSubmissions(ctx context.Context, eventID shared.ID, version int, filter SubmissionFilter, out RowWriter) error {
+	form, schema, err := s.resultsForm(ctx, eventID, version, filter)
+	if err != nil {
+		return err
+	}
+
+	header := append([]string{
+		"response_id", "user_id", "name", "submitted_at", "stale",
+		"registration_status", "checked_in_at",
+	}, schema.Header()...)
+	if err := out.WriteRow(header); err != nil {
+		return err
+	}
+
+	return s.responseRepo.EachSubmission(ctx, form.ID, filter, func(item *Submission) error {
+		answers, err := forms.DecodeAnswers(item.Answers)
+		if err != nil {
+			return err
+		}
+
+		checkedIn := ""
+		if item.CheckedInAt != nil {
+			checkedIn = item.CheckedInAt.Format(time.RFC3339)
+		}
+
+		return out.WriteRow(append([]string{
+			item.ID.String(), item.UserID.String(), item.UserName,
+			item.SubmittedAt.Format(time.RFC3339), forms.YesNo(item.Stale),
+			string(item.RegistrationStatus), checkedIn,
+		}, schema.Cells(answers)...))
+	})
+}
+
+// SummarizeSubmissions aggregates the matching submissions per question.
+func (s *Service) SummarizeSubmissions(ctx context.Context, eventID shared.ID, version int, filter SubmissionFilter) (*Summary, error) {
+	form, schema, err := s.resultsForm(ctx, eventID, version, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.responseRepo.ListSubmissions(ctx, form.ID, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		answers, err := forms.DecodeAnswers(item.Answers)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, answers)
+	}
+
+	return &Summary{
+		Version: form.Version,
+		Total:   len(items),
+		Fields:  schema.Summarize(responses),
+	}, nil
+}
+
+func (s *Service) resultsForm(ctx context.Context, eventID shared.ID, version int, filter SubmissionFilter) (*forms.Form, *forms.Schema, error) {
+	var form *forms.Form
+	var err error
+	if version > 0 {
+		form, err = s.formRepo.GetByVersion(ctx, eventID, version)
+	} else {
+		form, err = s.formRepo.GetActiveByEvent(ctx, eventID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := forms.ParseSchema(form.Schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := schema.CheckFilter(filter.Answers); err != nil {
+		return nil, nil, err
+	}
+
+	return form, schema, nil
+}
@@ -3,16 +3,25 @@ package forms
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/forms"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
 )
 
 type FormRepo interface {
 	Create(ctx context.Context, form *forms.Form) error
 	GetByID(ctx context.Context, id shared.ID) (*forms.Form, error)
 	GetActiveByEvent(ctx context.Context, eventID shared.ID) (*forms.Form, error)
+	GetByVersion(ctx context.Context, eventID shared.ID, version int) (*forms.Form, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*forms.Form, error)
+	NextVersion(ctx context.Context, eventID shared.ID) (int, error)
+	DeactivateByEvent(ctx context.Context, eventID shared.ID) error
 	Update(ctx context.Context, form *forms.Form) error
 }
 
@@ -20,51 +29,307 @@ type ResponseRepo interface {
 	Create(ctx context.Context, response *forms.Response) error
 	GetByID(ctx context.Context, id shared.ID) (*forms.Response, error)
 	GetByFormAndUser(ctx context.Context, formID, userID shared.ID) (*forms.Response, error)
+	GetLatestByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*forms.Response, error)
 	Update(ctx context.Context, response *forms.Response) error
+	CreateDraft(ctx context.Context, response *forms.Response) error
+	UpdateDraft(ctx context.Context, response *forms.Response, base int) error
+	Delete(ctx context.Context, id shared.ID) error
 	ListByForm(ctx context.Context, formID shared.ID) ([]*forms.Response, error)
+	ListSubmissions(ctx context.Context, formID shared.ID, filter SubmissionFilter, limit, offset int) ([]*Submission, error)
+	EachSubmission(ctx context.Context, formID shared.ID, filter SubmissionFilter, fn func(*Submission) error) error
+	CountSubmissions(ctx context.Context, formID shared.ID, filter SubmissionFilter) (int, error)
 }
 
+type FileRepo interface {
+	Create(ctx context.Context, file *forms.File) error
+	GetByID(ctx context.Context, id shared.ID) (*forms.File, error)
+	ListByResponse(ctx context.Context, responseID shared.ID) ([]*forms.File, error)
+	LinkToResponse(ctx context.Context, ids []shared.ID, responseID shared.ID) error
+	MoveToResponse(ctx context.Context, fromID shared.ID, to *forms.Response) error
+	ListUnattached(ctx context.Context, before time.Time, limit int) ([]*forms.File, error)
+	Delete(ctx context.Context, id shared.ID) error
+}
+
+type Storage interface {
+	Upload(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	GetPresignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+type RegistrationLookup interface {
+	GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error)
+}
+
+// Profiles reads and updates the data a user chose to have remembered.
+type Profiles interface {
+	GetProfile(ctx context.Context, userID shared.ID) (map[string]interface{}, error)
+	SaveProfile(ctx context.Context, userID shared.ID, values map[string]interface{}) error
+}
+
+// Completer confirms a registration that was waiting for the form.
+type Completer interface {
+	CompleteForm(ctx context.Context, eventID, userID shared.ID) error
+}
+
+// Cache is a write-through copy of drafts; Postgres stays the source of truth.
 type Cache interface {
-	GetDraft(ctx context.Context, formID, userID shared.ID) (json.RawMessage, bool)
-	SetDraft(ctx context.Context, formID, userID shared.ID, data json.RawMessage, ttl time.Duration)
+	GetDraft(ctx context.Context, formID, userID shared.ID) (*forms.Response, bool)
+	SetDraft(ctx context.Context, draft *forms.Response, ttl time.Duration)
+	InvalidateDraft(ctx context.Context, formID, userID shared.ID)
+}
+
+const draftCacheTTL = 24 * time.Hour
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
 }
 
 type Service struct {
-	formRepo     FormRepo
-	responseRepo ResponseRepo
-	cache        Cache
+	uow           UnitOfWork
+	formRepo      FormRepo
+	responseRepo  ResponseRepo
+	fileRepo      FileRepo
+	storage       Storage
+	registrations RegistrationLookup
+	profiles      Profiles
+	completer     Completer
+	cache         Cache
 }
 
-func NewService(formRepo FormRepo, responseRepo ResponseRepo, cache Cache) *Service {
+func NewService(
+	uow UnitOfWork,
+	formRepo FormRepo,
+	responseRepo ResponseRepo,
+	fileRepo FileRepo,
+	storage Storage,
+	registrations RegistrationLookup,
+	profiles Profiles,
+	completer Completer,
+	cache Cache,
+) *Service {
 	return &Service{
-		formRepo:     formRepo,
-		responseRepo: responseRepo,
-		cache:        cache,
+		uow:           uow,
+		formRepo:      formRepo,
+		responseRepo:  responseRepo,
+		fileRepo:      fileRepo,
+		storage:       storage,
+		registrations: registrations,
+		profiles:      profiles,
+		completer:     completer,
+		cache:         cache,
 	}
 }
 
-func (s *Service) CreateForm(ctx context.Context, eventID shared.ID, schema, rules json.RawMessage) (*forms.Form, error) {
-	form := forms.NewForm(eventID, schema, rules)
-	if err := s.formRepo.Create(ctx, form); err != nil {
-		return nil, err
+// Migration counts what happened to the previous version's responses when
+// a new form version was created.
+type Migration struct {
+	Migrated int `json:"migrated"`
+	Stale    int `json:"stale"`
+}
+
+// CreateForm publishes a new version of the event's form and retires the
+// previous one. Submitted responses to the previous version are copied to
+// the new one when they still validate, and marked stale otherwise.
+func (s *Service) CreateForm(ctx context.Context, eventID shared.ID, schema, rules json.RawMessage) (*forms.Form, *Migration, error) {
+	parsedSchema, err := forms.ParseSchema(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsedRules, err := forms.ParseRules(rules, parsedSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var form *forms.Form
+	migration := &Migration{}
+	err = s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		previous, err := s.formRepo.GetActiveByEvent(ctx, eventID)
+		if err != nil && !errors.Is(err, forms.ErrFormNotFound) {
+			return err
+		}
+
+		if err := s.formRepo.DeactivateByEvent(ctx, eventID); err != nil {
+			return err
+		}
+		version, err := s.formRepo.NextVersion(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		form = forms.NewForm(eventID, version, schema, rules)
+		if err := s.formRepo.Create(ctx, form); err != nil {
+			return err
+		}
+
+		if previous == nil {
+			return nil
+		}
+		return s.migrateResponses(ctx, previous, form, parsedSchema, parsedRules, migration)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return form, migration, nil
+}
+
+func (s *Service) migrateResponses(
+	ctx context.Context,
+	from, to *forms.Form,
+	schema *forms.Schema,
+	rules []forms.Rule,
+	migration *Migration,
+) error {
+	responses, err := s.responseRepo.ListByForm(ctx, from.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, response := range responses {
+		if response.Status != forms.ResponseStatusSubmitted || response.Stale {
+			continue
+		}
+
+		answers, err := forms.DecodeAnswers(response.Answers)
+		if err != nil {
+			return err
+		}
+		for id := range answers {
+			if _, ok := schema.Field(id); !ok {
+				delete(answers, id)
+			}
+		}
+
+		values, state, err := s.applyRules(ctx, to.EventID, response.UserID, schema, rules, answers)
+		if err != nil {
+			return err
+		}
+
+		if schema.Validate(values, state) != nil {
+			response.MarkStale()
+			if err := s.responseRepo.Update(ctx, response); err != nil {
+				return err
+			}
+			migration.Stale++
+			continue
+		}
+
+		cleaned, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		migrated := response.Migrate(to.ID, cleaned)
+		if err := s.responseRepo.Create(ctx, migrated); err != nil {
+			return err
+		}
+		if err := s.fileRepo.MoveToResponse(ctx, response.ID, migrated); err != nil {
+			return err
+		}
+		migration.Migrated++
 	}
-	return form, nil
+
+	return nil
 }
 
 func (s *Service) GetActiveForm(ctx context.Context, eventID shared.ID) (*forms.Form, error) {
 	return s.formRepo.GetActiveByEvent(ctx, eventID)
 }
 
-func (s *Service) SubmitResponse(ctx context.Context, formID, userID shared.ID, answers json.RawMessage) (*forms.Response, error) {
-	response, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+func (s *Service) GetForm(ctx context.Context, formID shared.ID) (*forms.Form, error) {
+	return s.formRepo.GetByID(ctx, formID)
+}
+
+// Prefill returns answers for the form taken from the user's saved
+// profile data.
+func (s *Service) Prefill(ctx context.Context, form *forms.Form, userID shared.ID) (map[string]interface{}, error) {
+	if userID == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	schema, err := forms.ParseSchema(form.Schema)
 	if err != nil {
+		return nil, err
+	}
+	profile, err := s.profiles.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Prefill(profile), nil
+}
+
+func (s *Service) ListVersions(ctx context.Context, eventID shared.ID) ([]*forms.Form, error) {
+	return s.formRepo.ListByEvent(ctx, eventID)
+}
+
+// DiffVersions compares two versions of the event's form.
+func (s *Service) DiffVersions(ctx context.Context, eventID shared.ID, from, to int) (*forms.SchemaDiff, error) {
+	fromForm, err := s.formRepo.GetByVersion(ctx, eventID, from)
+	if err != nil {
+		return nil, err
+	}
+	toForm, err := s.formRepo.GetByVersion(ctx, eventID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return forms.Diff(fromForm, toForm)
+}
+
+// SubmitResponse applies the form's rules to answers, validates what is
+// left against the schema and stores it. Hidden fields are dropped and
+// computed ones filled in. Invalid answers are reported as a
+// *forms.ValidationError. A seat held for this form is confirmed. With
+// remember set, answers to fields linked to the profile are saved for
+// prefilling later forms.
+func (s *Service) SubmitResponse(ctx context.Context, formID, userID shared.ID, answers json.RawMessage, remember bool) (*forms.Response, error) {
+	form, err := s.formRepo.GetByID(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.Active {
+		return nil, forms.ErrFormInactive
+	}
+
+	schema, values, state, err := s.evaluate(ctx, form, userID, answers)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(values, state); err != nil {
+		return nil, err
+	}
+	files, err := s.resolveFiles(ctx, form, userID, schema.FileAnswers(values))
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+	isNew := errors.Is(err, forms.ErrResponseNotFound)
+	if err != nil && !isNew {
+		return nil, err
+	}
+	if isNew {
 		response = forms.NewResponse(formID, userID)
 	}
+	if response.Status == forms.ResponseStatusDraft && response.PreviousID == nil {
+		previous, err := s.responseRepo.GetLatestByEventAndUser(ctx, form.EventID, userID)
+		if err != nil && !errors.Is(err, forms.ErrResponseNotFound) {
+			return nil, err
+		}
+		if previous != nil {
+			response.PreviousID = &previous.ID
+		}
+	}
 
-	response.Answers = answers
+	response.Answers = cleaned
 	response.Submit()
 
-	if response.Status == forms.ResponseStatusDraft {
+	if isNew {
 		if err := s.responseRepo.Create(ctx, response); err != nil {
 			return nil, err
 		}
@@ -73,15 +338,226 @@ func (s *Service) SubmitResponse(ctx context.Context, formID, userID shared.ID,
 			return nil, err
 		}
 	}
+	s.cache.InvalidateDraft(ctx, formID, userID)
+
+	if err := s.syncFiles(ctx, response.ID, files); err != nil {
+		return nil, err
+	}
+
+	if remember {
+		if err := s.profiles.SaveProfile(ctx, userID, schema.ProfileValues(values, state)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.completer.CompleteForm(ctx, form.EventID, userID); err != nil &&
+		!errors.Is(err, registrations.ErrRegistrationNotFound) {
+		return nil, err
+	}
 
 	return response, nil
 }
 
-func (s *Service) SaveDraft(ctx context.Context, formID, userID shared.ID, data json.RawMessage) error {
-	s.cache.SetDraft(ctx, formID, userID, data, 7*24*time.Hour)
-	return nil
+// SubmitAndRegister submits answers to the event's active form and then
+// runs register, in one transaction, so a registration that fails takes
+// the response and any remembered profile data with it. The answers are
+// validated before anything is written. Submitting first lets an event
+// that requires the form seat the attendee outright instead of holding
+// the seat.
+func (s *Service) SubmitAndRegister(ctx context.Context, eventID, userID shared.ID, answers json.RawMessage, remember bool, register func(context.Context) error) error {
+	return s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		form, err := s.formRepo.GetActiveByEvent(ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.SubmitResponse(ctx, form.ID, userID, answers, remember); err != nil {
+			return err
+		}
+		return register(ctx)
+	})
+}
+
+// Evaluate runs the form's rules on partial answers so clients can show
+// exactly what the server will enforce on submit.
+func (s *Service) Evaluate(ctx context.Context, formID, userID shared.ID, answers json.RawMessage) (*forms.State, error) {
+	form, err := s.formRepo.GetByID(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, state, err := s.evaluate(ctx, form, userID, answers)
+	return state, err
+}
+
+// RuleContext returns the facts about userID that the event's form rules
+// can depend on. Anonymous users and users without a registration get an
+// empty context.
+func (s *Service) RuleContext(ctx context.Context, eventID, userID shared.ID) (forms.Context, error) {
+	if userID == "" {
+		return forms.Context{}, nil
+	}
+
+	reg, err := s.registrations.GetByEventAndUser(ctx, eventID, userID)
+	if errors.Is(err, registrations.ErrRegistrationNotFound) {
+		return forms.Context{}, nil
+	}
+	if err != nil {
+		return forms.Context{}, err
+	}
+
+	rc := forms.Context{RegistrationStatus: string(reg.Status)}
+	if reg.TicketTypeID != nil {
+		rc.TicketType = reg.TicketTypeID.String()
+	}
+	return rc, nil
+}
+
+// evaluate parses the form and applies its rules to raw answers. It returns
+// the answers as they would be stored.
+func (s *Service) evaluate(
+	ctx context.Context,
+	form *forms.Form,
+	userID shared.ID,
+	raw json.RawMessage,
+) (*forms.Schema, map[string]interface{}, *forms.State, error) {
+	schema, err := forms.ParseSchema(form.Schema)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rules, err := forms.ParseRules(form.Rules, schema)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	answers, err := forms.DecodeAnswers(raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	values, state, err := s.applyRules(ctx, form.EventID, userID, schema, rules, answers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return schema, values, state, nil
+}
+
+func (s *Service) applyRules(
+	ctx context.Context,
+	eventID, userID shared.ID,
+	schema *forms.Schema,
+	rules []forms.Rule,
+	answers map[string]interface{},
+) (map[string]interface{}, *forms.State, error) {
+	rc, err := s.RuleContext(ctx, eventID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state := forms.Evaluate(schema, rules, answers, rc)
+	return state.Apply(answers), state, nil
+}
+
+// SaveDraft autosaves the user's unsubmitted answers. base is the draft
+// revision the client last saw; when someone saved since, the save fails
+// with a *forms.DraftConflictError holding the newer draft. A nil base
+// overwrites unconditionally.
+func (s *Service) SaveDraft(ctx context.Context, formID, userID shared.ID, data json.RawMessage, base *int) (*forms.Response, error) {
+	form, err := s.formRepo.GetByID(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.Active {
+		return nil, forms.ErrFormInactive
+	}
+
+	answers, err := forms.DecodeAnswers(data)
+	if err != nil {
+		return nil, err
+	}
+	if answers == nil {
+		answers = make(map[string]interface{})
+	}
+	cleaned, err := json.Marshal(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := forms.ParseSchema(form.Schema)
+	if err != nil {
+		return nil, err
+	}
+	// Drafts may be incomplete, so files that fail the checks are simply
+	// left unlinked until the user fixes them.
+	files, err := s.resolveFiles(ctx, form, userID, schema.FileAnswers(answers))
+	var verr *forms.ValidationError
+	if err != nil && !errors.As(err, &verr) {
+		return nil, err
+	}
+
+	draft, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+	isNew := errors.Is(err, forms.ErrResponseNotFound)
+	if err != nil && !isNew {
+		return nil, err
+	}
+	if isNew {
+		draft = forms.NewResponse(formID, userID)
+	}
+
+	current := draft.Revision
+	if err := draft.SaveDraft(cleaned, base); err != nil {
+		return nil, err
+	}
+
+	if isNew {
+		err = s.responseRepo.CreateDraft(ctx, draft)
+	} else {
+		err = s.responseRepo.UpdateDraft(ctx, draft, current)
+	}
+	if errors.Is(err, forms.ErrDraftConflict) {
+		return nil, s.draftConflict(ctx, formID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncFiles(ctx, draft.ID, files); err != nil {
+		return nil, err
+	}
+
+	s.cache.SetDraft(ctx, draft, draftCacheTTL)
+	return draft, nil
+}
+
+// GetDraft returns the user's unsubmitted answers, or forms.ErrResponseNotFound
+// when there are none.
+func (s *Service) GetDraft(ctx context.Context, formID, userID shared.ID) (*forms.Response, error) {
+	if draft, ok := s.cache.GetDraft(ctx, formID, userID); ok {
+		return draft, nil
+	}
+
+	draft, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != forms.ResponseStatusDraft {
+		return nil, forms.ErrResponseNotFound
+	}
+
+	s.cache.SetDraft(ctx, draft, draftCacheTTL)
+	return draft, nil
 }
 
-func (s *Service) GetDraft(ctx context.Context, formID, userID shared.ID) (json.RawMessage, bool) {
-	return s.cache.GetDraft(ctx, formID, userID)
+// draftConflict reports a lost race against another client with the draft
+// that won it.
+func (s *Service) draftConflict(ctx context.Context, formID, userID shared.ID) error {
+	s.cache.InvalidateDraft(ctx, formID, userID)
+
+	current, err := s.responseRepo.GetByFormAndUser(ctx, formID, userID)
+	if err != nil {
+		return err
+	}
+	if current.Status == forms.ResponseStatusSubmitted {
+		return forms.ErrAlreadySubmitted
+	}
+	return &forms.DraftConflictError{Current: current}
 }
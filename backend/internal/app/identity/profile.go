@@ -0,0 +1,121 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// Profile keys backed by user columns. Other keys live in SavedFields.
+const (
+	ProfileName  = "name"
+	ProfileEmail = "email"
+	ProfilePhone = "phone"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// Profile returns what forms can be prefilled with, keyed by profile key.
+// A remembered name wins over the display name from the messenger.
+func (u *User) Profile() map[string]interface{} {
+	profile := make(map[string]interface{}, len(u.SavedFields)+3)
+	if u.DisplayName != "" {
+		profile[ProfileName] = u.DisplayName
+	}
+	for k, v := range u.SavedFields {
+		profile[k] = v
+	}
+	if u.Email != "" {
+		profile[ProfileEmail] = u.Email
+	}
+	if u.Phone != "" {
+		profile[ProfilePhone] = u.Phone
+	}
+	return profile
+}
+
+// Remember stores form answers the user chose to keep.
+func (u *User) Remember(values map[string]interface{}) {
+	if u.SavedFields == nil {
+		u.SavedFields = make(map[string]interface{})
+	}
+	for k, v := range values {
+		switch k {
+		case ProfileEmail:
+			u.Email = fmt.Sprint(v)
+		case ProfilePhone:
+			u.Phone = fmt.Sprint(v)
+		default:
+			u.SavedFields[k] = v
+		}
+	}
+	u.Timestamp.Touch()
+}
+
+// Forget clears the given saved keys, or all saved data when keys is empty.
+// The display name comes from the messenger and is kept.
+func (u *User) Forget(keys []string) {
+	if len(keys) == 0 {
+		u.SavedFields = make(map[string]interface{})
+		u.Email, u.Phone = "", ""
+	}
+	for _, k := range keys {
+		switch k {
+		case ProfileEmail:
+			u.Email = ""
+		case ProfilePhone:
+			u.Phone = ""
+		default:
+			delete(u.SavedFields, k)
+		}
+	}
+	u.Timestamp.Touch()
+}
+
+func (s *Service) GetProfile(ctx context.Context, userID shared.ID) (map[string]interface{}, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.Profile(), nil
+}
+
+func (s *Service) SaveProfile(ctx context.Context, userID shared.ID, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.Remember(values)
+	return s.repo.Update(ctx, user)
+}
+
+func (s *Service) ClearSavedData(ctx context.Context, userID shared.ID, keys []string) (*User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Forget(keys)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *Service) getUser(ctx context.Context, userID shared.ID) (*User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
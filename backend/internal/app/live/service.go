@@ -0,0 +1,76 @@
+package live
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// publishTimeout bounds a publish that outlives the request which caused
+// it; the change is already committed by then.
+const publishTimeout = 5 * time.Second
+
+// Broker fans updates out to every process streaming the event, whichever
+// process made the change.
+type Broker interface {
+	PublishLive(ctx context.Context, update *live.Update) error
+	// SubscribeLive delivers the event's updates until ctx is done, then
+	// closes the channel.
+	SubscribeLive(ctx context.Context, eventID shared.ID) (<-chan *live.Update, error)
+}
+
+type CountsRepo interface {
+	Counts(ctx context.Context, eventID shared.ID) (*live.Counts, error)
+}
+
+type Service struct {
+	broker Broker
+	counts CountsRepo
+}
+
+func NewService(broker Broker, counts CountsRepo) *Service {
+	return &Service{broker: broker, counts: counts}
+}
+
+// Publish sends committed changes at one event, each carrying the counts
+// taken after them. The dashboard is best effort: failures are logged and
+// never undo or fail the change itself.
+func (s *Service) Publish(ctx context.Context, eventID shared.ID, updates ...*live.Update) {
+	if len(updates) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+
+	counts, err := s.counts.Counts(ctx, eventID)
+	if err != nil {
+		log.Printf("live: counts for event %s: %v", eventID, err)
+	}
+	for _, u := range updates {
+		u.Counts = counts
+		if err := s.broker.PublishLive(ctx, u); err != nil {
+			log.Printf("live: publish %s for event %s: %v", u.Kind, eventID, err)
+			return
+		}
+	}
+}
+
+// Snapshot is the first message of a stream.
+func (s *Service) Snapshot(ctx context.Context, eventID shared.ID) (*live.Update, error) {
+	counts, err := s.counts.Counts(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	u := live.NewUpdate(live.KindSnapshot, eventID)
+	u.Counts = counts
+	return u, nil
+}
+
+// Subscribe streams the event's updates until ctx is done. Subscribe
+// before taking the snapshot so that nothing falls between the two.
+func (s *Service) Subscribe(ctx context.Context, eventID shared.ID) (<-chan *live.Update, error) {
+	return s.broker.SubscribeLive(ctx, eventID)
+}
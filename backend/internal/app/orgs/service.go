@@ -0,0 +1,412 @@
+package orgs
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/orgs"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
+)
+
+type OrgRepo interface {
+	Create(ctx context.Context, org *orgs.Organization) error
+	Update(ctx context.Context, org *orgs.Organization) error
+	GetByID(ctx context.Context, id shared.ID) (*orgs.Organization, error)
+	GetByInviteToken(ctx context.Context, token string) (*orgs.Organization, error)
+	ListByUser(ctx context.Context, userID shared.ID) ([]*orgs.Organization, error)
+}
+
+type MemberRepo interface {
+	Add(ctx context.Context, member *orgs.Member) error
+	GetRole(ctx context.Context, orgID, userID shared.ID) (orgs.Role, error)
+	ListByOrg(ctx context.Context, orgID shared.ID) ([]*orgs.Member, error)
+	UpdateRole(ctx context.Context, orgID, userID shared.ID, role orgs.Role) error
+	Remove(ctx context.Context, orgID, userID shared.ID) error
+	CountAdmins(ctx context.Context, orgID shared.ID) (int, error)
+	GetFeedToken(ctx context.Context, orgID, userID shared.ID) (string, error)
+	SetFeedToken(ctx context.Context, orgID, userID shared.ID, token string) error
+	GetByFeedToken(ctx context.Context, orgID shared.ID, token string) (shared.ID, error)
+}
+
+type JoinRequestRepo interface {
+	Create(ctx context.Context, req *orgs.JoinRequest) error
+	Update(ctx context.Context, req *orgs.JoinRequest) error
+	GetByID(ctx context.Context, id shared.ID) (*orgs.JoinRequest, error)
+	GetPending(ctx context.Context, orgID, userID shared.ID) (*orgs.JoinRequest, error)
+	ListPending(ctx context.Context, orgID shared.ID) ([]*orgs.JoinRequest, error)
+}
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
+}
+
+type Service struct {
+	uow         UnitOfWork
+	orgRepo     OrgRepo
+	memberRepo  MemberRepo
+	requestRepo JoinRequestRepo
+}
+
+func NewService(uow UnitOfWork, orgRepo OrgRepo, memberRepo MemberRepo, requestRepo JoinRequestRepo) *Service {
+	return &Service{
+		uow:         uow,
+		orgRepo:     orgRepo,
+		memberRepo:  memberRepo,
+		requestRepo: requestRepo,
+	}
+}
+
+// CreateOrganization creates the organization with its creator as the first
+// admin.
+func (s *Service) CreateOrganization(ctx context.Context, userID shared.ID, name, description string) (*orgs.Organization, error) {
+	org, err := orgs.NewOrganization(userID, name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if err := s.orgRepo.Create(ctx, org); err != nil {
+			return err
+		}
+		return s.memberRepo.Add(ctx, orgs.NewMember(org.ID, userID, orgs.RoleAdmin))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (s *Service) GetOrganization(ctx context.Context, orgID shared.ID) (*orgs.Organization, error) {
+	return s.orgRepo.GetByID(ctx, orgID)
+}
+
+func (s *Service) UpdateOrganization(ctx context.Context, userID, orgID shared.ID, name, description string) (*orgs.Organization, error) {
+	org, err := s.getAdministered(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		org.Name = name
+	}
+	org.Description = description
+	org.Timestamp.Touch()
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (s *Service) ListMyOrganizations(ctx context.Context, userID shared.ID) ([]*orgs.Organization, error) {
+	return s.orgRepo.ListByUser(ctx, userID)
+}
+
+func (s *Service) ListMembers(ctx context.Context, userID, orgID shared.ID) ([]*orgs.Member, error) {
+	role, err := s.memberRepo.GetRole(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, orgs.ErrNotMember
+	}
+
+	return s.memberRepo.ListByOrg(ctx, orgID)
+}
+
+func (s *Service) UpdateMemberRole(ctx context.Context, userID, orgID, memberID shared.ID, role orgs.Role) error {
+	if !role.Valid() {
+		return orgs.ErrInvalidRole
+	}
+	if _, err := s.getAdministered(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	return s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		current, err := s.memberRepo.GetRole(ctx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return orgs.ErrNotMember
+		}
+		if current == orgs.RoleAdmin && role != orgs.RoleAdmin {
+			if err := s.checkNotLastAdmin(ctx, orgID); err != nil {
+				return err
+			}
+		}
+
+		return s.memberRepo.UpdateRole(ctx, orgID, memberID, role)
+	})
+}
+
+// RemoveMember removes a member. Admins may remove anyone; members may only
+// leave themselves.
+func (s *Service) RemoveMember(ctx context.Context, userID, orgID, memberID shared.ID) error {
+	if userID != memberID {
+		if _, err := s.getAdministered(ctx, userID, orgID); err != nil {
+			return err
+		}
+	}
+
+	return s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		current, err := s.memberRepo.GetRole(ctx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return orgs.ErrNotMember
+		}
+		if current == orgs.RoleAdmin {
+			if err := s.checkNotLastAdmin(ctx, orgID); err != nil {
+				return err
+			}
+		}
+
+		return s.memberRepo.Remove(ctx, orgID, memberID)
+	})
+}
+
+// RequestToJoin files a join request. Asking again while a request is open
+// returns the existing one.
+func (s *Service) RequestToJoin(ctx context.Context, userID, orgID shared.ID, message string) (*orgs.JoinRequest, error) {
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	role, err := s.memberRepo.GetRole(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, orgs.ErrAlreadyMember
+	}
+
+	existing, err := s.requestRepo.GetPending(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	req := orgs.NewJoinRequest(orgID, userID, message)
+	if err := s.requestRepo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (s *Service) ListJoinRequests(ctx context.Context, userID, orgID shared.ID) ([]*orgs.JoinRequest, error) {
+	if _, err := s.getAdministered(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.requestRepo.ListPending(ctx, orgID)
+}
+
+func (s *Service) ApproveJoinRequest(ctx context.Context, userID, orgID, requestID shared.ID) (*orgs.JoinRequest, error) {
+	return s.decideJoinRequest(ctx, userID, orgID, requestID, true)
+}
+
+func (s *Service) RejectJoinRequest(ctx context.Context, userID, orgID, requestID shared.ID) (*orgs.JoinRequest, error) {
+	return s.decideJoinRequest(ctx, userID, orgID, requestID, false)
+}
+
+func (s *Service) decideJoinRequest(ctx context.Context, userID, orgID, requestID shared.ID, approve bool) (*orgs.JoinRequest, error) {
+	if _, err := s.getAdministered(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	req, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req.OrgID != orgID {
+		return nil, orgs.ErrJoinRequestNotFound
+	}
+
+	if err := req.Decide(userID, approve); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if err := s.requestRepo.Update(ctx, req); err != nil {
+			return err
+		}
+		if !approve {
+			return nil
+		}
+		return s.addMember(ctx, orgID, req.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// GetInviteLink returns the current invite token, creating one on first use.
+func (s *Service) GetInviteLink(ctx context.Context, userID, orgID shared.ID) (string, error) {
+	org, err := s.getAdministered(ctx, userID, orgID)
+	if err != nil {
+		return "", err
+	}
+
+	if org.InviteToken != "" {
+		return org.InviteToken, nil
+	}
+
+	return s.rotateInviteToken(ctx, org)
+}
+
+func (s *Service) RotateInviteLink(ctx context.Context, userID, orgID shared.ID) (string, error) {
+	org, err := s.getAdministered(ctx, userID, orgID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.rotateInviteToken(ctx, org)
+}
+
+func (s *Service) RevokeInviteLink(ctx context.Context, userID, orgID shared.ID) error {
+	org, err := s.getAdministered(ctx, userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	org.RevokeInviteToken()
+	return s.orgRepo.Update(ctx, org)
+}
+
+func (s *Service) rotateInviteToken(ctx context.Context, org *orgs.Organization) (string, error) {
+	if err := org.RotateInviteToken(); err != nil {
+		return "", err
+	}
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return "", err
+	}
+
+	return org.InviteToken, nil
+}
+
+// GetFeedToken returns the member's token for the organization's calendar
+// feed, creating one on first use.
+func (s *Service) GetFeedToken(ctx context.Context, userID, orgID shared.ID) (string, error) {
+	token, err := s.memberRepo.GetFeedToken(ctx, orgID, userID)
+	if err != nil || token != "" {
+		return token, err
+	}
+
+	return s.RotateFeedToken(ctx, userID, orgID)
+}
+
+// RotateFeedToken replaces the member's feed token, cutting off calendars
+// subscribed with the old link.
+func (s *Service) RotateFeedToken(ctx context.Context, userID, orgID shared.ID) (string, error) {
+	if _, err := s.memberRepo.GetFeedToken(ctx, orgID, userID); err != nil {
+		return "", err
+	}
+
+	token, err := orgs.NewFeedToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.memberRepo.SetFeedToken(ctx, orgID, userID, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// FeedMember resolves a feed token to the member it was issued to.
+func (s *Service) FeedMember(ctx context.Context, orgID shared.ID, token string) (shared.ID, error) {
+	if token == "" {
+		return "", orgs.ErrInvalidFeedToken
+	}
+	return s.memberRepo.GetByFeedToken(ctx, orgID, token)
+}
+
+// JoinByInvite adds the user as a member of the organization the token
+// belongs to. Joining an organization one already belongs to is a no-op.
+func (s *Service) JoinByInvite(ctx context.Context, userID shared.ID, token string) (*orgs.Organization, error) {
+	if token == "" {
+		return nil, orgs.ErrInvalidInvite
+	}
+
+	org, err := s.orgRepo.GetByInviteToken(ctx, token)
+	if err == orgs.ErrOrgNotFound {
+		return nil, orgs.ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.memberRepo.GetRole(ctx, org.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return org, nil
+	}
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		return s.addMember(ctx, org.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// addMember adds the user as a plain member and closes any join request they
+// still have open.
+func (s *Service) addMember(ctx context.Context, orgID, userID shared.ID) error {
+	if err := s.memberRepo.Add(ctx, orgs.NewMember(orgID, userID, orgs.RoleMember)); err != nil {
+		return err
+	}
+
+	pending, err := s.requestRepo.GetPending(ctx, orgID, userID)
+	if err != nil || pending == nil {
+		return err
+	}
+	if err := pending.Decide(userID, true); err != nil {
+		return err
+	}
+
+	return s.requestRepo.Update(ctx, pending)
+}
+
+func (s *Service) checkNotLastAdmin(ctx context.Context, orgID shared.ID) error {
+	admins, err := s.memberRepo.CountAdmins(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return orgs.ErrLastAdmin
+	}
+
+	return nil
+}
+
+func (s *Service) getAdministered(ctx context.Context, userID, orgID shared.ID) (*orgs.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.memberRepo.GetRole(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != orgs.RoleAdmin {
+		return nil, orgs.ErrNotAdmin
+	}
+
+	return org, nil
+}
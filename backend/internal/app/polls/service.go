@@ -60,6 +60,14 @@ func (s *Service) Vote(ctx context.Context, pollID, userID shared.ID, optionKey
 	return s.voteRepo.Create(ctx, vote)
 }
 
+func (s *Service) GetPollEventID(ctx context.Context, pollID shared.ID) (shared.ID, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return "", err
+	}
+	return poll.EventID, nil
+}
+
 func (s *Service) GetResults(ctx context.Context, pollID shared.ID) (map[string]int, error) {
 	return s.voteRepo.CountByOption(ctx, pollID)
 }
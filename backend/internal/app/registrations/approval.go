@@ -0,0 +1,163 @@
+package registrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+// ApproveRegistrations accepts pending applicants. Each approved applicant
+// takes a seat or, when the event is full, a waitlist place. The batch is
+// all-or-nothing: one applicant that is not pending, or that does not fit
+// into an event without a waitlist, fails the whole call.
+func (s *Service) ApproveRegistrations(ctx context.Context, organizerID, eventID shared.ID, userIDs []shared.ID, reason string) ([]*registrations.Registration, error) {
+	event, err := s.checkOrganizer(ctx, eventID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var decided []*registrations.Registration
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		decided = nil
+
+		capacity, waitlist, err := s.eventRepo.LockCapacity(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+			if err != nil {
+				return err
+			}
+			if err := reg.Approve(organizerID, reason); err != nil {
+				return err
+			}
+
+			ticketType, err := s.ticketTypeOf(ctx, reg)
+			if err != nil {
+				return err
+			}
+			if err := s.seat(ctx, reg, capacity, waitlist, ticketType); err != nil {
+				return err
+			}
+			held, err := s.holdForForm(ctx, event, reg)
+			if err != nil {
+				return err
+			}
+			if err := s.regRepo.Update(ctx, reg); err != nil {
+				return err
+			}
+			if held {
+				if err := s.scheduler.ScheduleFormHoldExpiry(ctx, reg); err != nil {
+					return fmt.Errorf("schedule form hold expiry: %w", err)
+				}
+			}
+
+			decided = append(decided, reg)
+		}
+
+		return s.notifyDecisions(ctx, decided, event.Title)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishDecisions(ctx, eventID, decided)
+	return decided, nil
+}
+
+// RejectRegistrations refuses pending applicants with the same
+// all-or-nothing semantics as ApproveRegistrations.
+func (s *Service) RejectRegistrations(ctx context.Context, organizerID, eventID shared.ID, userIDs []shared.ID, reason string) ([]*registrations.Registration, error) {
+	event, err := s.checkOrganizer(ctx, eventID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var decided []*registrations.Registration
+
+	err = s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		decided = nil
+
+		for _, userID := range userIDs {
+			reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+			if err != nil {
+				return err
+			}
+			if err := reg.Reject(organizerID, reason); err != nil {
+				return err
+			}
+			if err := s.regRepo.Update(ctx, reg); err != nil {
+				return err
+			}
+
+			decided = append(decided, reg)
+		}
+
+		return s.notifyDecisions(ctx, decided, event.Title)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishDecisions(ctx, eventID, decided)
+	return decided, nil
+}
+
+func (s *Service) ListRegistrations(ctx context.Context, organizerID, eventID shared.ID, statuses []registrations.Status) ([]*registrations.Registration, error) {
+	if _, err := s.checkOrganizer(ctx, eventID, organizerID); err != nil {
+		return nil, err
+	}
+
+	if len(statuses) == 0 {
+		statuses = []registrations.Status{
+			registrations.StatusGoing,
+			registrations.StatusMaybe,
+			registrations.StatusNotGoing,
+			registrations.StatusWaitlist,
+			registrations.StatusPendingForm,
+			registrations.StatusPending,
+			registrations.StatusRejected,
+		}
+	}
+
+	return s.regRepo.ListByEvent(ctx, eventID, statuses)
+}
+
+func (s *Service) publishDecisions(ctx context.Context, eventID shared.ID, decided []*registrations.Registration) {
+	updates := make([]*live.Update, len(decided))
+	for i, reg := range decided {
+		updates[i] = liveUpdate(live.KindRegistration, reg, registrations.StatusPending)
+	}
+	s.live.Publish(ctx, eventID, updates...)
+}
+
+func (s *Service) notifyDecisions(ctx context.Context, decided []*registrations.Registration, eventTitle string) error {
+	for _, reg := range decided {
+		if err := s.scheduler.ScheduleDecisionNotification(ctx, reg, eventTitle); err != nil {
+			return fmt.Errorf("schedule decision notification: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) checkOrganizer(ctx context.Context, eventID, userID shared.ID) (*events.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.HasPermission(event, userID, role, events.PermManageRegistrations) {
+		return nil, events.ErrUnauthorized
+	}
+
+	return event, nil
+}
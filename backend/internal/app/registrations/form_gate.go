@@ -0,0 +1,135 @@
+package registrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/jackc/pgx/v5"
+)
+
+// holdForForm turns a fresh going seat into a pending_form hold when the
+// event requires its form and the attendee has not submitted it yet. It
+// reports whether the seat is held; the caller persists reg.
+func (s *Service) holdForForm(ctx context.Context, event *events.Event, reg *registrations.Registration) (bool, error) {
+	if reg.Status != registrations.StatusGoing || !event.RequiresForm() {
+		return false, nil
+	}
+
+	done, err := s.forms.HasCompletedForm(ctx, reg.EventID, reg.UserID)
+	if err != nil {
+		return false, err
+	}
+	if done {
+		return false, nil
+	}
+
+	reg.HoldForForm(event.FormHold())
+	return true, nil
+}
+
+// scheduleHoldExpiry schedules the release of a held seat. It is called in
+// the transaction that holds the seat; should that roll back, the expiry
+// finds no hold and does nothing.
+func (s *Service) scheduleHoldExpiry(ctx context.Context, reg *registrations.Registration) error {
+	if err := s.scheduler.ScheduleFormHoldExpiry(ctx, reg); err != nil {
+		return fmt.Errorf("schedule form hold expiry: %w", err)
+	}
+	return nil
+}
+
+// notifyFormHolds sends the attendees whose seats were held a link to the
+// form. It runs once the holds are committed, so nobody hears about a seat
+// that was rolled back. The seats stand either way, so failures are only
+// logged.
+func (s *Service) notifyFormHolds(ctx context.Context, held []*registrations.Registration, eventTitle string) {
+	for _, reg := range held {
+		if err := s.scheduler.ScheduleDecisionNotification(ctx, reg, eventTitle); err != nil {
+			log.Printf("schedule form hold notification for %s at event %s: %v", reg.UserID, reg.EventID, err)
+		}
+	}
+}
+
+// CompleteForm confirms a held seat once the attendee has submitted the
+// event's form. Registrations in any other state are left alone.
+func (s *Service) CompleteForm(ctx context.Context, eventID, userID shared.ID) error {
+	var confirmed *registrations.Registration
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		confirmed = nil
+
+		if _, _, err := s.eventRepo.LockCapacity(ctx, eventID); err != nil {
+			return err
+		}
+
+		reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+		if reg.Status != registrations.StatusPendingForm {
+			return nil
+		}
+
+		reg.UpdateRSVP(registrations.StatusGoing)
+		if err := s.regRepo.Update(ctx, reg); err != nil {
+			return err
+		}
+		confirmed = reg
+		return nil
+	})
+	if err != nil || confirmed == nil {
+		return err
+	}
+
+	s.live.Publish(ctx, eventID, liveUpdate(live.KindRSVP, confirmed, registrations.StatusPendingForm))
+	return nil
+}
+
+// ExpireFormHold releases the seat of an attendee who did not submit the
+// form in time and offers it to the waitlist. Holds that were completed,
+// cancelled or renewed in the meantime are left alone.
+func (s *Service) ExpireFormHold(ctx context.Context, eventID, userID shared.ID) error {
+	var released *registrations.Registration
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		released = nil
+
+		if _, _, err := s.eventRepo.LockCapacity(ctx, eventID); err != nil {
+			return err
+		}
+
+		reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+		if errors.Is(err, registrations.ErrRegistrationNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !reg.HoldExpired(time.Now()) {
+			return nil
+		}
+
+		reg.UpdateRSVP(registrations.StatusNotGoing)
+		if err := s.regRepo.Update(ctx, reg); err != nil {
+			return err
+		}
+
+		if err := s.scheduler.ScheduleWaitlistPromotion(ctx, eventID); err != nil {
+			return fmt.Errorf("schedule waitlist promotion: %w", err)
+		}
+		released = reg
+		return nil
+	})
+	if err != nil || released == nil {
+		return err
+	}
+
+	s.live.Publish(ctx, eventID, liveUpdate(live.KindRSVP, released, registrations.StatusPendingForm))
+	return nil
+}
@@ -3,126 +3,566 @@ package registrations
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/live"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/registrations"
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/tickets"
+	"github.com/Alexander-D-Karpov/kvorum/internal/uow"
+	"github.com/jackc/pgx/v5"
 )
 
 type RegistrationRepo interface {
 	Create(ctx context.Context, reg *registrations.Registration) error
 	GetByEventAndUser(ctx context.Context, eventID, userID shared.ID) (*registrations.Registration, error)
 	Update(ctx context.Context, reg *registrations.Registration) error
-	CountByEvent(ctx context.Context, eventID shared.ID, status registrations.Status) (int, error)
+	CountSeats(ctx context.Context, eventID shared.ID, ticketTypeID *shared.ID, statuses []registrations.Status) (int, error)
 	ListByEvent(ctx context.Context, eventID shared.ID, statuses []registrations.Status) ([]*registrations.Registration, error)
 	Delete(ctx context.Context, eventID, userID shared.ID) error
 }
 
 type WaitlistRepo interface {
 	Create(ctx context.Context, entry *registrations.Waitlist) error
-	GetNextByEvent(ctx context.Context, eventID shared.ID) (*registrations.Waitlist, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*registrations.Waitlist, error)
 	Delete(ctx context.Context, id shared.ID) error
+	DeleteByEventAndUser(ctx context.Context, eventID, userID shared.ID) error
 	CountByEvent(ctx context.Context, eventID shared.ID) (int, error)
 }
 
-type EventCapacityChecker interface {
-	GetCapacity(ctx context.Context, eventID shared.ID) (int, error)
+type EventRepo interface {
+	GetByID(ctx context.Context, id shared.ID) (*events.Event, error)
+	// LockCapacity locks the event row for the rest of the transaction,
+	// serializing every seat change for that event.
+	LockCapacity(ctx context.Context, eventID shared.ID) (capacity int, waitlist bool, err error)
+}
+
+type RoleRepo interface {
+	GetUserRole(ctx context.Context, eventID, userID shared.ID) (events.Role, error)
+}
+
+type TicketTypeRepo interface {
+	GetByID(ctx context.Context, id shared.ID) (*tickets.TicketType, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*tickets.TicketType, error)
+}
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn uow.TxFunc) error
+}
+
+type Scheduler interface {
+	ScheduleWaitlistPromotion(ctx context.Context, eventID shared.ID) error
+	ScheduleDecisionNotification(ctx context.Context, reg *registrations.Registration, eventTitle string) error
+	ScheduleFormHoldExpiry(ctx context.Context, reg *registrations.Registration) error
+}
+
+// FormCompletion tells whether an attendee has submitted the event's
+// active form.
+type FormCompletion interface {
+	HasCompletedForm(ctx context.Context, eventID, userID shared.ID) (bool, error)
+}
+
+// LiveFeed tells the event's live dashboard about committed changes.
+type LiveFeed interface {
+	Publish(ctx context.Context, eventID shared.ID, updates ...*live.Update)
 }
 
 type Service struct {
+	uow          UnitOfWork
 	regRepo      RegistrationRepo
 	waitlistRepo WaitlistRepo
-	capacityChk  EventCapacityChecker
+	eventRepo    EventRepo
+	roleRepo     RoleRepo
+	ticketRepo   TicketTypeRepo
+	forms        FormCompletion
+	scheduler    Scheduler
+	live         LiveFeed
+}
+
+// TicketSelection is what the attendee picked. A nil TypeID is fine when the
+// event has no ticket types or exactly one visible type.
+type TicketSelection struct {
+	TypeID     *shared.ID
+	Quantity   int
+	UnlockCode string
 }
 
-func NewService(regRepo RegistrationRepo, waitlistRepo WaitlistRepo, capacityChk EventCapacityChecker) *Service {
+func NewService(
+	uow UnitOfWork,
+	regRepo RegistrationRepo,
+	waitlistRepo WaitlistRepo,
+	eventRepo EventRepo,
+	roleRepo RoleRepo,
+	ticketRepo TicketTypeRepo,
+	forms FormCompletion,
+	scheduler Scheduler,
+	live LiveFeed,
+) *Service {
 	return &Service{
+		uow:          uow,
 		regRepo:      regRepo,
 		waitlistRepo: waitlistRepo,
-		capacityChk:  capacityChk,
+		eventRepo:    eventRepo,
+		roleRepo:     roleRepo,
+		ticketRepo:   ticketRepo,
+		forms:        forms,
+		scheduler:    scheduler,
+		live:         live,
 	}
 }
 
-func (s *Service) Register(ctx context.Context, eventID, userID shared.ID, source string, utm json.RawMessage) (*registrations.Registration, error) {
-	existing, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
-	if err == nil {
-		return existing, registrations.ErrAlreadyRegistered
-	}
+func (s *Service) Register(ctx context.Context, eventID, userID shared.ID, ticket TicketSelection, source string, utm json.RawMessage) (*registrations.Registration, error) {
+	var (
+		result *registrations.Registration
+		event  *events.Event
+		held   bool
+	)
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		held = false
+
+		capacity, waitlist, err := s.eventRepo.LockCapacity(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+		if err == nil {
+			result = existing
+			return registrations.ErrAlreadyRegistered
+		}
+		if !errors.Is(err, registrations.ErrRegistrationNotFound) {
+			return err
+		}
 
-	capacity, err := s.capacityChk.GetCapacity(ctx, eventID)
+		ticketType, err := s.resolveTicket(ctx, eventID, ticket)
+		if err != nil {
+			return err
+		}
+
+		reg := registrations.NewRegistration(eventID, userID, source, utm)
+		if ticketType != nil {
+			reg.TicketTypeID = &ticketType.ID
+		}
+		if ticket.Quantity > 0 {
+			reg.Quantity = ticket.Quantity
+		}
+
+		event, err = s.eventRepo.GetByID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		if event.Visibility == events.VisibilityRequest {
+			reg.Status = registrations.StatusPending
+		} else if err := s.seat(ctx, reg, capacity, waitlist, ticketType); err != nil {
+			return err
+		}
+
+		held, err = s.holdForForm(ctx, event, reg)
+		if err != nil {
+			return err
+		}
+
+		if err := s.regRepo.Create(ctx, reg); err != nil {
+			return err
+		}
+
+		result = reg
+		if held {
+			return s.scheduleHoldExpiry(ctx, reg)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 
-	if capacity > 0 {
-		count, err := s.regRepo.CountByEvent(ctx, eventID, registrations.StatusGoing)
+	if held {
+		s.notifyFormHolds(ctx, []*registrations.Registration{result}, event.Title)
+	}
+	s.live.Publish(ctx, eventID, liveUpdate(live.KindRegistration, result, ""))
+	return result, nil
+}
+
+func (s *Service) RegisterMany(ctx context.Context, eventIDs []shared.ID, userID shared.ID, source string, utm json.RawMessage) ([]*registrations.Registration, error) {
+	result := make([]*registrations.Registration, 0, len(eventIDs))
+	for _, eventID := range eventIDs {
+		reg, err := s.Register(ctx, eventID, userID, TicketSelection{}, source, utm)
+		if errors.Is(err, registrations.ErrAlreadyRegistered) {
+			err = nil
+			if reg == nil {
+				reg, err = s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+			}
+		}
 		if err != nil {
-			return nil, err
+			return result, err
+		}
+		result = append(result, reg)
+	}
+	return result, nil
+}
+
+// UpdateRSVP changes the attendee's answer. Switching to going on a full event
+// puts the attendee on the waitlist instead; the returned registration carries
+// the status that was actually stored.
+func (s *Service) UpdateRSVP(ctx context.Context, eventID, userID shared.ID, status registrations.Status) (*registrations.Registration, error) {
+	if !status.IsRSVP() {
+		return nil, registrations.ErrInvalidStatus
+	}
+
+	var (
+		result    *registrations.Registration
+		oldStatus registrations.Status
+		event     *events.Event
+		held      bool
+	)
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		held = false
+
+		capacity, waitlist, err := s.eventRepo.LockCapacity(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+		if err != nil {
+			return registrations.ErrRegistrationNotFound
 		}
 
-		if count >= capacity {
-			waitlistEntry := registrations.NewWaitlistEntry(eventID, userID)
-			if err := s.waitlistRepo.Create(ctx, waitlistEntry); err != nil {
-				return nil, err
+		if err := reg.CheckEditable(); err != nil {
+			return err
+		}
+
+		oldStatus = reg.Status
+		if oldStatus == status || (oldStatus == registrations.StatusPendingForm && status == registrations.StatusGoing) {
+			result = reg
+			return nil
+		}
+
+		if status == registrations.StatusGoing {
+			ticketType, err := s.ticketTypeOf(ctx, reg)
+			if err != nil {
+				return err
+			}
+			eventRoom, typeRoom, err := s.hasRoom(ctx, eventID, capacity, ticketType, reg.Quantity)
+			if err != nil {
+				return err
+			}
+			if !eventRoom || !typeRoom {
+				if !waitlist {
+					return registrations.ErrCapacityReached
+				}
+				if oldStatus == registrations.StatusWaitlist {
+					result = reg
+					return nil
+				}
+				if err := s.waitlistRepo.Create(ctx, registrations.NewWaitlistEntry(reg)); err != nil {
+					return err
+				}
+				status = registrations.StatusWaitlist
 			}
+		}
 
-			reg := registrations.NewRegistration(eventID, userID, source, utm)
-			reg.Status = registrations.StatusWaitlist
-			if err := s.regRepo.Create(ctx, reg); err != nil {
-				return nil, err
+		if oldStatus == registrations.StatusWaitlist && status != registrations.StatusWaitlist {
+			if err := s.waitlistRepo.DeleteByEventAndUser(ctx, eventID, userID); err != nil {
+				return err
+			}
+		}
+
+		reg.UpdateRSVP(status)
+
+		if status == registrations.StatusGoing {
+			if event, err = s.eventRepo.GetByID(ctx, eventID); err != nil {
+				return err
+			}
+			if held, err = s.holdForForm(ctx, event, reg); err != nil {
+				return err
+			}
+		}
+
+		if err := s.regRepo.Update(ctx, reg); err != nil {
+			return err
+		}
+		if held {
+			if err := s.scheduleHoldExpiry(ctx, reg); err != nil {
+				return err
 			}
-			return reg, nil
 		}
-	}
 
-	reg := registrations.NewRegistration(eventID, userID, source, utm)
-	if err := s.regRepo.Create(ctx, reg); err != nil {
+		if oldStatus.HoldsSeat() && !reg.Status.HoldsSeat() {
+			if err := s.scheduler.ScheduleWaitlistPromotion(ctx, eventID); err != nil {
+				return fmt.Errorf("schedule waitlist promotion: %w", err)
+			}
+		}
+
+		result = reg
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return reg, nil
+	if held {
+		s.notifyFormHolds(ctx, []*registrations.Registration{result}, event.Title)
+	}
+	if result.Status != oldStatus {
+		s.live.Publish(ctx, eventID, liveUpdate(live.KindRSVP, result, oldStatus))
+	}
+	return result, nil
 }
 
-func (s *Service) UpdateRSVP(ctx context.Context, eventID, userID shared.ID, status registrations.Status) error {
-	reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+func (s *Service) CancelRegistration(ctx context.Context, eventID, userID shared.ID) error {
+	var cancelled *registrations.Registration
+
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		cancelled = nil
+
+		if _, _, err := s.eventRepo.LockCapacity(ctx, eventID); err != nil {
+			return err
+		}
+
+		reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.regRepo.Delete(ctx, eventID, userID); err != nil {
+			return err
+		}
+		cancelled = reg
+
+		if reg.Status == registrations.StatusWaitlist {
+			return s.waitlistRepo.DeleteByEventAndUser(ctx, eventID, userID)
+		}
+
+		if reg.Status.HoldsSeat() {
+			if err := s.scheduler.ScheduleWaitlistPromotion(ctx, eventID); err != nil {
+				return fmt.Errorf("schedule waitlist promotion: %w", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return registrations.ErrRegistrationNotFound
+		return err
 	}
 
-	oldStatus := reg.Status
-	reg.UpdateRSVP(status)
+	u := live.NewUpdate(live.KindRegistration, eventID).For(userID)
+	u.PrevStatus = string(cancelled.Status)
+	s.live.Publish(ctx, eventID, u)
+	return nil
+}
+
+// PromoteWaitlist moves people from the waitlist to going, oldest first,
+// while seats remain. On events that require the form, promoted attendees
+// who have not submitted it get a held seat instead. An entry whose ticket type is sold out blocks only that
+// type, so later entries for other types can still move up. It is idempotent,
+// so the queue may retry it or run it for a seat that a rolled-back
+// transaction never actually freed.
+func (s *Service) PromoteWaitlist(ctx context.Context, eventID shared.ID) ([]*registrations.Registration, error) {
+	var (
+		promoted []*registrations.Registration
+		held     []*registrations.Registration
+		event    *events.Event
+	)
 
-	if err := s.regRepo.Update(ctx, reg); err != nil {
-		return err
+	err := s.uow.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		promoted, held = nil, nil
+
+		capacity, _, err := s.eventRepo.LockCapacity(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		event, err = s.eventRepo.GetByID(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		entries, err := s.waitlistRepo.ListByEvent(ctx, eventID)
+		if err != nil {
+			return err
+		}
+
+		soldOut := make(map[shared.ID]bool)
+		for _, entry := range entries {
+			if entry.TicketTypeID != nil && soldOut[*entry.TicketTypeID] {
+				continue
+			}
+
+			reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, entry.UserID)
+			if err != nil && !errors.Is(err, registrations.ErrRegistrationNotFound) {
+				return err
+			}
+			if reg == nil || reg.Status != registrations.StatusWaitlist {
+				if err := s.waitlistRepo.Delete(ctx, entry.ID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			ticketType, err := s.ticketTypeOf(ctx, reg)
+			if err != nil {
+				return err
+			}
+			eventRoom, typeRoom, err := s.hasRoom(ctx, eventID, capacity, ticketType, reg.Quantity)
+			if err != nil {
+				return err
+			}
+			if !eventRoom {
+				return nil
+			}
+			if !typeRoom {
+				soldOut[ticketType.ID] = true
+				continue
+			}
+
+			if err := s.waitlistRepo.Delete(ctx, entry.ID); err != nil {
+				return err
+			}
+			reg.UpdateRSVP(registrations.StatusGoing)
+			isHeld, err := s.holdForForm(ctx, event, reg)
+			if err != nil {
+				return err
+			}
+			if err := s.regRepo.Update(ctx, reg); err != nil {
+				return err
+			}
+			if isHeld {
+				if err := s.scheduleHoldExpiry(ctx, reg); err != nil {
+					return err
+				}
+				held = append(held, reg)
+			}
+			promoted = append(promoted, reg)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if oldStatus == registrations.StatusGoing && status != registrations.StatusGoing {
-		go s.processWaitlist(context.Background(), eventID)
+	s.notifyFormHolds(ctx, held, event.Title)
+	updates := make([]*live.Update, len(promoted))
+	for i, reg := range promoted {
+		updates[i] = liveUpdate(live.KindWaitlist, reg, registrations.StatusWaitlist)
 	}
+	s.live.Publish(ctx, eventID, updates...)
+	return promoted, nil
+}
 
-	return nil
+// liveUpdate reports reg's new status to the live dashboard.
+func liveUpdate(kind live.Kind, reg *registrations.Registration, prev registrations.Status) *live.Update {
+	u := live.NewUpdate(kind, reg.EventID).For(reg.UserID)
+	u.Status = string(reg.Status)
+	u.PrevStatus = string(prev)
+	return u
 }
 
-func (s *Service) CancelRegistration(ctx context.Context, eventID, userID shared.ID) error {
-	if err := s.regRepo.Delete(ctx, eventID, userID); err != nil {
+// seat sets reg to going when there is room for it and to waitlist
+// otherwise, creating the waitlist entry. The caller persists reg.
+func (s *Service) seat(ctx context.Context, reg *registrations.Registration, capacity int, waitlist bool, ticketType *tickets.TicketType) error {
+	eventRoom, typeRoom, err := s.hasRoom(ctx, reg.EventID, capacity, ticketType, reg.Quantity)
+	if err != nil {
 		return err
 	}
+	if eventRoom && typeRoom {
+		reg.UpdateRSVP(registrations.StatusGoing)
+		return nil
+	}
 
-	go s.processWaitlist(context.Background(), eventID)
+	if !waitlist {
+		return registrations.ErrCapacityReached
+	}
+	if err := s.waitlistRepo.Create(ctx, registrations.NewWaitlistEntry(reg)); err != nil {
+		return err
+	}
+	reg.UpdateRSVP(registrations.StatusWaitlist)
 	return nil
 }
 
-func (s *Service) processWaitlist(ctx context.Context, eventID shared.ID) {
-	next, err := s.waitlistRepo.GetNextByEvent(ctx, eventID)
-	if err != nil {
-		return
+// resolveTicket picks the ticket type for a new registration and checks that
+// it can be bought right now. It returns nil for events without ticket types.
+func (s *Service) resolveTicket(ctx context.Context, eventID shared.ID, sel TicketSelection) (*tickets.TicketType, error) {
+	quantity := sel.Quantity
+	if quantity == 0 {
+		quantity = 1
 	}
 
-	reg, err := s.regRepo.GetByEventAndUser(ctx, eventID, next.UserID)
-	if err != nil {
-		return
+	var ticketType *tickets.TicketType
+	if sel.TypeID != nil {
+		tt, err := s.ticketRepo.GetByID(ctx, *sel.TypeID)
+		if err != nil {
+			return nil, err
+		}
+		if tt.EventID != eventID {
+			return nil, tickets.ErrTicketTypeNotFound
+		}
+		ticketType = tt
+	} else {
+		types, err := s.ticketRepo.ListByEvent(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		if len(types) == 0 {
+			if quantity != 1 {
+				return nil, tickets.ErrPerUserLimit
+			}
+			return nil, nil
+		}
+
+		var visible []*tickets.TicketType
+		for _, tt := range types {
+			if !tt.Hidden {
+				visible = append(visible, tt)
+			}
+		}
+		if len(visible) != 1 {
+			return nil, tickets.ErrTicketTypeRequired
+		}
+		ticketType = visible[0]
+	}
+
+	if err := ticketType.CheckPurchase(time.Now(), quantity, sel.UnlockCode); err != nil {
+		return nil, err
+	}
+
+	return ticketType, nil
+}
+
+func (s *Service) ticketTypeOf(ctx context.Context, reg *registrations.Registration) (*tickets.TicketType, error) {
+	if reg.TicketTypeID == nil {
+		return nil, nil
+	}
+	return s.ticketRepo.GetByID(ctx, *reg.TicketTypeID)
+}
+
+// hasRoom reports whether quantity more seats fit into the event as a whole
+// and into the given ticket type. Zero capacity means unlimited.
+func (s *Service) hasRoom(ctx context.Context, eventID shared.ID, capacity int, ticketType *tickets.TicketType, quantity int) (eventRoom, typeRoom bool, err error) {
+	eventRoom, typeRoom = true, true
+
+	if capacity > 0 {
+		taken, err := s.regRepo.CountSeats(ctx, eventID, nil, registrations.SeatStatuses)
+		if err != nil {
+			return false, false, err
+		}
+		eventRoom = taken+quantity <= capacity
+	}
+
+	if ticketType != nil && ticketType.Capacity > 0 {
+		taken, err := s.regRepo.CountSeats(ctx, eventID, &ticketType.ID, registrations.SeatStatuses)
+		if err != nil {
+			return false, false, err
+		}
+		typeRoom = taken+quantity <= ticketType.Capacity
 	}
 
-	reg.Status = registrations.StatusGoing
-	_ = s.regRepo.Update(ctx, reg)
-	_ = s.waitlistRepo.Delete(ctx, next.ID)
+	return eventRoom, typeRoom, nil
 }
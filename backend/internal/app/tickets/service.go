@@ -0,0 +1,149 @@
+package tickets
+
+import (
+	"context"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/tickets"
+)
+
+type TicketTypeRepo interface {
+	Create(ctx context.Context, t *tickets.TicketType) error
+	Update(ctx context.Context, t *tickets.TicketType) error
+	GetByID(ctx context.Context, id shared.ID) (*tickets.TicketType, error)
+	ListByEvent(ctx context.Context, eventID shared.ID) ([]*tickets.TicketType, error)
+	Delete(ctx context.Context, id shared.ID) error
+}
+
+type EventGetter interface {
+	GetByID(ctx context.Context, id shared.ID) (*events.Event, error)
+}
+
+type RoleGetter interface {
+	GetUserRole(ctx context.Context, eventID, userID shared.ID) (events.Role, error)
+}
+
+type Service struct {
+	ticketRepo TicketTypeRepo
+	eventRepo  EventGetter
+	roleRepo   RoleGetter
+}
+
+func NewService(ticketRepo TicketTypeRepo, eventRepo EventGetter, roleRepo RoleGetter) *Service {
+	return &Service{
+		ticketRepo: ticketRepo,
+		eventRepo:  eventRepo,
+		roleRepo:   roleRepo,
+	}
+}
+
+func (s *Service) CreateTicketType(ctx context.Context, userID shared.ID, t *tickets.TicketType) (*tickets.TicketType, error) {
+	if _, err := s.checkEditor(ctx, t.EventID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ticketRepo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// UpdateTicketType replaces every editable field of the ticket type. Lowering
+// capacity below what is already sold is allowed; it only stops new sales.
+func (s *Service) UpdateTicketType(ctx context.Context, userID, id shared.ID, updates *tickets.TicketType) (*tickets.TicketType, error) {
+	t, err := s.ticketRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.checkEditor(ctx, t.EventID, userID); err != nil {
+		return nil, err
+	}
+
+	t.Name = updates.Name
+	t.Description = updates.Description
+	t.Price = updates.Price
+	if updates.Currency != "" {
+		t.Currency = updates.Currency
+	}
+	t.PriceTiers = updates.PriceTiers
+	t.Capacity = updates.Capacity
+	t.SalesStart = updates.SalesStart
+	t.SalesEnd = updates.SalesEnd
+	t.Hidden = updates.Hidden
+	t.UnlockCode = updates.UnlockCode
+	t.PerUserLimit = updates.PerUserLimit
+	t.SortOrder = updates.SortOrder
+	t.Touch()
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ticketRepo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) DeleteTicketType(ctx context.Context, userID, id shared.ID) error {
+	t, err := s.ticketRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.checkEditor(ctx, t.EventID, userID); err != nil {
+		return err
+	}
+
+	return s.ticketRepo.Delete(ctx, id)
+}
+
+// ListTicketTypes returns everything to organizers. Attendees only see types
+// that are not hidden or that unlockCode opens, without the code itself.
+func (s *Service) ListTicketTypes(ctx context.Context, eventID, userID shared.ID, unlockCode string) ([]*tickets.TicketType, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := s.ticketRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if events.HasPermission(event, userID, role, events.PermManageTickets) {
+		return types, nil
+	}
+
+	result := make([]*tickets.TicketType, 0, len(types))
+	for _, t := range types {
+		if t.Unlocks(unlockCode) {
+			result = append(result, t.Public())
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Service) checkEditor(ctx context.Context, eventID, userID shared.ID) (*events.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _ := s.roleRepo.GetUserRole(ctx, eventID, userID)
+	if !events.HasPermission(event, userID, role, events.PermManageTickets) {
+		return nil, events.ErrUnauthorized
+	}
+
+	return event, nil
+}
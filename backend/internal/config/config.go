@@ -14,6 +14,7 @@ type Config struct {
 	Redis    RedisConfig
 	Bot      BotConfig
 	Security SecurityConfig
+	Storage  StorageConfig
 }
 
 type ServerConfig struct {
@@ -35,9 +36,26 @@ type RedisConfig struct {
 	DialTimeout time.Duration
 }
 
+// SecurityConfig also sets how long a ticket signing key signs before it
+// is rotated, and how long it keeps verifying afterwards.
 type SecurityConfig struct {
-	HMACSecret    string
-	WebhookSecret string
+	HMACSecret        string
+	WebhookSecret     string
+	TicketKeyRotation time.Duration
+	TicketKeyGrace    time.Duration
+}
+
+// StorageConfig selects where uploaded files live: "local" keeps them on
+// disk under LocalPath, "s3" uses an S3-compatible bucket such as MinIO.
+type StorageConfig struct {
+	Driver      string
+	LocalPath   string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
 }
 
 func Load() (*Config, error) {
@@ -66,8 +84,20 @@ func Load() (*Config, error) {
 			WebhookURL: getEnv("WEBHOOK_URL", ""),
 		},
 		Security: SecurityConfig{
-			HMACSecret:    getEnv("HMAC_SECRET", "change_this_secret_key"),
-			WebhookSecret: getEnv("WEBHOOK_SECRET", ""),
+			HMACSecret:        getEnv("HMAC_SECRET", "change_this_secret_key"),
+			WebhookSecret:     getEnv("WEBHOOK_SECRET", ""),
+			TicketKeyRotation: getEnvDuration("TICKET_KEY_ROTATION", 90*24*time.Hour),
+			TicketKeyGrace:    getEnvDuration("TICKET_KEY_GRACE", 30*24*time.Hour),
+		},
+		Storage: StorageConfig{
+			Driver:      getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:   getEnv("STORAGE_LOCAL_PATH", "./data/uploads"),
+			S3Endpoint:  getEnv("S3_ENDPOINT", "http://localhost:9000"),
+			S3Region:    getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("S3_BUCKET", "kvorum"),
+			S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+			S3PathStyle: getEnv("S3_PATH_STYLE", "true") == "true",
 		},
 	}, nil
 }
@@ -87,3 +117,12 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
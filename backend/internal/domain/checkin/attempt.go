@@ -0,0 +1,120 @@
+package checkin
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+var (
+	ErrWrongEvent      = errors.New("ticket is for another event")
+	ErrAttemptExists   = errors.New("offline scan already uploaded")
+	ErrAttemptNotFound = errors.New("check-in attempt not found")
+	ErrScannerDenied   = errors.New("not allowed to check attendees in")
+)
+
+// Scanner identifies who is checking attendees in and on which device.
+// ZoneID is the zone whose door the device guards, nil at the main
+// entrance.
+type Scanner struct {
+	UserID    shared.ID
+	DeviceID  string
+	ZoneID    *shared.ID
+	IP        string
+	UserAgent string
+}
+
+// Attempt is an audit record of one scan or manual check-in, whether it
+// was accepted or not. TicketID and UserID are filled in as far as the
+// attempt got.
+type Attempt struct {
+	ID        shared.ID  `json:"id"`
+	EventID   shared.ID  `json:"event_id"`
+	ScannerID shared.ID  `json:"scanner_id"`
+	DeviceID  string     `json:"device_id,omitempty"`
+	ZoneID    *shared.ID `json:"zone_id,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	Method    Method     `json:"method"`
+	Action    Action     `json:"action"`
+	TicketID  *shared.ID `json:"ticket_id,omitempty"`
+	UserID    *shared.ID `json:"user_id,omitempty"`
+	Accepted  bool       `json:"accepted"`
+	Reason    string     `json:"reason,omitempty"`
+	CheckinID *shared.ID `json:"checkin_id,omitempty"`
+	// LocalID is the device's own ID for a scan made offline; At is then
+	// when it was scanned and ReceivedAt when it was uploaded.
+	LocalID    string    `json:"local_id,omitempty"`
+	At         time.Time `json:"at"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+func NewAttempt(eventID shared.ID, scanner Scanner, method Method, action Action) *Attempt {
+	now := time.Now().UTC()
+	return &Attempt{
+		ID:         shared.NewID(),
+		EventID:    eventID,
+		ScannerID:  scanner.UserID,
+		DeviceID:   scanner.DeviceID,
+		ZoneID:     scanner.ZoneID,
+		IP:         scanner.IP,
+		UserAgent:  scanner.UserAgent,
+		Method:     method,
+		Action:     action,
+		At:         now,
+		ReceivedAt: now,
+	}
+}
+
+func (a *Attempt) Accept(c *Checkin) {
+	a.Accepted = true
+	a.Reason = ""
+	a.UserID = &c.UserID
+	a.CheckinID = &c.ID
+}
+
+func (a *Attempt) Reject(err error) {
+	a.Accepted = false
+	a.Reason = RejectReason(err)
+}
+
+const (
+	reasonDuplicate    = "duplicate"
+	reasonNotCheckedIn = "not_checked_in"
+	reasonNotInside    = "not_inside"
+	// ReasonInternal marks a failure on our side rather than a refusal.
+	ReasonInternal = "error"
+)
+
+// RejectReason turns a check-in error into a stable code for the audit
+// trail.
+func RejectReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidQRToken):
+		return "invalid_token"
+	case errors.Is(err, ErrWrongEvent):
+		return "wrong_event"
+	case errors.Is(err, ErrTicketOutsideWindow):
+		return "outside_window"
+	case errors.Is(err, ErrTicketRevoked):
+		return "revoked"
+	case errors.Is(err, ErrNoConfirmedSeat):
+		return "not_registered"
+	case errors.Is(err, ErrAlreadyCheckedIn):
+		return reasonDuplicate
+	case errors.Is(err, ErrNotCheckedIn):
+		return reasonNotCheckedIn
+	case errors.Is(err, ErrNotInside):
+		return reasonNotInside
+	case errors.Is(err, ErrInvalidAction):
+		return "invalid_action"
+	case errors.Is(err, ErrZoneNotFound):
+		return "zone_not_found"
+	case errors.Is(err, ErrZoneDenied):
+		return "zone_denied"
+	case errors.Is(err, ErrScannerDenied):
+		return "forbidden"
+	}
+	return ReasonInternal
+}
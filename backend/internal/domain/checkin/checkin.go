@@ -2,19 +2,44 @@ package checkin
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 )
 
+// Checkin is one pass through the door. Direction tells entries from
+// exits; Reentry marks an entry after the attendee had already been in.
+// ZoneID is nil at the event's main entrance.
 type Checkin struct {
-	ID      shared.ID
-	EventID shared.ID
-	UserID  shared.ID
-	Method  Method
-	At      time.Time
+	ID        shared.ID
+	EventID   shared.ID
+	UserID    shared.ID
+	ZoneID    *shared.ID
+	Method    Method
+	Direction Direction
+	Reentry   bool
+	At        time.Time
 }
 
+type Direction string
+
+const (
+	DirectionIn  Direction = "in"
+	DirectionOut Direction = "out"
+)
+
+// Action is what the operator asks for at the door. A plain entry is
+// refused once the attendee has been in; letting them back needs an
+// explicit re-entry, which is only possible after they went out.
+type Action string
+
+const (
+	ActionEntry   Action = "entry"
+	ActionReentry Action = "reentry"
+	ActionExit    Action = "exit"
+)
+
 type Method string
 
 const (
@@ -22,41 +47,80 @@ const (
 	MethodManual Method = "manual"
 )
 
-type QRToken struct {
-	ID        shared.ID
-	UserID    shared.ID
-	EventID   shared.ID
-	TokenHash []byte
-	ExpiresAt time.Time
-	shared.Timestamp
-}
-
 var (
 	ErrInvalidQRToken   = errors.New("invalid or expired QR token")
 	ErrAlreadyCheckedIn = errors.New("user already checked in")
+	ErrNotCheckedIn     = errors.New("user has not checked in")
+	ErrNotInside        = errors.New("user is not inside")
+	ErrInvalidAction    = errors.New("invalid check-in action")
 )
 
-func NewCheckin(eventID, userID shared.ID, method Method) *Checkin {
-	return &Checkin{
-		ID:      shared.NewID(),
-		EventID: eventID,
-		UserID:  userID,
-		Method:  method,
-		At:      time.Now().UTC(),
-	}
+// DuplicateError reports a repeated entry along with when the attendee
+// first came in. It matches ErrAlreadyCheckedIn.
+type DuplicateError struct {
+	FirstAt time.Time
+	LastAt  time.Time
+	Inside  bool
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s at %s", ErrAlreadyCheckedIn, e.FirstAt.Format(time.RFC3339))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrAlreadyCheckedIn
 }
 
-func NewQRToken(userID, eventID shared.ID, tokenHash []byte, ttl time.Duration) *QRToken {
-	return &QRToken{
+func (a Action) Valid() bool {
+	return a == ActionEntry || a == ActionReentry || a == ActionExit
+}
+
+func NewCheckin(eventID, userID shared.ID, method Method, direction Direction) *Checkin {
+	return &Checkin{
 		ID:        shared.NewID(),
-		UserID:    userID,
 		EventID:   eventID,
-		TokenHash: tokenHash,
-		ExpiresAt: time.Now().UTC().Add(ttl),
-		Timestamp: shared.NewTimestamp(),
+		UserID:    userID,
+		Method:    method,
+		Direction: direction,
+		At:        time.Now().UTC(),
 	}
 }
 
-func (q *QRToken) IsExpired() bool {
-	return time.Now().UTC().After(q.ExpiresAt)
+// Record applies action to the attendee's history, oldest first, and
+// returns the pass to store.
+func Record(history []*Checkin, eventID, userID shared.ID, method Method, action Action) (*Checkin, error) {
+	var first, last *Checkin
+	for _, c := range history {
+		if first == nil && c.Direction == DirectionIn {
+			first = c
+		}
+		last = c
+	}
+	inside := last != nil && last.Direction == DirectionIn
+
+	switch action {
+	case ActionEntry:
+		if first != nil {
+			return nil, &DuplicateError{FirstAt: first.At, LastAt: last.At, Inside: inside}
+		}
+		return NewCheckin(eventID, userID, method, DirectionIn), nil
+	case ActionReentry:
+		if first == nil {
+			return nil, ErrNotCheckedIn
+		}
+		// Whoever holds the ticket is already in; a second person with a
+		// copy of it must not be let through.
+		if inside {
+			return nil, &DuplicateError{FirstAt: first.At, LastAt: last.At, Inside: true}
+		}
+		c := NewCheckin(eventID, userID, method, DirectionIn)
+		c.Reentry = true
+		return c, nil
+	case ActionExit:
+		if !inside {
+			return nil, ErrNotInside
+		}
+		return NewCheckin(eventID, userID, method, DirectionOut), nil
+	}
+	return nil, ErrInvalidAction
 }
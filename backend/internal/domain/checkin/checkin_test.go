@@ -0,0 +1,63 @@
+package checkin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+func TestRecord(t *testing.T) {
+	eventID, userID := shared.NewID(), shared.NewID()
+	start := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		actions []Action
+		want    error
+		inside  bool
+	}{
+		{"entry", []Action{ActionEntry}, nil, true},
+		{"entry twice", []Action{ActionEntry, ActionEntry}, ErrAlreadyCheckedIn, true},
+		{"reentry without entry", []Action{ActionReentry}, ErrNotCheckedIn, false},
+		{"reentry while inside", []Action{ActionEntry, ActionReentry}, ErrAlreadyCheckedIn, true},
+		{"entry, exit, reentry", []Action{ActionEntry, ActionExit, ActionReentry}, nil, true},
+		{"entry, exit, entry", []Action{ActionEntry, ActionExit, ActionEntry}, ErrAlreadyCheckedIn, false},
+		{"reentry twice", []Action{ActionEntry, ActionExit, ActionReentry, ActionReentry}, ErrAlreadyCheckedIn, true},
+		{"exit without entry", []Action{ActionExit}, ErrNotInside, false},
+		{"exit twice", []Action{ActionEntry, ActionExit, ActionExit}, ErrNotInside, false},
+	}
+
+	for _, tt := range tests {
+		var history []*Checkin
+		var err error
+		for i, action := range tt.actions {
+			var c *Checkin
+			c, err = Record(history, eventID, userID, MethodQR, action)
+			if err != nil {
+				break
+			}
+			c.At = start.Add(time.Duration(i) * time.Minute)
+			history = append(history, c)
+		}
+
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		var dup *DuplicateError
+		if errors.As(err, &dup) && (!dup.FirstAt.Equal(start) || dup.Inside != tt.inside) {
+			t.Errorf("%s: duplicate = %+v, want first at %s, inside %v", tt.name, dup, start, tt.inside)
+		}
+		if err == nil {
+			last := history[len(history)-1]
+			if inside := last.Direction == DirectionIn; inside != tt.inside {
+				t.Errorf("%s: inside = %v, want %v", tt.name, inside, tt.inside)
+			}
+			if want := tt.actions[len(tt.actions)-1] == ActionReentry; last.Reentry != want {
+				t.Errorf("%s: reentry = %v, want %v", tt.name, last.Reentry, want)
+			}
+		}
+	}
+}
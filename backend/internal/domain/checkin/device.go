@@ -0,0 +1,89 @@
+package checkin
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+var (
+	ErrDeviceRequired = errors.New("device id is required")
+	ErrDeviceNotFound = errors.New("scanner device not found")
+)
+
+// ManifestEntry is an attendee as a scanner device sees them offline.
+// Status is the registration status, or "cancelled" once the registration
+// is gone; TicketID is the live ticket, or the last revoked one. Inside is
+// about the main entrance.
+type ManifestEntry struct {
+	UserID       shared.ID  `json:"user_id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`
+	TicketID     *shared.ID `json:"ticket_id,omitempty"`
+	TicketTypeID *shared.ID `json:"ticket_type_id,omitempty"`
+	ValidFrom    *time.Time `json:"valid_from,omitempty"`
+	ValidUntil   *time.Time `json:"valid_until,omitempty"`
+	Revoked      bool       `json:"revoked"`
+	Inside       bool       `json:"inside"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Device is the sync state of one scanner device at an event.
+type Device struct {
+	EventID        shared.ID  `json:"event_id"`
+	DeviceID       string     `json:"device_id"`
+	ScannerID      shared.ID  `json:"scanner_id"`
+	UserAgent      string     `json:"user_agent,omitempty"`
+	ManifestCursor *time.Time `json:"manifest_cursor,omitempty"`
+	ManifestAt     *time.Time `json:"manifest_at,omitempty"`
+	UploadedAt     *time.Time `json:"uploaded_at,omitempty"`
+	LastScanAt     *time.Time `json:"last_scan_at,omitempty"`
+	Uploaded       int        `json:"uploaded"`
+	Accepted       int        `json:"accepted"`
+	Conflicts      int        `json:"conflicts"`
+	Rejected       int        `json:"rejected"`
+	// Pending is what the device reported it still holds.
+	Pending int `json:"pending"`
+}
+
+func NewDevice(eventID shared.ID, scanner Scanner) *Device {
+	return &Device{
+		EventID:   eventID,
+		DeviceID:  scanner.DeviceID,
+		ScannerID: scanner.UserID,
+		UserAgent: scanner.UserAgent,
+	}
+}
+
+// Seen records who is using the device now; devices get passed around.
+func (d *Device) Seen(scanner Scanner) {
+	d.ScannerID = scanner.UserID
+	if scanner.UserAgent != "" {
+		d.UserAgent = scanner.UserAgent
+	}
+}
+
+// SyncStatus is how an uploaded offline scan was settled.
+type SyncStatus string
+
+const (
+	SyncAccepted SyncStatus = "accepted"
+	// SyncConflict means the server's history disagrees with the device,
+	// typically because another gate let the same ticket in first.
+	SyncConflict SyncStatus = "conflict"
+	SyncRejected SyncStatus = "rejected"
+	// SyncRetry means the scan could not be settled now; upload it again.
+	SyncRetry SyncStatus = "retry"
+)
+
+// Status classifies a settled attempt for the device that uploaded it.
+func (a *Attempt) Status() SyncStatus {
+	switch {
+	case a.Accepted:
+		return SyncAccepted
+	case a.Reason == reasonDuplicate || a.Reason == reasonNotCheckedIn || a.Reason == reasonNotInside:
+		return SyncConflict
+	}
+	return SyncRejected
+}
@@ -0,0 +1,249 @@
+package checkin
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+	"github.com/google/uuid"
+)
+
+// Ticket is an attendee's pass to an event. There is one live ticket per
+// registration; revoking it lets a new one be issued with a different ID.
+type Ticket struct {
+	ID           shared.ID
+	EventID      shared.ID
+	UserID       shared.ID
+	TicketTypeID *shared.ID
+	ValidFrom    time.Time
+	ValidUntil   time.Time
+	RevokedAt    *time.Time
+	RevokeReason string
+	shared.Timestamp
+}
+
+// Tickets open a day before the event and stay valid for a while after it
+// ends. Events without an end are assumed to last a day.
+const (
+	TicketEarlyEntry = 24 * time.Hour
+	TicketGrace      = 6 * time.Hour
+)
+
+var (
+	ErrTicketNotFound      = errors.New("ticket not found")
+	ErrTicketRevoked       = errors.New("ticket has been revoked")
+	ErrTicketOutsideWindow = errors.New("ticket is not valid at this time")
+	ErrNoConfirmedSeat     = errors.New("no confirmed registration for this event")
+	ErrNoSigningKey        = errors.New("no ticket signing key")
+)
+
+func NewTicket(eventID, userID shared.ID, ticketTypeID *shared.ID, startsAt, endsAt time.Time) *Ticket {
+	t := &Ticket{
+		ID:           shared.NewID(),
+		EventID:      eventID,
+		UserID:       userID,
+		TicketTypeID: ticketTypeID,
+		Timestamp:    shared.NewTimestamp(),
+	}
+	t.Reschedule(startsAt, endsAt)
+	return t
+}
+
+// Reschedule moves the validity window to follow the event's times and
+// reports whether it changed. Windows are kept to whole seconds, which is
+// what the token carries.
+func (t *Ticket) Reschedule(startsAt, endsAt time.Time) bool {
+	if endsAt.IsZero() || endsAt.Before(startsAt) {
+		endsAt = startsAt.Add(24 * time.Hour)
+	}
+	from := startsAt.Add(-TicketEarlyEntry).UTC().Truncate(time.Second)
+	until := endsAt.Add(TicketGrace).UTC().Truncate(time.Second)
+	if from.Equal(t.ValidFrom) && until.Equal(t.ValidUntil) {
+		return false
+	}
+
+	t.ValidFrom = from
+	t.ValidUntil = until
+	t.Timestamp.Touch()
+	return true
+}
+
+func (t *Ticket) Revoke(reason string) {
+	now := time.Now().UTC()
+	t.RevokedAt = &now
+	t.RevokeReason = reason
+	t.Timestamp.Touch()
+}
+
+// Revocation is an entry of an event's revocation list: a ticket that was
+// revoked outright or whose registration no longer holds a place.
+type Revocation struct {
+	TicketID  shared.ID `json:"ticket_id"`
+	RevokedAt time.Time `json:"revoked_at"`
+	Reason    string    `json:"reason"`
+}
+
+// SigningKey signs ticket tokens. Only the newest key signs; older keys
+// keep verifying until ExpiresAt so tickets already handed out still scan.
+// SignedUntil is the latest ValidUntil among the tokens it signed, which
+// ExpiresAt never falls short of.
+type SigningKey struct {
+	ID          string
+	PublicKey   ed25519.PublicKey
+	PrivateKey  ed25519.PrivateKey
+	CreatedAt   time.Time
+	RetiredAt   *time.Time
+	ExpiresAt   *time.Time
+	SignedUntil *time.Time
+}
+
+// Covers reports whether the key is already known to verify until t.
+func (k *SigningKey) Covers(t time.Time) bool {
+	return k.SignedUntil != nil && !k.SignedUntil.Before(t)
+}
+
+func NewSigningKey() (*SigningKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, keyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:         hex.EncodeToString(id),
+		PublicKey:  pub,
+		PrivateKey: priv,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Claims is what a ticket token asserts.
+type Claims struct {
+	KeyID        string
+	TicketID     shared.ID
+	EventID      shared.ID
+	UserID       shared.ID
+	TicketTypeID *shared.ID
+	ValidFrom    time.Time
+	ValidUntil   time.Time
+}
+
+func (t *Ticket) Claims() Claims {
+	return Claims{
+		TicketID:     t.ID,
+		EventID:      t.EventID,
+		UserID:       t.UserID,
+		TicketTypeID: t.TicketTypeID,
+		ValidFrom:    t.ValidFrom,
+		ValidUntil:   t.ValidUntil,
+	}
+}
+
+// A ticket token is base64url (no padding) of a 141 byte message:
+//
+//	0      version, 1
+//	1..4   signing key ID
+//	5..20  ticket ID (UUID)
+//	21..36 event ID (UUID)
+//	37..52 user ID (UUID)
+//	53..68 ticket type ID (UUID, all zero when none)
+//	69..72 valid from, unix seconds, big endian
+//	73..76 valid until, unix seconds, big endian
+//	77..   Ed25519 signature of bytes 0..76
+//
+// Scanners verify it offline with the public key published under the key
+// ID and check the window and the event's revocation list themselves.
+const (
+	tokenVersion = 1
+	keyIDSize    = 4
+	claimsSize   = 1 + keyIDSize + 4*16 + 2*4
+	tokenSize    = claimsSize + ed25519.SignatureSize
+)
+
+// Sign encodes the claims as a token signed with key.
+func (c Claims) Sign(key *SigningKey) (string, error) {
+	if key.PrivateKey == nil {
+		return "", ErrNoSigningKey
+	}
+	c.KeyID = key.ID
+
+	msg, err := c.marshal()
+	if err != nil {
+		return "", err
+	}
+	msg = append(msg, ed25519.Sign(key.PrivateKey, msg)...)
+	return base64.RawURLEncoding.EncodeToString(msg), nil
+}
+
+func (c Claims) marshal() ([]byte, error) {
+	kid, err := hex.DecodeString(c.KeyID)
+	if err != nil || len(kid) != keyIDSize {
+		return nil, ErrNoSigningKey
+	}
+
+	msg := make([]byte, 0, tokenSize)
+	msg = append(msg, tokenVersion)
+	msg = append(msg, kid...)
+	for _, id := range []*shared.ID{&c.TicketID, &c.EventID, &c.UserID, c.TicketTypeID} {
+		var b uuid.UUID
+		if id != nil {
+			if b, err = uuid.Parse(id.String()); err != nil {
+				return nil, err
+			}
+		}
+		msg = append(msg, b[:]...)
+	}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(c.ValidFrom.Unix()))
+	msg = binary.BigEndian.AppendUint32(msg, uint32(c.ValidUntil.Unix()))
+	return msg, nil
+}
+
+// ParseToken checks the token's signature with the key it names and
+// returns its claims. The validity window is left to the caller.
+func ParseToken(token string, keys func(id string) (ed25519.PublicKey, bool)) (*Claims, error) {
+	msg, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(msg) != tokenSize || msg[0] != tokenVersion {
+		return nil, ErrInvalidQRToken
+	}
+
+	kid := hex.EncodeToString(msg[1 : 1+keyIDSize])
+	pub, ok := keys(kid)
+	if !ok || !ed25519.Verify(pub, msg[:claimsSize], msg[claimsSize:]) {
+		return nil, ErrInvalidQRToken
+	}
+
+	ids := make([]shared.ID, 4)
+	for i := range ids {
+		off := 1 + keyIDSize + i*16
+		b, _ := uuid.FromBytes(msg[off : off+16])
+		if b != uuid.Nil {
+			ids[i] = shared.ID(b.String())
+		}
+	}
+
+	c := &Claims{
+		KeyID:      kid,
+		TicketID:   ids[0],
+		EventID:    ids[1],
+		UserID:     ids[2],
+		ValidFrom:  time.Unix(int64(binary.BigEndian.Uint32(msg[claimsSize-8:])), 0).UTC(),
+		ValidUntil: time.Unix(int64(binary.BigEndian.Uint32(msg[claimsSize-4:])), 0).UTC(),
+	}
+	if ids[3] != "" {
+		c.TicketTypeID = &ids[3]
+	}
+	return c, nil
+}
+
+func (c *Claims) ValidAt(now time.Time) bool {
+	return !now.Before(c.ValidFrom) && now.Before(c.ValidUntil)
+}
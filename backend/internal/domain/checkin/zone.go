@@ -0,0 +1,72 @@
+package checkin
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/events"
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+var (
+	ErrZoneNotFound = errors.New("zone not found")
+	ErrInvalidZone  = errors.New("invalid zone configuration")
+	ErrZoneInUse    = errors.New("zone already has recorded passes")
+	ErrZoneDenied   = errors.New("ticket does not admit to this zone")
+)
+
+// Zone is an area of the event behind its own door, such as a VIP lounge
+// or a workshop room. Passes through a zone's door are kept apart from the
+// main entrance. A zone without rules takes anyone with a confirmed seat;
+// otherwise the attendee's ticket type or their role at the event must be
+// listed.
+type Zone struct {
+	ID            shared.ID     `json:"id"`
+	EventID       shared.ID     `json:"event_id"`
+	Name          string        `json:"name"`
+	TicketTypeIDs []shared.ID   `json:"ticket_type_ids"`
+	Roles         []events.Role `json:"roles"`
+	SortOrder     int           `json:"sort_order"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+func NewZone(eventID shared.ID, name string) *Zone {
+	now := time.Now().UTC()
+	return &Zone{
+		ID:            shared.NewID(),
+		EventID:       eventID,
+		Name:          name,
+		TicketTypeIDs: []shared.ID{},
+		Roles:         []events.Role{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func (z *Zone) Validate() error {
+	if strings.TrimSpace(z.Name) == "" {
+		return ErrInvalidZone
+	}
+	for _, role := range z.Roles {
+		if role != events.RoleOwner && !role.Grantable() {
+			return events.ErrInvalidRole
+		}
+	}
+	return nil
+}
+
+// Open reports whether the zone has no rules of its own.
+func (z *Zone) Open() bool {
+	return len(z.TicketTypeIDs) == 0 && len(z.Roles) == 0
+}
+
+func (z *Zone) AdmitsTicketType(id *shared.ID) bool {
+	return id != nil && slices.Contains(z.TicketTypeIDs, *id)
+}
+
+func (z *Zone) AdmitsRole(role events.Role) bool {
+	return role != "" && slices.Contains(z.Roles, role)
+}
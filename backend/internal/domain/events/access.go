@@ -0,0 +1,69 @@
+package events
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// Viewer is everything the access rules need to know about whoever is looking
+// at an event. UserID is empty for anonymous requests.
+type Viewer struct {
+	UserID     shared.ID
+	Role       Role
+	Invited    bool
+	Member     bool
+	ShareToken string
+}
+
+// CanUserView applies the event visibility. Anyone with a role sees the event
+// in any state; everybody else sees only published or cancelled events, and
+// only if the visibility lets them in. Invitees pass every visibility.
+func CanUserView(event *Event, v Viewer) bool {
+	if v.UserID != "" && (event.OwnerID == v.UserID || v.Role != "") {
+		return true
+	}
+	if event.Status == StatusDraft {
+		return false
+	}
+	if v.Invited {
+		return true
+	}
+
+	switch event.Visibility {
+	case VisibilityPublic, VisibilityRequest:
+		return true
+	case VisibilityByLink:
+		return event.ShareToken != "" && v.ShareToken != "" &&
+			subtle.ConstantTimeCompare([]byte(event.ShareToken), []byte(v.ShareToken)) == 1
+	case VisibilityByMembership:
+		return v.Member
+	default:
+		return false
+	}
+}
+
+// IsListed reports whether the event may appear in public listings and in
+// caches shared between users.
+func (e *Event) IsListed() bool {
+	return e.Status == StatusPublished &&
+		(e.Visibility == VisibilityPublic || e.Visibility == VisibilityRequest)
+}
+
+// RotateShareToken replaces the share link token, invalidating old links.
+func (e *Event) RotateShareToken() error {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	e.ShareToken = base64.RawURLEncoding.EncodeToString(b)
+	e.Timestamp.Touch()
+	return nil
+}
+
+func (e *Event) RevokeShareToken() {
+	e.ShareToken = ""
+	e.Timestamp.Touch()
+}
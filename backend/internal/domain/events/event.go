@@ -22,6 +22,16 @@ type Event struct {
 	Capacity    int
 	Waitlist    bool
 	Settings    map[string]interface{}
+	SeriesID    *shared.ID
+	// OriginalStartsAt is the slot the recurrence rule generated for this
+	// occurrence; it stays fixed when a single occurrence is moved.
+	OriginalStartsAt *time.Time
+	// OrganizationID is set for events published under an organization;
+	// by_membership events are restricted to its members.
+	OrganizationID *shared.ID
+	// ShareToken opens by_link events. It is never serialized; organizers
+	// read it through the share link endpoint.
+	ShareToken string `json:"-"`
 	shared.Timestamp
 }
 
@@ -50,6 +60,8 @@ const (
 	RoleOrganizer   Role = "organizer"
 	RoleCoOrganizer Role = "coorganizer"
 	RoleViewer      Role = "viewer"
+	// RoleScanner is door staff: it may check attendees in and nothing else.
+	RoleScanner Role = "scanner"
 )
 
 type Series struct {
@@ -67,6 +79,8 @@ var (
 	ErrCannotPublishDraft = errors.New("cannot publish event without required fields")
 	ErrEventNotFound      = errors.New("event not found")
 	ErrUnauthorized       = errors.New("unauthorized to perform this action")
+	ErrInvalidVisibility  = errors.New("invalid visibility")
+	ErrMembershipNoOrg    = errors.New("by_membership events must belong to an organization")
 )
 
 func NewEvent(ownerID shared.ID, title, description string) *Event {
@@ -83,6 +97,28 @@ func NewEvent(ownerID shared.ID, title, description string) *Event {
 	}
 }
 
+func NewOccurrence(template *Event, seriesID shared.ID, startsAt time.Time) *Event {
+	occ := *template
+	occ.ID = shared.NewID()
+	occ.SeriesID = &seriesID
+	occ.StartsAt = startsAt
+	if !template.EndsAt.IsZero() {
+		occ.EndsAt = startsAt.Add(template.EndsAt.Sub(template.StartsAt))
+	}
+	original := startsAt
+	occ.OriginalStartsAt = &original
+	occ.Settings = make(map[string]interface{}, len(template.Settings))
+	for k, v := range template.Settings {
+		occ.Settings[k] = v
+	}
+	occ.Timestamp = shared.NewTimestamp()
+	return &occ
+}
+
+func (e *Event) IsOccurrence() bool {
+	return e.SeriesID != nil
+}
+
 func (e *Event) Publish() error {
 	if e.Title == "" || e.StartsAt.IsZero() {
 		return ErrCannotPublishDraft
@@ -114,16 +150,31 @@ func (e *Event) ValidateCapacity() error {
 	return nil
 }
 
-func CanUserEdit(event *Event, userID shared.ID, role Role) bool {
-	if event.OwnerID == userID {
-		return true
+// ValidateVisibility rejects by_membership on an event outside any
+// organization: it would have no members to show it to.
+func (e *Event) ValidateVisibility() error {
+	switch e.Visibility {
+	case VisibilityPublic, VisibilityPrivate, VisibilityByLink, VisibilityRequest:
+		return nil
+	case VisibilityByMembership:
+		if e.OrganizationID == nil {
+			return ErrMembershipNoOrg
+		}
+		return nil
 	}
-	return role == RoleOrganizer || role == RoleCoOrganizer
+	return ErrInvalidVisibility
+}
+
+func CanUserEdit(event *Event, userID shared.ID, role Role) bool {
+	return HasPermission(event, userID, role, PermEditEvent)
 }
 
 func CanUserPublish(event *Event, userID shared.ID, role Role) bool {
-	if event.OwnerID == userID {
-		return true
-	}
-	return role == RoleOrganizer
+	return HasPermission(event, userID, role, PermPublishEvent)
+}
+
+// TransferOwnership hands the event to newOwnerID.
+func (e *Event) TransferOwnership(newOwnerID shared.ID) {
+	e.OwnerID = newOwnerID
+	e.Timestamp.Touch()
 }
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	formRequiredKey = "form_required"
+	formHoldKey     = "form_hold_minutes"
+	maxFormHold     = 7 * 24 * time.Hour
+)
+
+// DefaultFormHold is how long a seat is held for an attendee who still has
+// to fill in the registration form.
+const DefaultFormHold = 30 * time.Minute
+
+var ErrInvalidFormHold = errors.New("form hold must be between 1 minute and 7 days")
+
+// RequiresForm reports whether attendees must submit the event's active
+// form before their seat is confirmed.
+func (e *Event) RequiresForm() bool {
+	required, _ := e.Settings[formRequiredKey].(bool)
+	return required
+}
+
+func (e *Event) FormHold() time.Duration {
+	switch v := e.Settings[formHoldKey].(type) {
+	case int:
+		return time.Duration(v) * time.Minute
+	case float64:
+		return time.Duration(v) * time.Minute
+	}
+	return DefaultFormHold
+}
+
+// SetFormRequirement makes the active form mandatory for registration. A
+// zero hold restores DefaultFormHold.
+func (e *Event) SetFormRequirement(required bool, hold time.Duration) error {
+	if hold != 0 && (hold < time.Minute || hold > maxFormHold) {
+		return ErrInvalidFormHold
+	}
+	if e.Settings == nil {
+		e.Settings = make(map[string]interface{})
+	}
+
+	e.Settings[formRequiredKey] = required
+	if hold == 0 {
+		delete(e.Settings, formHoldKey)
+	} else {
+		e.Settings[formHoldKey] = int(hold / time.Minute)
+	}
+	e.Timestamp.Touch()
+	return nil
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type Permission string
+
+const (
+	PermEditEvent           Permission = "event.edit"
+	PermPublishEvent        Permission = "event.publish"
+	PermManageRoles         Permission = "roles.manage"
+	PermManageRegistrations Permission = "registrations.manage"
+	PermManageTickets       Permission = "tickets.manage"
+	PermManageForms         Permission = "forms.manage"
+	PermManagePolls         Permission = "polls.manage"
+	PermManageCampaigns     Permission = "campaigns.manage"
+	PermScanCheckin         Permission = "checkin.scan"
+	PermViewAnalytics       Permission = "analytics.view"
+)
+
+// rolePermissions is the permission matrix for event roles. The owner is not
+// listed: the event owner holds every permission.
+var rolePermissions = map[Role][]Permission{
+	RoleOrganizer: {
+		PermEditEvent, PermPublishEvent, PermManageRoles, PermManageRegistrations,
+		PermManageTickets, PermManageForms, PermManagePolls, PermManageCampaigns,
+		PermScanCheckin, PermViewAnalytics,
+	},
+	RoleCoOrganizer: {
+		PermEditEvent, PermManageRegistrations, PermManageTickets, PermManageForms,
+		PermManagePolls, PermScanCheckin, PermViewAnalytics,
+	},
+	RoleViewer: {
+		PermViewAnalytics,
+	},
+	RoleScanner: {
+		PermScanCheckin,
+	},
+}
+
+var roleRank = map[Role]int{
+	RoleViewer:      1,
+	RoleScanner:     1,
+	RoleCoOrganizer: 2,
+	RoleOrganizer:   3,
+	RoleOwner:       4,
+}
+
+var (
+	ErrInvalidRole       = errors.New("invalid event role")
+	ErrCannotChangeOwner = errors.New("owner role can only change through ownership transfer")
+	ErrRoleInviteInvalid = errors.New("role invite is invalid, used or expired")
+)
+
+func HasPermission(event *Event, userID shared.ID, role Role, perm Permission) bool {
+	if userID != "" && event.OwnerID == userID {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists everything the user may do on the event.
+func Permissions(event *Event, userID shared.ID, role Role) []Permission {
+	all := rolePermissions[RoleOrganizer]
+	result := make([]Permission, 0, len(all))
+	for _, p := range all {
+		if HasPermission(event, userID, role, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Grantable reports whether the role can be handed out through grants and
+// invites. Ownership moves only through a transfer.
+func (r Role) Grantable() bool {
+	return r == RoleOrganizer || r == RoleCoOrganizer || r == RoleViewer || r == RoleScanner
+}
+
+// CanGrant reports whether a user holding granterRole may grant or revoke
+// target. The owner manages every role; others need the roles permission and
+// may only manage roles below their own.
+func CanGrant(event *Event, userID shared.ID, granterRole, target Role) bool {
+	if !target.Grantable() {
+		return false
+	}
+	if event.OwnerID == userID {
+		return true
+	}
+	if !HasPermission(event, userID, granterRole, PermManageRoles) {
+		return false
+	}
+	return granterRole.Outranks(target)
+}
+
+func (r Role) Outranks(other Role) bool {
+	return roleRank[r] > roleRank[other]
+}
+
+// RoleInvite grants Role to whoever accepts it first.
+type RoleInvite struct {
+	ID        shared.ID
+	EventID   shared.ID
+	Role      Role
+	Token     string
+	CreatedBy shared.ID
+	ExpiresAt *time.Time
+	UsedBy    *shared.ID
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func NewRoleInvite(eventID, createdBy shared.ID, role Role, ttl time.Duration) (*RoleInvite, error) {
+	if !role.Grantable() {
+		return nil, ErrInvalidRole
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	invite := &RoleInvite{
+		ID:        shared.NewID(),
+		EventID:   eventID,
+		Role:      role,
+		Token:     base64.RawURLEncoding.EncodeToString(b),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		invite.ExpiresAt = &expires
+	}
+
+	return invite, nil
+}
+
+func (i *RoleInvite) Accept(userID shared.ID) error {
+	now := time.Now().UTC()
+	if i.UsedBy != nil || (i.ExpiresAt != nil && now.After(*i.ExpiresAt)) {
+		return ErrRoleInviteInvalid
+	}
+
+	i.UsedBy = &userID
+	i.UsedAt = &now
+	return nil
+}
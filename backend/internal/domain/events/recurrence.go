@@ -0,0 +1,472 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+type EditScope string
+
+const (
+	ScopeThis      EditScope = "this"
+	ScopeFollowing EditScope = "following"
+	ScopeAll       EditScope = "all"
+)
+
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+var (
+	ErrInvalidRRule        = errors.New("invalid recurrence rule")
+	ErrSeriesExists        = errors.New("event already belongs to a series")
+	ErrSeriesRequiresStart = errors.New("series requires event start time")
+	ErrNotSeriesEvent      = errors.New("event is not part of a series")
+	ErrInvalidEditScope    = errors.New("invalid edit scope")
+	ErrOccurrenceNotFound  = errors.New("occurrence not found")
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func ParseRRule(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, ErrInvalidRRule
+	}
+
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRRule, part)
+		}
+
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL %s", ErrInvalidRRule, value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT %s", ErrInvalidRRule, value)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL %s", ErrInvalidRRule, value)
+			}
+			r.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY %s", ErrInvalidRRule, v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRRule, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRRule)
+	}
+	// RFC 5545 only allows these with the frequencies they restrict.
+	if r.Freq != FrequencyMonthly {
+		for _, wd := range r.ByDay {
+			if wd.N != 0 {
+				return nil, fmt.Errorf("%w: numbered BYDAY needs FREQ=MONTHLY", ErrInvalidRRule)
+			}
+		}
+	}
+	if r.Freq == FrequencyWeekly && len(r.ByMonthDay) > 0 {
+		return nil, fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalidRRule)
+	}
+
+	return r, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, ErrInvalidRRule
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %s", ErrInvalidRRule, code)
+	}
+
+	wd, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %s", ErrInvalidRRule, code)
+	}
+
+	var n int
+	if prefix := code[:len(code)-2]; prefix != "" {
+		v, err := strconv.Atoi(prefix)
+		if err != nil || v == 0 || v < -5 || v > 5 {
+			return WeekdayNum{}, fmt.Errorf("%w: BYDAY %s", ErrInvalidRRule, code)
+		}
+		n = v
+	}
+
+	return WeekdayNum{Weekday: wd, N: n}, nil
+}
+
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			code := strings.ToUpper(wd.Weekday.String()[:2])
+			if wd.N != 0 {
+				code = strconv.Itoa(wd.N) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Expand returns occurrence start times beginning at dtstart (inclusive) up to
+// the horizon. Wall-clock time is preserved in loc, so a 19:00 meetup stays at
+// 19:00 across DST changes. COUNT is applied from dtstart, before the horizon
+// cuts the list, so a partially expanded series keeps its numbering. For a
+// daily rule BYDAY and BYMONTHDAY only filter the days; for a monthly one
+// both must match when both are given.
+func (r *Recurrence) Expand(dtstart time.Time, loc *time.Location, horizon time.Time, limit int) []time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := dtstart.In(loc)
+
+	var result []time.Time
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		if t.After(horizon) || (limit > 0 && len(result) >= limit) {
+			return false
+		}
+		result = append(result, t.UTC())
+		return true
+	}
+
+	// Hard cap on iterations protects against rules that never produce a
+	// candidate, e.g. BYMONTHDAY=31 with a two-month interval starting in April.
+	const maxPeriods = 5000
+
+	switch r.Freq {
+	case FrequencyDaily:
+		for i := 0; i < maxPeriods; i++ {
+			day := start.AddDate(0, 0, i*r.Interval)
+			if !r.matchesDay(day) {
+				continue
+			}
+			if !emit(day) {
+				return result
+			}
+		}
+
+	case FrequencyWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: start.Weekday()}}
+		}
+		weekStart := start.AddDate(0, 0, -int(mondayOffset(start.Weekday())))
+		for i := 0; i < maxPeriods; i++ {
+			week := weekStart.AddDate(0, 0, 7*i*r.Interval)
+			candidates := make([]time.Time, 0, len(days))
+			for _, wd := range days {
+				candidates = append(candidates, week.AddDate(0, 0, int(mondayOffset(wd.Weekday))))
+			}
+			for _, c := range uniqueTimes(candidates) {
+				if !emit(c) {
+					return result
+				}
+			}
+		}
+
+	case FrequencyMonthly:
+		monthStart := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), 0, loc)
+		for i := 0; i < maxPeriods; i++ {
+			month := monthStart.AddDate(0, i*r.Interval, 0)
+			candidates := r.monthlyCandidates(month, start.Day())
+			for _, c := range candidates {
+				if !emit(c) {
+					return result
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// matchesDay reports whether a day of a daily rule passes its BYDAY and
+// BYMONTHDAY filters.
+func (r *Recurrence) matchesDay(t time.Time) bool {
+	if len(r.ByDay) > 0 {
+		found := false
+		for _, wd := range r.ByDay {
+			if wd.Weekday == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return r.monthDays(month.AddDate(0, 1, -1).Day())[t.Day()]
+	}
+	return true
+}
+
+func (r *Recurrence) monthlyCandidates(month time.Time, defaultDay int) []time.Time {
+	daysInMonth := month.AddDate(0, 1, -1).Day()
+
+	var days map[int]bool
+	switch {
+	case len(r.ByDay) > 0 && len(r.ByMonthDay) > 0:
+		byMonthDay := r.monthDays(daysInMonth)
+		days = make(map[int]bool)
+		for d := range r.weekdaysOf(month, daysInMonth) {
+			if byMonthDay[d] {
+				days[d] = true
+			}
+		}
+	case len(r.ByDay) > 0:
+		days = r.weekdaysOf(month, daysInMonth)
+	case len(r.ByMonthDay) > 0:
+		days = r.monthDays(daysInMonth)
+	default:
+		days = map[int]bool{}
+		if defaultDay <= daysInMonth {
+			days[defaultDay] = true
+		}
+	}
+
+	candidates := make([]time.Time, 0, len(days))
+	for d := range days {
+		candidates = append(candidates, month.AddDate(0, 0, d-1))
+	}
+	sortTimes(candidates)
+	return candidates
+}
+
+// weekdaysOf returns the days of the month that BYDAY selects.
+func (r *Recurrence) weekdaysOf(month time.Time, daysInMonth int) map[int]bool {
+	days := make(map[int]bool)
+	for _, wd := range r.ByDay {
+		var matches []int
+		for d := 1; d <= daysInMonth; d++ {
+			if month.AddDate(0, 0, d-1).Weekday() == wd.Weekday {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case wd.N == 0:
+			for _, d := range matches {
+				days[d] = true
+			}
+		case wd.N > 0 && wd.N <= len(matches):
+			days[matches[wd.N-1]] = true
+		case wd.N < 0 && -wd.N <= len(matches):
+			days[matches[len(matches)+wd.N]] = true
+		}
+	}
+	return days
+}
+
+// monthDays returns the days of a month of daysInMonth days that
+// BYMONTHDAY selects, counting negative values from the end.
+func (r *Recurrence) monthDays(daysInMonth int) map[int]bool {
+	days := make(map[int]bool)
+	for _, d := range r.ByMonthDay {
+		if d < 0 {
+			d = daysInMonth + d + 1
+		}
+		if d >= 1 && d <= daysInMonth {
+			days[d] = true
+		}
+	}
+	return days
+}
+
+func mondayOffset(wd time.Weekday) time.Weekday {
+	return (wd + 6) % 7
+}
+
+func sortTimes(ts []time.Time) {
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+}
+
+// uniqueTimes sorts ts and drops repeats, so a day listed twice in a rule
+// counts once against COUNT.
+func uniqueTimes(ts []time.Time) []time.Time {
+	sortTimes(ts)
+	result := ts[:0]
+	for _, t := range ts {
+		if len(result) == 0 || !t.Equal(result[len(result)-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (s *Series) Recurrence() (*Recurrence, error) {
+	r, err := ParseRRule(s.RRule)
+	if err != nil {
+		return nil, err
+	}
+	if s.Until != nil && (r.Until == nil || s.Until.Before(*r.Until)) {
+		until := s.Until.UTC()
+		r.Until = &until
+	}
+	return r, nil
+}
+
+// Occurrences expands the series from dtstart, dropping excluded dates.
+func (s *Series) Occurrences(dtstart time.Time, loc *time.Location, horizon time.Time, limit int) ([]time.Time, error) {
+	r, err := s.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	starts := r.Expand(dtstart, loc, horizon, 0)
+	result := make([]time.Time, 0, len(starts))
+	for _, t := range starts {
+		if s.IsExcluded(t) {
+			continue
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func (s *Series) IsExcluded(t time.Time) bool {
+	for _, ex := range s.ExDates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Series) Exclude(t time.Time) {
+	if s.IsExcluded(t) {
+		return
+	}
+	s.ExDates = append(s.ExDates, t.UTC())
+	s.Timestamp.Touch()
+}
+
+// EndBefore stops the series right before t, used when cancelling or
+// splitting off "this and following" occurrences.
+func (s *Series) EndBefore(t time.Time) {
+	until := t.UTC().Add(-time.Second)
+	s.Until = &until
+	s.Timestamp.Touch()
+}
+
+func ParseEditScope(v string) (EditScope, error) {
+	switch EditScope(v) {
+	case "":
+		return ScopeThis, nil
+	case ScopeThis, ScopeFollowing, ScopeAll:
+		return EditScope(v), nil
+	default:
+		return "", ErrInvalidEditScope
+	}
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	// Wednesday, 1 January 2025.
+	dtstart := time.Date(2025, 1, 1, 19, 0, 0, 0, time.UTC)
+	horizon := dtstart.AddDate(2, 0, 0)
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		{
+			name: "daily",
+			rule: "FREQ=DAILY;COUNT=3",
+			want: []string{"2025-01-01", "2025-01-02", "2025-01-03"},
+		},
+		{
+			name: "daily on weekdays",
+			rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=6",
+			want: []string{"2025-01-01", "2025-01-02", "2025-01-03", "2025-01-06", "2025-01-07", "2025-01-08"},
+		},
+		{
+			name: "daily on a day of the month",
+			rule: "FREQ=DAILY;BYMONTHDAY=-1;COUNT=3",
+			want: []string{"2025-01-31", "2025-02-28", "2025-03-31"},
+		},
+		{
+			name: "weekly with a repeated day",
+			rule: "FREQ=WEEKLY;BYDAY=WE,WE,FR;COUNT=4",
+			want: []string{"2025-01-01", "2025-01-03", "2025-01-08", "2025-01-10"},
+		},
+		{
+			name: "monthly on the first Monday",
+			rule: "FREQ=MONTHLY;BYDAY=1MO;COUNT=3",
+			want: []string{"2025-01-06", "2025-02-03", "2025-03-03"},
+		},
+		{
+			name: "monthly Friday the 13th",
+			rule: "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3",
+			want: []string{"2025-06-13", "2026-02-13", "2026-03-13"},
+		},
+		{
+			name: "monthly with a repeated day of the month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=1,1,15;COUNT=4",
+			want: []string{"2025-01-01", "2025-01-15", "2025-02-01", "2025-02-15"},
+		},
+		{
+			name: "monthly with BYDAY selecting the same day twice",
+			rule: "FREQ=MONTHLY;BYDAY=MO,1MO;COUNT=5",
+			want: []string{"2025-01-06", "2025-01-13", "2025-01-20", "2025-01-27", "2025-02-03"},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: time.Date(2025, 1, 31, 19, 0, 0, 0, time.UTC),
+			want:  []string{"2025-01-31", "2025-03-31", "2025-05-31"},
+		},
+		{
+			name: "until",
+			rule: "FREQ=WEEKLY;UNTIL=20250115T190000Z",
+			want: []string{"2025-01-01", "2025-01-08", "2025-01-15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule: %v", err)
+			}
+			start := tt.start
+			if start.IsZero() {
+				start = dtstart
+			}
+
+			got := r.Expand(start, time.UTC, horizon, 0)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i, g := range got {
+				if day := g.Format(time.DateOnly); day != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, day, tt.want[i])
+				}
+				if g.Hour() != 19 {
+					t.Errorf("occurrence %d at %s, want 19:00", i, g.Format(time.TimeOnly))
+				}
+			}
+		})
+	}
+}
+
+func TestExpandKeepsWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	r, err := ParseRRule("FREQ=WEEKLY;COUNT=3")
+	if err != nil {
+		t.Fatalf("ParseRRule: %v", err)
+	}
+
+	// The clocks go forward on 30 March 2025.
+	dtstart := time.Date(2025, 3, 23, 19, 0, 0, 0, loc)
+	for _, got := range r.Expand(dtstart, loc, dtstart.AddDate(1, 0, 0), 0) {
+		if h := got.In(loc).Hour(); h != 19 {
+			t.Errorf("%s is at %d:00 local time, want 19:00", got, h)
+		}
+	}
+}
+
+func TestParseRRuleRejects(t *testing.T) {
+	rules := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYDAY=-1FR",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYDAY=6MO",
+	}
+	for _, rule := range rules {
+		if _, err := ParseRRule(rule); !errors.Is(err, ErrInvalidRRule) {
+			t.Errorf("ParseRRule(%q) = %v, want ErrInvalidRRule", rule, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// Reminder is one scheduled reminder task for an event. It is sent to every
+// attendee when it fires.
+type Reminder struct {
+	ID        shared.ID
+	EventID   shared.ID
+	At        time.Time
+	Offset    time.Duration
+	TaskID    string
+	Status    ReminderStatus
+	CreatedAt time.Time
+}
+
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "pending"
+	ReminderSent      ReminderStatus = "sent"
+	ReminderCancelled ReminderStatus = "cancelled"
+)
+
+const (
+	reminderOffsetsKey = "reminder_offsets"
+	maxReminderOffsets = 10
+	maxReminderOffset  = 30 * 24 * time.Hour
+)
+
+var DefaultReminderOffsets = []time.Duration{
+	24 * time.Hour,
+	3 * time.Hour,
+	30 * time.Minute,
+}
+
+var (
+	ErrInvalidReminderOffsets = errors.New("reminder offsets must be between 1 minute and 30 days, at most 10")
+	ErrReminderNotFound       = errors.New("reminder not found")
+)
+
+func NewReminder(eventID shared.ID, startsAt time.Time, offset time.Duration) *Reminder {
+	return &Reminder{
+		ID:        shared.NewID(),
+		EventID:   eventID,
+		At:        startsAt.Add(-offset),
+		Offset:    offset,
+		Status:    ReminderPending,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// ReminderOffsets returns how long before the start reminders go out. Events
+// without their own setting use DefaultReminderOffsets; an empty setting
+// turns reminders off.
+func (e *Event) ReminderOffsets() []time.Duration {
+	raw, ok := e.Settings[reminderOffsetsKey]
+	if !ok || raw == nil {
+		return DefaultReminderOffsets
+	}
+
+	var minutes []int
+	switch v := raw.(type) {
+	case []int:
+		minutes = v
+	case []interface{}:
+		for _, m := range v {
+			if f, ok := m.(float64); ok {
+				minutes = append(minutes, int(f))
+			}
+		}
+	default:
+		return DefaultReminderOffsets
+	}
+
+	offsets := make([]time.Duration, 0, len(minutes))
+	for _, m := range minutes {
+		offsets = append(offsets, time.Duration(m)*time.Minute)
+	}
+	return offsets
+}
+
+// SetReminderOffsets stores the event's reminder offsets, rounded to whole
+// minutes. A nil slice restores the defaults.
+func (e *Event) SetReminderOffsets(offsets []time.Duration) error {
+	if e.Settings == nil {
+		e.Settings = make(map[string]interface{})
+	}
+	if offsets == nil {
+		delete(e.Settings, reminderOffsetsKey)
+		e.Timestamp.Touch()
+		return nil
+	}
+	if len(offsets) > maxReminderOffsets {
+		return ErrInvalidReminderOffsets
+	}
+
+	seen := make(map[int]bool, len(offsets))
+	minutes := make([]int, 0, len(offsets))
+	for _, d := range offsets {
+		if d < time.Minute || d > maxReminderOffset {
+			return ErrInvalidReminderOffsets
+		}
+		m := int(d / time.Minute)
+		if !seen[m] {
+			seen[m] = true
+			minutes = append(minutes, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(minutes)))
+
+	e.Settings[reminderOffsetsKey] = minutes
+	e.Timestamp.Touch()
+	return nil
+}
@@ -0,0 +1,138 @@
+package forms
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// SchemaDiff describes how a form version differs from an earlier one.
+type SchemaDiff struct {
+	FromVersion   int           `json:"from_version"`
+	ToVersion     int           `json:"to_version"`
+	Added         []string      `json:"added"`
+	Removed       []string      `json:"removed"`
+	Changed       []FieldChange `json:"changed"`
+	NewlyRequired []string      `json:"newly_required"`
+	RulesChanged  bool          `json:"rules_changed"`
+	// Breaking is set when earlier answers may no longer be valid.
+	Breaking bool `json:"breaking"`
+}
+
+type FieldChange struct {
+	Field   string   `json:"field"`
+	Changes []string `json:"changes"`
+}
+
+func Diff(from, to *Form) (*SchemaDiff, error) {
+	oldSchema, err := ParseSchema(from.Schema)
+	if err != nil {
+		return nil, err
+	}
+	newSchema, err := ParseSchema(to.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{
+		FromVersion:   from.Version,
+		ToVersion:     to.Version,
+		Added:         []string{},
+		Removed:       []string{},
+		Changed:       []FieldChange{},
+		NewlyRequired: []string{},
+		RulesChanged:  !jsonEqual(from.Rules, to.Rules),
+	}
+
+	for _, f := range oldSchema.Fields {
+		if _, ok := newSchema.Field(f.ID); !ok {
+			diff.Removed = append(diff.Removed, f.ID)
+		}
+	}
+
+	for i := range newSchema.Fields {
+		f := &newSchema.Fields[i]
+		old, ok := oldSchema.Field(f.ID)
+		if !ok {
+			diff.Added = append(diff.Added, f.ID)
+			if f.Required {
+				diff.NewlyRequired = append(diff.NewlyRequired, f.ID)
+			}
+			continue
+		}
+
+		if f.Required && !old.Required {
+			diff.NewlyRequired = append(diff.NewlyRequired, f.ID)
+		}
+		if changes := fieldChanges(old, f); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, FieldChange{Field: f.ID, Changes: changes})
+			for _, c := range changes {
+				switch c {
+				case "type", "constraints":
+					diff.Breaking = true
+				case "options":
+					// Adding options keeps old answers valid; removing
+					// them does not.
+					if !optionsSubset(old.Options, f.Options) {
+						diff.Breaking = true
+					}
+				}
+			}
+		}
+	}
+
+	if len(diff.NewlyRequired) > 0 || diff.RulesChanged {
+		diff.Breaking = true
+	}
+
+	return diff, nil
+}
+
+func fieldChanges(old, f *Field) []string {
+	var changes []string
+	if old.Label != f.Label || old.Placeholder != f.Placeholder {
+		changes = append(changes, "label")
+	}
+	if old.Type != f.Type {
+		changes = append(changes, "type")
+	}
+	if old.Required != f.Required {
+		changes = append(changes, "required")
+	}
+	if !optionValuesEqual(old.Options, f.Options) {
+		changes = append(changes, "options")
+	}
+	if !reflect.DeepEqual(old.MinLength, f.MinLength) || !reflect.DeepEqual(old.MaxLength, f.MaxLength) ||
+		!reflect.DeepEqual(old.Min, f.Min) || !reflect.DeepEqual(old.Max, f.Max) ||
+		old.Pattern != f.Pattern || old.Format != f.Format {
+		changes = append(changes, "constraints")
+	}
+	if old.Section != f.Section {
+		changes = append(changes, "section")
+	}
+	return changes
+}
+
+func optionValuesEqual(a, b []Option) bool {
+	return len(a) == len(b) && optionsSubset(a, b)
+}
+
+// optionsSubset reports whether every option value of a is also in b.
+func optionsSubset(a, b []Option) bool {
+	values := make(map[string]bool, len(b))
+	for _, o := range b {
+		values[o.Value] = true
+	}
+	for _, o := range a {
+		if !values[o.Value] {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonEqual(a, b []byte) bool {
+	var x, y interface{}
+	_ = json.Unmarshal(a, &x)
+	_ = json.Unmarshal(b, &y)
+	return reflect.DeepEqual(x, y)
+}
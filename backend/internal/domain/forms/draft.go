@@ -0,0 +1,42 @@
+package forms
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrDraftConflict    = errors.New("draft was changed elsewhere")
+	ErrAlreadySubmitted = errors.New("response already submitted")
+)
+
+// DraftConflictError carries the stored draft that a save with an outdated
+// revision would have overwritten.
+type DraftConflictError struct {
+	Current *Response
+}
+
+func (e *DraftConflictError) Error() string {
+	return ErrDraftConflict.Error()
+}
+
+func (e *DraftConflictError) Unwrap() error {
+	return ErrDraftConflict
+}
+
+// SaveDraft replaces the draft answers. base is the revision the client
+// started from; a nil base skips the conflict check. Submitted responses
+// are never turned back into drafts.
+func (r *Response) SaveDraft(answers json.RawMessage, base *int) error {
+	if r.Status == ResponseStatusSubmitted {
+		return ErrAlreadySubmitted
+	}
+	if base != nil && *base != r.Revision {
+		return &DraftConflictError{Current: r}
+	}
+
+	r.Answers = answers
+	r.Revision++
+	r.Timestamp.Touch()
+	return nil
+}
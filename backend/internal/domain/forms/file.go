@@ -0,0 +1,114 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// MaxFileSize caps every upload; DefaultFileSize applies to file fields
+// without their own max_size.
+const (
+	MaxFileSize     int64 = 20 << 20
+	DefaultFileSize int64 = 10 << 20
+)
+
+var (
+	mimePattern = regexp.MustCompile(`^[a-z0-9.+-]+/([a-z0-9.+-]+|\*)$`)
+	extPattern  = regexp.MustCompile(`^\.[a-z0-9]{1,8}$`)
+)
+
+var (
+	ErrFileNotFound       = errors.New("file not found")
+	ErrNotFileField       = errors.New("field does not accept files")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// File is an upload for a file field. It belongs to the uploader until a
+// response references it, and is deleted together with that response.
+type File struct {
+	ID          shared.ID
+	EventID     shared.ID
+	FormID      shared.ID
+	UserID      shared.ID
+	ResponseID  *shared.ID
+	FieldID     string
+	Name        string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	CreatedAt   time.Time
+}
+
+func NewFile(form *Form, userID shared.ID, fieldID, name, contentType string, size int64) *File {
+	id := shared.NewID()
+	ext := strings.ToLower(path.Ext(name))
+	if !extPattern.MatchString(ext) {
+		ext = ""
+	}
+
+	return &File{
+		ID:          id,
+		EventID:     form.EventID,
+		FormID:      form.ID,
+		UserID:      userID,
+		FieldID:     fieldID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  fmt.Sprintf("forms/%s/%s%s", form.EventID, id, ext),
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// CheckUpload reports whether a file of the given type and size may be
+// uploaded to the field.
+func (f *Field) CheckUpload(contentType string, size int64) error {
+	if f.Type != FieldFile {
+		return ErrNotFileField
+	}
+
+	limit := f.MaxSize
+	if limit == 0 {
+		limit = DefaultFileSize
+	}
+	if size > limit {
+		return fmt.Errorf("%w: limit is %d bytes", ErrFileTooLarge, limit)
+	}
+
+	if len(f.Accept) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ErrFileTypeNotAllowed
+	}
+	for _, a := range f.Accept {
+		if a == mediaType || strings.HasSuffix(a, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*")) {
+			return nil
+		}
+	}
+	return ErrFileTypeNotAllowed
+}
+
+// FileAnswers returns the file IDs referenced by answers, keyed by field.
+func (s *Schema) FileAnswers(answers map[string]interface{}) map[string]shared.ID {
+	files := make(map[string]shared.ID)
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Type != FieldFile {
+			continue
+		}
+		if id, ok := answers[f.ID].(string); ok && id != "" {
+			files[f.ID] = shared.ID(id)
+		}
+	}
+	return files
+}
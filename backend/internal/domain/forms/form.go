@@ -17,12 +17,19 @@ type Form struct {
 	shared.Timestamp
 }
 
+// Response is one user's answers to one form version. PreviousID links it
+// to the response it replaced on an earlier version. Stale marks responses
+// that no longer satisfy the active version and must be submitted again.
+// Revision counts draft saves and detects concurrent edits.
 type Response struct {
-	ID      shared.ID
-	FormID  shared.ID
-	UserID  shared.ID
-	Status  ResponseStatus
-	Answers json.RawMessage
+	ID         shared.ID
+	FormID     shared.ID
+	UserID     shared.ID
+	Status     ResponseStatus
+	Answers    json.RawMessage
+	PreviousID *shared.ID
+	Stale      bool
+	Revision   int
 	shared.Timestamp
 }
 
@@ -37,13 +44,17 @@ var (
 	ErrFormNotFound     = errors.New("form not found")
 	ErrResponseNotFound = errors.New("response not found")
 	ErrInvalidSchema    = errors.New("invalid form schema")
+	ErrInvalidRules     = errors.New("invalid form rules")
+	ErrInvalidAnswers   = errors.New("invalid answers")
+	ErrFormInactive     = errors.New("form is no longer accepting responses")
+	ErrVersionNotFound  = errors.New("form version not found")
 )
 
-func NewForm(eventID shared.ID, schema, rules json.RawMessage) *Form {
+func NewForm(eventID shared.ID, version int, schema, rules json.RawMessage) *Form {
 	return &Form{
 		ID:        shared.NewID(),
 		EventID:   eventID,
-		Version:   1,
+		Version:   version,
 		Schema:    schema,
 		Rules:     rules,
 		Active:    true,
@@ -62,6 +73,25 @@ func NewResponse(formID, userID shared.ID) *Response {
 	}
 }
 
+// Migrate copies a submitted response onto a newer form version.
+func (r *Response) Migrate(formID shared.ID, answers json.RawMessage) *Response {
+	previousID := r.ID
+	return &Response{
+		ID:         shared.NewID(),
+		FormID:     formID,
+		UserID:     r.UserID,
+		Status:     ResponseStatusSubmitted,
+		Answers:    answers,
+		PreviousID: &previousID,
+		Timestamp:  shared.NewTimestamp(),
+	}
+}
+
+func (r *Response) MarkStale() {
+	r.Stale = true
+	r.Timestamp.Touch()
+}
+
 func (r *Response) Submit() {
 	r.Status = ResponseStatusSubmitted
 	r.Timestamp.Touch()
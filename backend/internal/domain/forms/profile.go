@@ -0,0 +1,45 @@
+package forms
+
+// Prefill maps saved profile values onto the fields linked to them. Values
+// that the field would reject, such as a retired select option, are left
+// out.
+func (s *Schema) Prefill(profile map[string]interface{}) map[string]interface{} {
+	prefill := make(map[string]interface{})
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Profile == "" {
+			continue
+		}
+
+		value, ok := profile[f.Profile]
+		if !ok || isEmpty(value) {
+			continue
+		}
+
+		verr := &ValidationError{}
+		f.validate(value, verr)
+		if len(verr.Fields) == 0 {
+			prefill[f.ID] = value
+		}
+	}
+	return prefill
+}
+
+// ProfileValues picks the answers to remember, keyed by profile key.
+// Fields hidden by the rule state and empty answers are skipped.
+func (s *Schema) ProfileValues(answers map[string]interface{}, state *State) map[string]interface{} {
+	values := make(map[string]interface{})
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Profile == "" || state != nil && state.Hidden[f.ID] {
+			continue
+		}
+
+		value, ok := answers[f.ID]
+		if !ok || isEmpty(value) {
+			continue
+		}
+		values[f.Profile] = value
+	}
+	return values
+}
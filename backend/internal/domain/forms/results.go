@@ -0,0 +1,212 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid response filter")
+
+// CheckFilter makes sure responses can be filtered by the given answers.
+// File answers hold upload IDs and cannot be filtered on.
+func (s *Schema) CheckFilter(answers map[string]string) error {
+	for id := range answers {
+		f, ok := s.Field(id)
+		if !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, id)
+		}
+		if f.Type == FieldFile {
+			return fmt.Errorf("%w: cannot filter by file field %q", ErrInvalidFilter, id)
+		}
+	}
+	return nil
+}
+
+// Header returns the export column titles, one per field.
+func (s *Schema) Header() []string {
+	header := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		header[i] = f.Label
+		if header[i] == "" {
+			header[i] = f.ID
+		}
+	}
+	return header
+}
+
+// Cells renders answers as export cells in field order. Choices are shown
+// by their labels.
+func (s *Schema) Cells(answers map[string]interface{}) []string {
+	cells := make([]string, len(s.Fields))
+	for i := range s.Fields {
+		value, ok := answers[s.Fields[i].ID]
+		if ok && s.Fields[i].answered(value) {
+			cells[i] = s.Fields[i].cell(value)
+		}
+	}
+	return cells
+}
+
+// answered differs from isEmpty for checkboxes: an unticked box is an
+// answer too.
+func (f *Field) answered(value interface{}) bool {
+	if f.Type == FieldCheckbox {
+		_, ok := value.(bool)
+		return ok
+	}
+	return !isEmpty(value)
+}
+
+func (f *Field) cell(value interface{}) string {
+	switch f.Type {
+	case FieldNumber:
+		if n, ok := toNumber(value); ok {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+	case FieldCheckbox:
+		if b, ok := value.(bool); ok {
+			return YesNo(b)
+		}
+	case FieldSelect, FieldRadio:
+		if v, ok := value.(string); ok {
+			return f.optionLabel(v)
+		}
+	case FieldMultiselect:
+		if items, ok := value.([]interface{}); ok {
+			labels := make([]string, 0, len(items))
+			for _, item := range items {
+				labels = append(labels, f.optionLabel(fmt.Sprint(item)))
+			}
+			return strings.Join(labels, "; ")
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// YesNo is how a ticked or unticked box reads in exports and summaries.
+func YesNo(b bool) string {
+	if b {
+		return "Да"
+	}
+	return "Нет"
+}
+
+func (f *Field) optionLabel(value string) string {
+	for _, o := range f.Options {
+		if o.Value == value && o.Label != "" {
+			return o.Label
+		}
+	}
+	return value
+}
+
+// FieldSummary aggregates the answers to one field. Choice fields count
+// each option, number fields get basic statistics.
+type FieldSummary struct {
+	FieldID  string         `json:"field_id"`
+	Label    string         `json:"label"`
+	Type     FieldType      `json:"type"`
+	Answered int            `json:"answered"`
+	Options  []OptionCount  `json:"options,omitempty"`
+	Numbers  *NumberSummary `json:"numbers,omitempty"`
+}
+
+type OptionCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+type NumberSummary struct {
+	Sum    float64 `json:"sum"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// Summarize aggregates a set of decoded responses field by field. Answers
+// that no longer fit the field, such as retired options, are counted as
+// answered but left out of the breakdown.
+func (s *Schema) Summarize(responses []map[string]interface{}) []FieldSummary {
+	summaries := make([]FieldSummary, len(s.Fields))
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		sum := FieldSummary{FieldID: f.ID, Label: f.Label, Type: f.Type}
+
+		counts := make(map[string]int)
+		var numbers []float64
+		for _, answers := range responses {
+			value, ok := answers[f.ID]
+			if !ok || !f.answered(value) {
+				continue
+			}
+			sum.Answered++
+
+			switch f.Type {
+			case FieldSelect, FieldRadio:
+				if v, ok := value.(string); ok {
+					counts[v]++
+				}
+			case FieldMultiselect:
+				if items, ok := value.([]interface{}); ok {
+					for _, item := range items {
+						if v, ok := item.(string); ok {
+							counts[v]++
+						}
+					}
+				}
+			case FieldCheckbox:
+				if b, ok := value.(bool); ok {
+					counts[strconv.FormatBool(b)]++
+				}
+			case FieldNumber:
+				if n, ok := toNumber(value); ok {
+					numbers = append(numbers, n)
+				}
+			}
+		}
+
+		switch f.Type {
+		case FieldSelect, FieldRadio, FieldMultiselect:
+			sum.Options = make([]OptionCount, len(f.Options))
+			for j, o := range f.Options {
+				sum.Options[j] = OptionCount{Value: o.Value, Label: o.Label, Count: counts[o.Value]}
+			}
+		case FieldCheckbox:
+			sum.Options = []OptionCount{
+				{Value: "true", Label: YesNo(true), Count: counts["true"]},
+				{Value: "false", Label: YesNo(false), Count: counts["false"]},
+			}
+		case FieldNumber:
+			sum.Numbers = summarizeNumbers(numbers)
+		}
+
+		summaries[i] = sum
+	}
+	return summaries
+}
+
+func summarizeNumbers(numbers []float64) *NumberSummary {
+	if len(numbers) == 0 {
+		return nil
+	}
+	sort.Float64s(numbers)
+
+	sum := &NumberSummary{Min: numbers[0], Max: numbers[len(numbers)-1]}
+	for _, n := range numbers {
+		sum.Sum += n
+	}
+	sum.Mean = sum.Sum / float64(len(numbers))
+
+	mid := len(numbers) / 2
+	if len(numbers)%2 == 0 {
+		sum.Median = (numbers[mid-1] + numbers[mid]) / 2
+	} else {
+		sum.Median = numbers[mid]
+	}
+	return sum
+}
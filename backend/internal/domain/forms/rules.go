@@ -0,0 +1,492 @@
+package forms
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Rule changes a form while it is filled in. When every condition in When
+// holds (an empty When always holds) the action is applied to Target, which
+// is a field or, for show, hide and skip_to, a section.
+//
+// Rules are applied in order, so a later rule wins over an earlier one.
+// Fields targeted by a show rule start hidden. Hidden fields count as
+// unanswered in conditions, and the whole evaluation is repeated until the
+// result stops changing.
+type Rule struct {
+	Target  string      `json:"target"`
+	Action  RuleAction  `json:"action"`
+	When    []Condition `json:"when,omitempty"`
+	From    string      `json:"from,omitempty"`
+	Compute *Compute    `json:"compute,omitempty"`
+}
+
+type RuleAction string
+
+const (
+	ActionShow     RuleAction = "show"
+	ActionHide     RuleAction = "hide"
+	ActionRequire  RuleAction = "require"
+	ActionOptional RuleAction = "optional"
+	// ActionCompute sets Target to the value of Compute.
+	ActionCompute RuleAction = "compute"
+	// ActionSkipTo hides every section after From and before Target.
+	ActionSkipTo RuleAction = "skip_to"
+)
+
+// Condition tests an answer or a fact about the respondent's registration.
+// Equals is the short form of {"op": "equals", "value": ...}.
+type Condition struct {
+	Source ConditionSource `json:"source,omitempty"`
+	Field  string          `json:"field,omitempty"`
+	Op     ConditionOp     `json:"op,omitempty"`
+	Value  interface{}     `json:"value,omitempty"`
+	Equals interface{}     `json:"equals,omitempty"`
+}
+
+type ConditionSource string
+
+const (
+	SourceAnswer             ConditionSource = "answer"
+	SourceTicketType         ConditionSource = "ticket_type"
+	SourceRegistrationStatus ConditionSource = "registration_status"
+)
+
+type ConditionOp string
+
+const (
+	OpEquals    ConditionOp = "equals"
+	OpNotEquals ConditionOp = "not_equals"
+	OpIn        ConditionOp = "in"
+	OpNotIn     ConditionOp = "not_in"
+	OpContains  ConditionOp = "contains"
+	OpEmpty     ConditionOp = "empty"
+	OpNotEmpty  ConditionOp = "not_empty"
+	OpGt        ConditionOp = "gt"
+	OpGte       ConditionOp = "gte"
+	OpLt        ConditionOp = "lt"
+	OpLte       ConditionOp = "lte"
+)
+
+// Compute derives a field from others: sum adds numbers, count counts
+// answered fields and concat joins text with Separator.
+type Compute struct {
+	Op        ComputeOp `json:"op"`
+	Fields    []string  `json:"fields"`
+	Separator string    `json:"separator,omitempty"`
+}
+
+type ComputeOp string
+
+const (
+	ComputeSum    ComputeOp = "sum"
+	ComputeCount  ComputeOp = "count"
+	ComputeConcat ComputeOp = "concat"
+)
+
+// Context holds the facts about the respondent that conditions can use.
+type Context struct {
+	TicketType         string `json:"ticket_type,omitempty"`
+	RegistrationStatus string `json:"registration_status,omitempty"`
+}
+
+// State is the outcome of evaluating rules against a set of answers.
+// Required lists only visible fields.
+type State struct {
+	Hidden          map[string]bool        `json:"hidden"`
+	Required        map[string]bool        `json:"required"`
+	Computed        map[string]interface{} `json:"computed"`
+	SkippedSections []string               `json:"skipped_sections"`
+}
+
+// ParseRules decodes rules and checks them against the schema. Errors wrap
+// ErrInvalidRules.
+func ParseRules(raw json.RawMessage, schema *Schema) ([]Rule, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil, nil
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	for i := range rules {
+		if err := rules[i].check(schema); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %v", ErrInvalidRules, i, err)
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *Rule) check(schema *Schema) error {
+	_, isField := schema.Field(r.Target)
+	isSection := schema.sectionIndex(r.Target) >= 0
+
+	switch r.Action {
+	case ActionShow, ActionHide:
+		if !isField && !isSection {
+			return fmt.Errorf("unknown target %q", r.Target)
+		}
+	case ActionRequire, ActionOptional:
+		if !isField {
+			return fmt.Errorf("unknown field %q", r.Target)
+		}
+	case ActionCompute:
+		if !isField {
+			return fmt.Errorf("unknown field %q", r.Target)
+		}
+		if r.Compute == nil || len(r.Compute.Fields) == 0 {
+			return fmt.Errorf("compute needs fields")
+		}
+		switch r.Compute.Op {
+		case ComputeSum, ComputeCount, ComputeConcat:
+		default:
+			return fmt.Errorf("unknown compute op %q", r.Compute.Op)
+		}
+		for _, id := range r.Compute.Fields {
+			if _, ok := schema.Field(id); !ok || id == r.Target {
+				return fmt.Errorf("invalid compute field %q", id)
+			}
+		}
+	case ActionSkipTo:
+		from, to := schema.sectionIndex(r.From), schema.sectionIndex(r.Target)
+		if from < 0 || to < 0 || from >= to {
+			return fmt.Errorf("skip_to needs sections from %q before %q", r.From, r.Target)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	for i := range r.When {
+		if err := r.When[i].check(schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Condition) check(schema *Schema) error {
+	switch c.Source {
+	case "", SourceAnswer:
+		if _, ok := schema.Field(c.Field); !ok {
+			return fmt.Errorf("condition on unknown field %q", c.Field)
+		}
+	case SourceTicketType, SourceRegistrationStatus:
+	default:
+		return fmt.Errorf("unknown condition source %q", c.Source)
+	}
+
+	switch c.op() {
+	case OpEquals, OpNotEquals, OpContains, OpEmpty, OpNotEmpty, OpGt, OpGte, OpLt, OpLte:
+	case OpIn, OpNotIn:
+		if _, ok := c.value().([]interface{}); !ok {
+			return fmt.Errorf("%s needs a list value", c.Op)
+		}
+	default:
+		return fmt.Errorf("unknown condition op %q", c.Op)
+	}
+
+	return nil
+}
+
+func (c *Condition) op() ConditionOp {
+	if c.Op == "" {
+		return OpEquals
+	}
+	return c.Op
+}
+
+func (c *Condition) value() interface{} {
+	if c.Op == "" && c.Equals != nil {
+		return c.Equals
+	}
+	return c.Value
+}
+
+func (c *Condition) holds(answers map[string]interface{}, ctx Context) bool {
+	var actual interface{}
+	switch c.Source {
+	case SourceTicketType:
+		actual = ctx.TicketType
+	case SourceRegistrationStatus:
+		actual = ctx.RegistrationStatus
+	default:
+		actual = answers[c.Field]
+	}
+
+	want := c.value()
+	switch c.op() {
+	case OpEquals:
+		return valuesEqual(actual, want)
+	case OpNotEquals:
+		return !valuesEqual(actual, want)
+	case OpIn, OpNotIn:
+		found := false
+		if list, ok := want.([]interface{}); ok {
+			for _, v := range list {
+				if valuesEqual(actual, v) {
+					found = true
+					break
+				}
+			}
+		}
+		return found == (c.op() == OpIn)
+	case OpContains:
+		list, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range list {
+			if valuesEqual(v, want) {
+				return true
+			}
+		}
+		return false
+	case OpEmpty:
+		return isUnanswered(actual)
+	case OpNotEmpty:
+		return !isUnanswered(actual)
+	case OpGt, OpGte, OpLt, OpLte:
+		cmp, ok := compareValues(actual, want)
+		if !ok {
+			return false
+		}
+		switch c.op() {
+		case OpGt:
+			return cmp > 0
+		case OpGte:
+			return cmp >= 0
+		case OpLt:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// isUnanswered is like isEmpty, but a false checkbox is an answer.
+func isUnanswered(v interface{}) bool {
+	if _, ok := v.(bool); ok {
+		return false
+	}
+	return isEmpty(v)
+}
+
+// valuesEqual compares numbers by value, so "42", 42 and 42.0 are equal,
+// and everything else by its JSON form.
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return x == y
+		}
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && sa == sb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compareValues(a, b interface{}) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+// Evaluate applies rules to answers and returns which fields are hidden and
+// required and the computed values.
+func Evaluate(schema *Schema, rules []Rule, answers map[string]interface{}, ctx Context) *State {
+	var state *State
+	for i := 0; i <= len(schema.Fields)+len(schema.Sections); i++ {
+		next := evaluateOnce(schema, rules, effectiveAnswers(answers, state), ctx)
+		if state != nil && reflect.DeepEqual(state, next) {
+			break
+		}
+		state = next
+	}
+	return state
+}
+
+func evaluateOnce(schema *Schema, rules []Rule, answers map[string]interface{}, ctx Context) *State {
+	state := &State{
+		Hidden:          make(map[string]bool),
+		Required:        make(map[string]bool),
+		Computed:        make(map[string]interface{}),
+		SkippedSections: []string{},
+	}
+
+	// Show targets start hidden, and computed fields are never taken from
+	// the respondent.
+	for _, r := range rules {
+		switch r.Action {
+		case ActionShow:
+			setHidden(schema, state, r.Target, true)
+		case ActionCompute:
+			state.Computed[r.Target] = nil
+		}
+	}
+	for _, f := range schema.Fields {
+		state.Required[f.ID] = f.Required
+	}
+
+	skipped := make(map[string]bool)
+	for _, r := range rules {
+		if !allHold(r.When, answers, ctx) {
+			continue
+		}
+
+		switch r.Action {
+		case ActionShow:
+			setHidden(schema, state, r.Target, false)
+		case ActionHide:
+			setHidden(schema, state, r.Target, true)
+		case ActionRequire:
+			state.Required[r.Target] = true
+		case ActionOptional:
+			state.Required[r.Target] = false
+		case ActionCompute:
+			state.Computed[r.Target] = r.Compute.apply(answers)
+		case ActionSkipTo:
+			from, to := schema.sectionIndex(r.From), schema.sectionIndex(r.Target)
+			for i := from + 1; i < to; i++ {
+				skipped[schema.Sections[i].ID] = true
+			}
+		}
+	}
+
+	for _, sec := range schema.Sections {
+		if skipped[sec.ID] {
+			state.SkippedSections = append(state.SkippedSections, sec.ID)
+			setHidden(schema, state, sec.ID, true)
+		}
+	}
+
+	for id, hidden := range state.Hidden {
+		if !hidden {
+			delete(state.Hidden, id)
+		}
+	}
+	for id, required := range state.Required {
+		if !required || state.Hidden[id] {
+			delete(state.Required, id)
+		}
+	}
+	for id := range state.Computed {
+		if state.Hidden[id] {
+			delete(state.Computed, id)
+		}
+	}
+
+	return state
+}
+
+func setHidden(schema *Schema, state *State, target string, hidden bool) {
+	if _, ok := schema.Field(target); ok {
+		state.Hidden[target] = hidden
+		return
+	}
+	for _, f := range schema.Fields {
+		if f.Section == target {
+			state.Hidden[f.ID] = hidden
+		}
+	}
+}
+
+func allHold(conds []Condition, answers map[string]interface{}, ctx Context) bool {
+	for i := range conds {
+		if !conds[i].holds(answers, ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// effectiveAnswers drops hidden fields and fills in computed ones.
+func effectiveAnswers(answers map[string]interface{}, state *State) map[string]interface{} {
+	if state == nil {
+		return answers
+	}
+	return state.Apply(answers)
+}
+
+// Apply returns a copy of answers without hidden fields and with computed
+// fields set, which is what gets stored on submit.
+func (s *State) Apply(answers map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(answers))
+	for id, v := range answers {
+		if !s.Hidden[id] {
+			result[id] = v
+		}
+	}
+	for id, v := range s.Computed {
+		result[id] = v
+	}
+	return result
+}
+
+func (c *Compute) apply(answers map[string]interface{}) interface{} {
+	switch c.Op {
+	case ComputeSum:
+		var sum float64
+		for _, id := range c.Fields {
+			if n, ok := toNumber(answers[id]); ok {
+				sum += n
+			}
+		}
+		return sum
+	case ComputeCount:
+		count := 0
+		for _, id := range c.Fields {
+			if !isUnanswered(answers[id]) {
+				count++
+			}
+		}
+		return count
+	case ComputeConcat:
+		parts := make([]string, 0, len(c.Fields))
+		for _, id := range c.Fields {
+			if v := answers[id]; !isUnanswered(v) {
+				parts = append(parts, stringify(v))
+			}
+		}
+		return strings.Join(parts, c.Separator)
+	}
+	return nil
+}
+
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,485 @@
+package forms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is the field list stored in Form.Schema. Fields may be grouped
+// into sections, which rules can skip over.
+type Schema struct {
+	Fields   []Field   `json:"fields"`
+	Sections []Section `json:"sections,omitempty"`
+}
+
+type Section struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type Field struct {
+	ID          string    `json:"id"`
+	Label       string    `json:"label"`
+	Type        FieldType `json:"type"`
+	Placeholder string    `json:"placeholder,omitempty"`
+	Required    bool      `json:"required,omitempty"`
+	Options     []Option  `json:"options,omitempty"`
+	MinLength   *int      `json:"min_length,omitempty"`
+	MaxLength   *int      `json:"max_length,omitempty"`
+	Min         *float64  `json:"min,omitempty"`
+	Max         *float64  `json:"max,omitempty"`
+	Pattern     string    `json:"pattern,omitempty"`
+	Format      Format    `json:"format,omitempty"`
+	Section     string    `json:"section,omitempty"`
+	// MaxSize (bytes) and Accept (MIME types, "image/*" allowed) limit
+	// uploads to file fields.
+	MaxSize int64    `json:"max_size,omitempty"`
+	Accept  []string `json:"accept,omitempty"`
+	// Profile links the field to a key of the user's saved profile data,
+	// such as "name", "email", "phone" or "company".
+	Profile string `json:"profile,omitempty"`
+}
+
+type Option struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+type FieldType string
+
+const (
+	FieldText        FieldType = "text"
+	FieldTextarea    FieldType = "textarea"
+	FieldEmail       FieldType = "email"
+	FieldPhone       FieldType = "phone"
+	FieldNumber      FieldType = "number"
+	FieldDate        FieldType = "date"
+	FieldSelect      FieldType = "select"
+	FieldRadio       FieldType = "radio"
+	FieldMultiselect FieldType = "multiselect"
+	FieldCheckbox    FieldType = "checkbox"
+	// FieldFile answers hold the ID of a file uploaded for the field.
+	FieldFile FieldType = "file"
+)
+
+// Format adds a format check to text fields.
+type Format string
+
+const (
+	FormatEmail Format = "email"
+	FormatPhone Format = "phone"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	// Field IDs are made from labels, which are often Cyrillic.
+	fieldIDPattern = regexp.MustCompile(`^\p{L}[\p{L}\p{N}_]{0,63}$`)
+	phonePattern   = regexp.MustCompile(`^\+?[0-9][0-9 ()\-]{5,22}$`)
+)
+
+// ParseSchema decodes and checks a form schema. Errors wrap ErrInvalidSchema.
+func ParseSchema(raw json.RawMessage) (*Schema, error) {
+	var schema Schema
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	if len(schema.Fields) == 0 {
+		return nil, fmt.Errorf("%w: no fields", ErrInvalidSchema)
+	}
+
+	seen := make(map[string]bool, len(schema.Fields)+len(schema.Sections))
+	for _, sec := range schema.Sections {
+		if !fieldIDPattern.MatchString(sec.ID) || seen[sec.ID] {
+			return nil, fmt.Errorf("%w: invalid or duplicate section id %q", ErrInvalidSchema, sec.ID)
+		}
+		seen[sec.ID] = true
+	}
+
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		if !fieldIDPattern.MatchString(f.ID) {
+			return nil, fmt.Errorf("%w: field %d: invalid id %q", ErrInvalidSchema, i, f.ID)
+		}
+		if seen[f.ID] {
+			return nil, fmt.Errorf("%w: field %q: duplicate id", ErrInvalidSchema, f.ID)
+		}
+		seen[f.ID] = true
+
+		if f.Section != "" && schema.sectionIndex(f.Section) < 0 {
+			return nil, fmt.Errorf("%w: field %q: unknown section %q", ErrInvalidSchema, f.ID, f.Section)
+		}
+		if err := f.check(); err != nil {
+			return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidSchema, f.ID, err)
+		}
+	}
+
+	return &schema, nil
+}
+
+func (f *Field) check() error {
+	switch f.Type {
+	case FieldText, FieldTextarea, FieldEmail, FieldPhone, FieldNumber, FieldDate, FieldCheckbox:
+		if len(f.Options) > 0 {
+			return fmt.Errorf("options are not allowed for %s", f.Type)
+		}
+	case FieldSelect, FieldRadio, FieldMultiselect:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("%s needs options", f.Type)
+		}
+		values := make(map[string]bool, len(f.Options))
+		for _, o := range f.Options {
+			if o.Value == "" || values[o.Value] {
+				return fmt.Errorf("option values must be unique and non-empty")
+			}
+			values[o.Value] = true
+		}
+	case FieldFile:
+		if len(f.Options) > 0 {
+			return fmt.Errorf("options are not allowed for %s", f.Type)
+		}
+		if f.MaxSize < 0 || f.MaxSize > MaxFileSize {
+			return fmt.Errorf("max_size must be between 0 and %d", MaxFileSize)
+		}
+		for _, a := range f.Accept {
+			if !mimePattern.MatchString(a) {
+				return fmt.Errorf("invalid accept type %q", a)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+	if f.Type != FieldFile && (f.MaxSize != 0 || len(f.Accept) > 0) {
+		return errors.New("max_size and accept are only allowed for file fields")
+	}
+	if f.Profile != "" && (f.Type == FieldFile || !fieldIDPattern.MatchString(f.Profile)) {
+		return fmt.Errorf("invalid profile key %q", f.Profile)
+	}
+
+	if f.MinLength != nil && *f.MinLength < 0 || f.MaxLength != nil && *f.MaxLength < 0 {
+		return errors.New("lengths must not be negative")
+	}
+	if f.MinLength != nil && f.MaxLength != nil && *f.MinLength > *f.MaxLength {
+		return errors.New("min_length exceeds max_length")
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return errors.New("min exceeds max")
+	}
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+	switch f.Format {
+	case "", FormatEmail, FormatPhone:
+	default:
+		return fmt.Errorf("unknown format %q", f.Format)
+	}
+
+	return nil
+}
+
+// Field returns the field with the given ID.
+func (s *Schema) Field(id string) (*Field, bool) {
+	for i := range s.Fields {
+		if s.Fields[i].ID == id {
+			return &s.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+func (s *Schema) sectionIndex(id string) int {
+	for i := range s.Sections {
+		if s.Sections[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Error codes reported in FieldError.Code.
+const (
+	CodeRequired  = "required"
+	CodeType      = "type"
+	CodeEnum      = "enum"
+	CodeMinLength = "min_length"
+	CodeMaxLength = "max_length"
+	CodeMin       = "min"
+	CodeMax       = "max"
+	CodePattern   = "pattern"
+	CodeFormat    = "format"
+	CodeUnknown   = "unknown_field"
+	CodeFile      = "file"
+)
+
+// FieldError describes one invalid answer. Code is stable and meant for
+// clients to localise; Message is a readable fallback.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid answer of a submission.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "invalid answers: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidAnswers
+}
+
+func (e *ValidationError) add(field, code, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{
+		Field:   field,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// DecodeAnswers parses submitted answers into a map keyed by field ID.
+func DecodeAnswers(raw json.RawMessage) (map[string]interface{}, error) {
+	answers := make(map[string]interface{})
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return answers, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&answers); err != nil {
+		return nil, fmt.Errorf("%w: answers must be a JSON object", ErrInvalidAnswers)
+	}
+	return answers, nil
+}
+
+// Validate checks answers against the schema. With a rule state, hidden
+// fields are skipped and its required flags replace the schema's. It
+// returns a *ValidationError listing every problem, or nil.
+func (s *Schema) Validate(answers map[string]interface{}, state *State) error {
+	verr := &ValidationError{}
+
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		required := f.Required
+		if state != nil {
+			if state.Hidden[f.ID] {
+				continue
+			}
+			required = state.Required[f.ID]
+		}
+
+		value, ok := answers[f.ID]
+		if !ok || isEmpty(value) {
+			if required {
+				verr.add(f.ID, CodeRequired, "is required")
+			}
+			continue
+		}
+
+		f.validate(value, verr)
+	}
+
+	var unknown []string
+	for id := range answers {
+		if _, ok := s.Field(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		verr.add(id, CodeUnknown, "is not a field of this form")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case bool:
+		// An unticked checkbox counts as unanswered, so a required
+		// checkbox must be ticked.
+		return !v
+	}
+	return false
+}
+
+func (f *Field) validate(value interface{}, verr *ValidationError) {
+	switch f.Type {
+	case FieldText, FieldTextarea, FieldEmail, FieldPhone:
+		s, ok := value.(string)
+		if !ok {
+			verr.add(f.ID, CodeType, "must be a string")
+			return
+		}
+		f.validateString(s, verr)
+
+	case FieldNumber:
+		n, ok := toNumber(value)
+		if !ok {
+			verr.add(f.ID, CodeType, "must be a number")
+			return
+		}
+		if f.Min != nil && n < *f.Min {
+			verr.add(f.ID, CodeMin, "must be at least %v", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			verr.add(f.ID, CodeMax, "must be at most %v", *f.Max)
+		}
+
+	case FieldDate:
+		s, ok := value.(string)
+		if !ok {
+			verr.add(f.ID, CodeType, "must be a date string")
+			return
+		}
+		if _, err := time.Parse(dateLayout, s); err != nil {
+			verr.add(f.ID, CodeFormat, "must be a date in YYYY-MM-DD format")
+		}
+
+	case FieldSelect, FieldRadio:
+		s, ok := value.(string)
+		if !ok {
+			verr.add(f.ID, CodeType, "must be a string")
+			return
+		}
+		if !f.hasOption(s) {
+			verr.add(f.ID, CodeEnum, "must be one of the listed options")
+		}
+
+	case FieldMultiselect:
+		items, ok := value.([]interface{})
+		if !ok {
+			verr.add(f.ID, CodeType, "must be a list")
+			return
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok || !f.hasOption(s) {
+				verr.add(f.ID, CodeEnum, "must only contain listed options")
+				return
+			}
+		}
+		if f.MinLength != nil && len(items) < *f.MinLength {
+			verr.add(f.ID, CodeMinLength, "must have at least %d choices", *f.MinLength)
+		}
+		if f.MaxLength != nil && len(items) > *f.MaxLength {
+			verr.add(f.ID, CodeMaxLength, "must have at most %d choices", *f.MaxLength)
+		}
+
+	case FieldCheckbox:
+		if _, ok := value.(bool); !ok {
+			verr.add(f.ID, CodeType, "must be true or false")
+		}
+
+	case FieldFile:
+		if _, ok := value.(string); !ok {
+			verr.add(f.ID, CodeType, "must be an uploaded file id")
+		}
+	}
+}
+
+func (f *Field) validateString(s string, verr *ValidationError) {
+	n := utf8.RuneCountInString(s)
+	if f.MinLength != nil && n < *f.MinLength {
+		verr.add(f.ID, CodeMinLength, "must be at least %d characters", *f.MinLength)
+	}
+	if f.MaxLength != nil && n > *f.MaxLength {
+		verr.add(f.ID, CodeMaxLength, "must be at most %d characters", *f.MaxLength)
+	}
+	if f.Pattern != "" {
+		if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(s) {
+			verr.add(f.ID, CodePattern, "has an invalid format")
+		}
+	}
+
+	format := f.Format
+	switch f.Type {
+	case FieldEmail:
+		format = FormatEmail
+	case FieldPhone:
+		format = FormatPhone
+	}
+	switch format {
+	case FormatEmail:
+		if !isEmail(s) {
+			verr.add(f.ID, CodeFormat, "must be a valid email address")
+		}
+	case FormatPhone:
+		if !isPhone(s) {
+			verr.add(f.ID, CodeFormat, "must be a valid phone number")
+		}
+	}
+}
+
+func (f *Field) hasOption(value string) bool {
+	for _, o := range f.Options {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		// HTML inputs submit numbers as strings.
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndex(s, "@"):], ".")
+}
+
+func isPhone(s string) bool {
+	if !phonePattern.MatchString(s) {
+		return false
+	}
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
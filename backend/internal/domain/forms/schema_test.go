@@ -0,0 +1,75 @@
+package forms
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// uiSchema is a form as EventFormsPage builds it: IDs slugged from Russian
+// labels, select and radio options entered as a comma-separated list.
+const uiSchema = `{
+	"fields": [
+		{"id": "ваше_имя", "label": "Ваше имя", "type": "text"},
+		{"id": "телефон_моб", "label": "Телефон (моб.)", "type": "text"},
+		{"id": "о_себе", "label": "О себе", "type": "textarea"},
+		{"id": "размер_футболки", "label": "Размер футболки", "type": "select",
+			"options": [{"value": "S", "label": "S"}, {"value": "M", "label": "M"}, {"value": "L", "label": "L"}]},
+		{"id": "формат", "label": "Формат", "type": "radio",
+			"options": [{"value": "Онлайн", "label": "Онлайн"}, {"value": "Офлайн", "label": "Офлайн"}]},
+		{"id": "согласие", "label": "Согласие", "type": "checkbox"},
+		{"id": "field_2024", "label": "2024", "type": "text"},
+		{"id": "ваше_имя_2", "label": "Ваше имя", "type": "text"}
+	]
+}`
+
+func TestParseSchemaAcceptsUIForms(t *testing.T) {
+	schema, err := ParseSchema(json.RawMessage(uiSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	answers, err := DecodeAnswers(json.RawMessage(`{
+		"ваше_имя": "Анна",
+		"размер_футболки": "M",
+		"формат": "Офлайн",
+		"согласие": true
+	}`))
+	if err != nil {
+		t.Fatalf("DecodeAnswers: %v", err)
+	}
+	if err := schema.Validate(answers, nil); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	answers["размер_футболки"] = "XXL"
+	var verr *ValidationError
+	if err := schema.Validate(answers, nil); !errors.As(err, &verr) || verr.Fields[0].Code != CodeEnum {
+		t.Fatalf("Validate with an unknown option = %v, want an enum error", err)
+	}
+}
+
+func TestParseSchemaFieldIDs(t *testing.T) {
+	tests := []struct {
+		id string
+		ok bool
+	}{
+		{"email", true},
+		{"ваше_имя", true},
+		{"вопрос_2", true},
+		{"", false},
+		{"2_вопрос", false},
+		{"_имя", false},
+		{"ваше имя", false},
+		{"имя-фамилия", false},
+	}
+	for _, tt := range tests {
+		raw, _ := json.Marshal(map[string]interface{}{
+			"fields": []map[string]string{{"id": tt.id, "label": "Вопрос", "type": "text"}},
+		})
+		_, err := ParseSchema(raw)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseSchema with id %q: err = %v, want ok = %v", tt.id, err, tt.ok)
+		}
+	}
+}
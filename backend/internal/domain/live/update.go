@@ -0,0 +1,70 @@
+// Package live describes what an event's live dashboard is told as
+// attendance changes.
+package live
+
+import (
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type Kind string
+
+const (
+	// KindSnapshot opens every stream with the current counts.
+	KindSnapshot Kind = "snapshot"
+	KindCheckin  Kind = "checkin"
+	KindRSVP     Kind = "rsvp"
+	KindWaitlist Kind = "waitlist"
+	// KindRegistration covers a registration created, cancelled or
+	// decided by the organizer.
+	KindRegistration Kind = "registration"
+)
+
+// Counts is the event's state right after the change it is sent with.
+// Going and the other statuses count registrations; Seats counts the
+// places they hold against Capacity.
+type Counts struct {
+	Capacity    int `json:"capacity"`
+	Seats       int `json:"seats"`
+	Going       int `json:"going"`
+	Maybe       int `json:"maybe"`
+	NotGoing    int `json:"not_going"`
+	Waitlist    int `json:"waitlist"`
+	Pending     int `json:"pending"`
+	PendingForm int `json:"pending_form"`
+	Rejected    int `json:"rejected"`
+	Inside      int `json:"inside"`
+	Attended    int `json:"attended"`
+}
+
+// Update is one change at an event. Status is the attendee's new
+// registration status, or the check-in direction for KindCheckin; an
+// empty status on KindRegistration means the registration was cancelled.
+// ZoneID marks a pass through a zone's door; Counts.Inside is the main
+// entrance only.
+type Update struct {
+	Kind       Kind       `json:"kind"`
+	EventID    shared.ID  `json:"event_id"`
+	UserID     *shared.ID `json:"user_id,omitempty"`
+	ZoneID     *shared.ID `json:"zone_id,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	PrevStatus string     `json:"prev_status,omitempty"`
+	Reentry    bool       `json:"reentry,omitempty"`
+	Counts     *Counts    `json:"counts,omitempty"`
+	At         time.Time  `json:"at"`
+}
+
+func NewUpdate(kind Kind, eventID shared.ID) *Update {
+	return &Update{
+		Kind:    kind,
+		EventID: eventID,
+		At:      time.Now().UTC(),
+	}
+}
+
+// For sets the attendee the update is about.
+func (u *Update) For(userID shared.ID) *Update {
+	u.UserID = &userID
+	return u
+}
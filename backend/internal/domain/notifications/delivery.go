@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+// Delivery records one message sent to one user on behalf of a campaign or a
+// reminder.
+type Delivery struct {
+	ID         shared.ID
+	CampaignID *shared.ID
+	ReminderID *shared.ID
+	Channel    string
+	TargetUser shared.ID
+	MessageID  string
+	Status     DeliveryStatus
+	Error      string
+	Attempts   int
+	shared.Timestamp
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+const ChannelMax = "max"
+
+func NewReminderDelivery(reminderID, userID shared.ID) *Delivery {
+	return &Delivery{
+		ID:         shared.NewID(),
+		ReminderID: &reminderID,
+		Channel:    ChannelMax,
+		TargetUser: userID,
+		Status:     DeliveryPending,
+		Timestamp:  shared.NewTimestamp(),
+	}
+}
+
+func NewCampaignDelivery(campaignID, userID shared.ID, channel string) *Delivery {
+	return &Delivery{
+		ID:         shared.NewID(),
+		CampaignID: &campaignID,
+		Channel:    channel,
+		TargetUser: userID,
+		Status:     DeliveryPending,
+		Timestamp:  shared.NewTimestamp(),
+	}
+}
+
+func (d *Delivery) MarkSent(messageID string) {
+	d.Status = DeliverySent
+	d.MessageID = messageID
+	d.Error = ""
+	d.Timestamp.Touch()
+}
+
+func (d *Delivery) MarkFailed(err error) {
+	d.Status = DeliveryFailed
+	d.Error = err.Error()
+	d.Timestamp.Touch()
+}
@@ -0,0 +1,154 @@
+package orgs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type Organization struct {
+	ID          shared.ID
+	Name        string
+	Description string
+	OwnerID     shared.ID
+	// InviteToken lets anyone holding the link join as a member. It is only
+	// shown to admins.
+	InviteToken string `json:"-"`
+	shared.Timestamp
+}
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+type Member struct {
+	OrgID    shared.ID
+	UserID   shared.ID
+	Role     Role
+	JoinedAt time.Time
+}
+
+type JoinRequest struct {
+	ID        shared.ID
+	OrgID     shared.ID
+	UserID    shared.ID
+	Message   string
+	Status    JoinStatus
+	DecidedBy *shared.ID
+	DecidedAt *time.Time
+	CreatedAt time.Time
+}
+
+type JoinStatus string
+
+const (
+	JoinPending  JoinStatus = "pending"
+	JoinApproved JoinStatus = "approved"
+	JoinRejected JoinStatus = "rejected"
+)
+
+var (
+	ErrOrgNotFound         = errors.New("organization not found")
+	ErrInvalidOrg          = errors.New("organization name is required")
+	ErrNotMember           = errors.New("user is not a member of the organization")
+	ErrNotAdmin            = errors.New("organization admin rights required")
+	ErrAlreadyMember       = errors.New("user is already a member")
+	ErrLastAdmin           = errors.New("organization must keep at least one admin")
+	ErrInvalidRole         = errors.New("invalid organization role")
+	ErrJoinRequestNotFound = errors.New("join request not found")
+	ErrJoinRequestDecided  = errors.New("join request already decided")
+	ErrInvalidInvite       = errors.New("invalid invite link")
+	ErrInvalidFeedToken    = errors.New("invalid calendar feed link")
+)
+
+func NewOrganization(ownerID shared.ID, name, description string) (*Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidOrg
+	}
+
+	return &Organization{
+		ID:          shared.NewID(),
+		Name:        name,
+		Description: description,
+		OwnerID:     ownerID,
+		Timestamp:   shared.NewTimestamp(),
+	}, nil
+}
+
+func (o *Organization) RotateInviteToken() error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	o.InviteToken = token
+	o.Timestamp.Touch()
+	return nil
+}
+
+// NewFeedToken makes a member's personal calendar feed token. Calendar
+// clients send no cookies, so the token stands in for the member's session
+// and dies with the membership.
+func NewFeedToken() (string, error) {
+	return newToken()
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (o *Organization) RevokeInviteToken() {
+	o.InviteToken = ""
+	o.Timestamp.Touch()
+}
+
+func NewMember(orgID, userID shared.ID, role Role) *Member {
+	return &Member{
+		OrgID:    orgID,
+		UserID:   userID,
+		Role:     role,
+		JoinedAt: time.Now().UTC(),
+	}
+}
+
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember
+}
+
+func NewJoinRequest(orgID, userID shared.ID, message string) *JoinRequest {
+	return &JoinRequest{
+		ID:        shared.NewID(),
+		OrgID:     orgID,
+		UserID:    userID,
+		Message:   message,
+		Status:    JoinPending,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func (j *JoinRequest) Decide(by shared.ID, approve bool) error {
+	if j.Status != JoinPending {
+		return ErrJoinRequestDecided
+	}
+
+	now := time.Now().UTC()
+	j.DecidedBy = &by
+	j.DecidedAt = &now
+	if approve {
+		j.Status = JoinApproved
+	} else {
+		j.Status = JoinRejected
+	}
+	return nil
+}
@@ -3,20 +3,36 @@ package registrations
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
 )
 
 type Registration struct {
-	ID      shared.ID
-	EventID shared.ID
-	UserID  shared.ID
-	Status  Status
-	Source  string
-	UTM     json.RawMessage
+	ID           shared.ID
+	EventID      shared.ID
+	UserID       shared.ID
+	TicketTypeID *shared.ID
+	Quantity     int
+	Status       Status
+	Source       string
+	UTM          json.RawMessage
+	// DecidedBy, DecidedAt and DecisionReason record the organizer's answer
+	// for events that require approval.
+	DecidedBy      *shared.ID
+	DecidedAt      *time.Time
+	DecisionReason string
+	// HoldUntil is when a pending_form registration gives up its seat.
+	HoldUntil *time.Time
 	shared.Timestamp
 }
 
+// Recipient is an attendee together with the MAX chat that reaches them.
+type Recipient struct {
+	UserID shared.ID
+	ChatID int64
+}
+
 type Status string
 
 const (
@@ -24,12 +40,32 @@ const (
 	StatusNotGoing Status = "not_going"
 	StatusMaybe    Status = "maybe"
 	StatusWaitlist Status = "waitlist"
+	StatusPending  Status = "pending"
+	StatusRejected Status = "rejected"
+	// StatusPendingForm holds a seat until the attendee submits the
+	// event's required form.
+	StatusPendingForm Status = "pending_form"
 )
 
+// SeatStatuses are the statuses that occupy a seat.
+var SeatStatuses = []Status{StatusGoing, StatusPendingForm}
+
+// IsRSVP reports whether an attendee may pick the status themselves. The
+// others are set by capacity, approval and the form gate.
+func (s Status) IsRSVP() bool {
+	return s == StatusGoing || s == StatusMaybe || s == StatusNotGoing
+}
+
+func (s Status) HoldsSeat() bool {
+	return s == StatusGoing || s == StatusPendingForm
+}
+
 type Waitlist struct {
-	ID      shared.ID
-	EventID shared.ID
-	UserID  shared.ID
+	ID           shared.ID
+	EventID      shared.ID
+	UserID       shared.ID
+	TicketTypeID *shared.ID
+	Quantity     int
 	shared.Timestamp
 }
 
@@ -37,6 +73,10 @@ var (
 	ErrRegistrationNotFound = errors.New("registration not found")
 	ErrAlreadyRegistered    = errors.New("user already registered")
 	ErrCapacityReached      = errors.New("event capacity reached")
+	ErrAwaitingApproval     = errors.New("registration is awaiting approval")
+	ErrRegistrationRejected = errors.New("registration was rejected")
+	ErrNotPending           = errors.New("registration is not pending approval")
+	ErrInvalidStatus        = errors.New("status must be going, maybe or not_going")
 )
 
 func NewRegistration(eventID, userID shared.ID, source string, utm json.RawMessage) *Registration {
@@ -44,6 +84,7 @@ func NewRegistration(eventID, userID shared.ID, source string, utm json.RawMessa
 		ID:        shared.NewID(),
 		EventID:   eventID,
 		UserID:    userID,
+		Quantity:  1,
 		Status:    StatusGoing,
 		Source:    source,
 		UTM:       utm,
@@ -53,14 +94,68 @@ func NewRegistration(eventID, userID shared.ID, source string, utm json.RawMessa
 
 func (r *Registration) UpdateRSVP(status Status) {
 	r.Status = status
+	if status != StatusPendingForm {
+		r.HoldUntil = nil
+	}
 	r.Timestamp.Touch()
 }
 
-func NewWaitlistEntry(eventID, userID shared.ID) *Waitlist {
+// HoldForForm keeps the seat for hold while the attendee fills in the form.
+func (r *Registration) HoldForForm(hold time.Duration) {
+	until := time.Now().Add(hold)
+	r.UpdateRSVP(StatusPendingForm)
+	r.HoldUntil = &until
+}
+
+func (r *Registration) HoldExpired(now time.Time) bool {
+	return r.Status == StatusPendingForm && r.HoldUntil != nil && !now.Before(*r.HoldUntil)
+}
+
+// CheckEditable fails while the registration is waiting for, or was refused
+// by, an organizer; the attendee cannot change the RSVP in those states.
+func (r *Registration) CheckEditable() error {
+	switch r.Status {
+	case StatusPending:
+		return ErrAwaitingApproval
+	case StatusRejected:
+		return ErrRegistrationRejected
+	}
+	return nil
+}
+
+// Approve records the decision only; the caller then seats the attendee
+// through the regular capacity check, which sets going or waitlist.
+func (r *Registration) Approve(by shared.ID, reason string) error {
+	if r.Status != StatusPending {
+		return ErrNotPending
+	}
+	r.decide(by, reason)
+	return nil
+}
+
+func (r *Registration) Reject(by shared.ID, reason string) error {
+	if r.Status != StatusPending {
+		return ErrNotPending
+	}
+	r.decide(by, reason)
+	r.UpdateRSVP(StatusRejected)
+	return nil
+}
+
+func (r *Registration) decide(by shared.ID, reason string) {
+	now := time.Now()
+	r.DecidedBy = &by
+	r.DecidedAt = &now
+	r.DecisionReason = reason
+}
+
+func NewWaitlistEntry(reg *Registration) *Waitlist {
 	return &Waitlist{
-		ID:        shared.NewID(),
-		EventID:   eventID,
-		UserID:    userID,
-		Timestamp: shared.NewTimestamp(),
+		ID:           shared.NewID(),
+		EventID:      reg.EventID,
+		UserID:       reg.UserID,
+		TicketTypeID: reg.TicketTypeID,
+		Quantity:     reg.Quantity,
+		Timestamp:    shared.NewTimestamp(),
 	}
 }
@@ -16,6 +16,12 @@ func (id ID) String() string {
 	return string(id)
 }
 
+// Valid reports whether the ID is a UUID, as every stored ID is.
+func (id ID) Valid() bool {
+	_, err := uuid.Parse(string(id))
+	return err == nil
+}
+
 type Timestamp struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -0,0 +1,133 @@
+package tickets
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/kvorum/internal/domain/shared"
+)
+
+type TicketType struct {
+	ID           shared.ID
+	EventID      shared.ID
+	Name         string
+	Description  string
+	Price        int64
+	Currency     string
+	PriceTiers   []PriceTier
+	Capacity     int
+	SalesStart   *time.Time
+	SalesEnd     *time.Time
+	Hidden       bool
+	UnlockCode   string
+	PerUserLimit int
+	SortOrder    int
+	shared.Timestamp
+}
+
+// PriceTier overrides the base price until the given moment, e.g. early bird.
+type PriceTier struct {
+	Name  string     `json:"name"`
+	Price int64      `json:"price"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+var (
+	ErrTicketTypeNotFound  = errors.New("ticket type not found")
+	ErrTicketTypeRequired  = errors.New("ticket type is required")
+	ErrTicketTypeLocked    = errors.New("ticket type requires an unlock code")
+	ErrSalesNotStarted     = errors.New("ticket sales have not started")
+	ErrSalesEnded          = errors.New("ticket sales have ended")
+	ErrPerUserLimit        = errors.New("ticket quantity exceeds per-user limit")
+	ErrInvalidQuantity     = errors.New("ticket quantity must be positive")
+	ErrInvalidSalesWindow  = errors.New("sales end must be after sales start")
+	ErrInvalidTicketConfig = errors.New("invalid ticket type configuration")
+)
+
+func NewTicketType(eventID shared.ID, name string) *TicketType {
+	return &TicketType{
+		ID:           shared.NewID(),
+		EventID:      eventID,
+		Name:         name,
+		Currency:     "RUB",
+		PerUserLimit: 1,
+		Timestamp:    shared.NewTimestamp(),
+	}
+}
+
+func (t *TicketType) Validate() error {
+	if strings.TrimSpace(t.Name) == "" || t.Capacity < 0 || t.Price < 0 || t.PerUserLimit < 0 {
+		return ErrInvalidTicketConfig
+	}
+	if t.SalesStart != nil && t.SalesEnd != nil && !t.SalesEnd.After(*t.SalesStart) {
+		return ErrInvalidSalesWindow
+	}
+	if t.Hidden && t.UnlockCode == "" {
+		return ErrInvalidTicketConfig
+	}
+	for _, tier := range t.PriceTiers {
+		if tier.Price < 0 {
+			return ErrInvalidTicketConfig
+		}
+	}
+	return nil
+}
+
+func (t *TicketType) OnSale(now time.Time) error {
+	if t.SalesStart != nil && now.Before(*t.SalesStart) {
+		return ErrSalesNotStarted
+	}
+	if t.SalesEnd != nil && !now.Before(*t.SalesEnd) {
+		return ErrSalesEnded
+	}
+	return nil
+}
+
+func (t *TicketType) Unlocks(code string) bool {
+	return !t.Hidden || (code != "" && code == t.UnlockCode)
+}
+
+// CheckPurchase verifies that quantity tickets of this type can be taken
+// right now with the given unlock code. Capacity is checked separately under
+// the event lock.
+func (t *TicketType) CheckPurchase(now time.Time, quantity int, unlockCode string) error {
+	if quantity < 1 {
+		return ErrInvalidQuantity
+	}
+	if !t.Unlocks(unlockCode) {
+		return ErrTicketTypeLocked
+	}
+	if err := t.OnSale(now); err != nil {
+		return err
+	}
+	if t.PerUserLimit > 0 && quantity > t.PerUserLimit {
+		return ErrPerUserLimit
+	}
+	return nil
+}
+
+func (t *TicketType) CurrentPrice(now time.Time) int64 {
+	tiers := make([]PriceTier, 0, len(t.PriceTiers))
+	for _, tier := range t.PriceTiers {
+		if tier.Until != nil {
+			tiers = append(tiers, tier)
+		}
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Until.Before(*tiers[j].Until) })
+
+	for _, tier := range tiers {
+		if now.Before(*tier.Until) {
+			return tier.Price
+		}
+	}
+	return t.Price
+}
+
+// Public returns a copy safe to show to attendees.
+func (t *TicketType) Public() *TicketType {
+	c := *t
+	c.UnlockCode = ""
+	return &c
+}
@@ -18,7 +18,13 @@ func NewUnitOfWork(pool *pgxpool.Pool) *UnitOfWork {
 
 type TxFunc func(context.Context, pgx.Tx) error
 
+// WithTx runs fn in a transaction. Called with a transaction already bound
+// to ctx, fn joins it instead, so services can compose transactional steps.
 func (u *UnitOfWork) WithTx(ctx context.Context, fn TxFunc) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return fn(ctx, tx)
+	}
+
 	tx, err := u.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
@@ -31,7 +37,7 @@ func (u *UnitOfWork) WithTx(ctx context.Context, fn TxFunc) error {
 		}
 	}()
 
-	if err := fn(ctx, tx); err != nil {
+	if err := fn(ContextWithTx(ctx, tx), tx); err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
 			return fmt.Errorf("rollback transaction: %w (original error: %v)", rbErr, err)
 		}
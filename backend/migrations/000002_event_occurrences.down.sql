@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_event_series_event;
+DROP INDEX IF EXISTS idx_events_series;
+
+ALTER TABLE events DROP COLUMN IF EXISTS original_starts_at;
+ALTER TABLE events DROP COLUMN IF EXISTS series_id;
@@ -0,0 +1,5 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES event_series(id) ON DELETE SET NULL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS original_starts_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_events_series ON events(series_id, original_starts_at);
+CREATE INDEX IF NOT EXISTS idx_event_series_event ON event_series(event_id);
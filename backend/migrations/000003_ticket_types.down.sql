@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_waitlist_event_created;
+DROP INDEX IF EXISTS idx_registrations_ticket_type;
+DROP INDEX IF EXISTS idx_ticket_types_event;
+
+ALTER TABLE waitlist DROP COLUMN IF EXISTS quantity;
+ALTER TABLE waitlist DROP COLUMN IF EXISTS ticket_type_id;
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_ticket_type_fk;
+ALTER TABLE registrations DROP COLUMN IF EXISTS quantity;
+
+DROP TABLE IF EXISTS ticket_types CASCADE;
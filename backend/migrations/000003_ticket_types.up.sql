@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS ticket_types (
+                                            id UUID PRIMARY KEY,
+                                            event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+                                            name TEXT NOT NULL,
+                                            description TEXT,
+                                            price BIGINT NOT NULL DEFAULT 0,
+                                            currency TEXT NOT NULL DEFAULT 'RUB',
+                                            price_tiers JSONB NOT NULL DEFAULT '[]'::jsonb,
+                                            capacity INT NOT NULL DEFAULT 0,
+                                            sales_start TIMESTAMPTZ,
+                                            sales_end TIMESTAMPTZ,
+                                            hidden BOOLEAN NOT NULL DEFAULT false,
+                                            unlock_code TEXT,
+                                            per_user_limit INT NOT NULL DEFAULT 1,
+                                            sort_order INT NOT NULL DEFAULT 0,
+                                            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 1;
+ALTER TABLE registrations
+    ADD CONSTRAINT registrations_ticket_type_fk FOREIGN KEY (ticket_type_id) REFERENCES ticket_types(id) ON DELETE SET NULL;
+
+ALTER TABLE waitlist ADD COLUMN IF NOT EXISTS ticket_type_id UUID REFERENCES ticket_types(id) ON DELETE SET NULL;
+ALTER TABLE waitlist ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_ticket_types_event ON ticket_types(event_id);
+CREATE INDEX IF NOT EXISTS idx_registrations_ticket_type ON registrations(ticket_type_id);
+CREATE INDEX IF NOT EXISTS idx_waitlist_event_created ON waitlist(event_id, created_at);
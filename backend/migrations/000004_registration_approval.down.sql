@@ -0,0 +1,3 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS decision_reason;
+ALTER TABLE registrations DROP COLUMN IF EXISTS decided_at;
+ALTER TABLE registrations DROP COLUMN IF EXISTS decided_by;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_event_invites_user;
+DROP TABLE IF EXISTS event_invites CASCADE;
+
+ALTER TABLE events DROP COLUMN IF EXISTS share_token;
@@ -0,0 +1,11 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS share_token TEXT;
+
+CREATE TABLE IF NOT EXISTS event_invites (
+                                             event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+                                             user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                             invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                             PRIMARY KEY (event_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_invites_user ON event_invites(user_id);
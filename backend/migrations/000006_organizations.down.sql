@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_events_organization;
+DROP INDEX IF EXISTS idx_org_join_requests_pending;
+DROP INDEX IF EXISTS idx_organization_members_user;
+DROP INDEX IF EXISTS idx_organizations_invite_token;
+
+ALTER TABLE events DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_join_requests CASCADE;
+DROP TABLE IF EXISTS organization_members CASCADE;
+DROP TABLE IF EXISTS organizations CASCADE;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS organizations (
+                                             id UUID PRIMARY KEY,
+                                             name TEXT NOT NULL,
+                                             description TEXT,
+                                             owner_id UUID NOT NULL REFERENCES users(id),
+                                             invite_token TEXT,
+                                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+                                                    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+                                                    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                    role TEXT NOT NULL DEFAULT 'member',
+                                                    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                                    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS organization_join_requests (
+                                                          id UUID PRIMARY KEY,
+                                                          org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+                                                          user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                          message TEXT,
+                                                          status TEXT NOT NULL DEFAULT 'pending',
+                                                          decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                                          decided_at TIMESTAMPTZ,
+                                                          created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_invite_token ON organizations(invite_token) WHERE invite_token IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_organization_members_user ON organization_members(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_org_join_requests_pending ON organization_join_requests(org_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_events_organization ON events(organization_id, starts_at);
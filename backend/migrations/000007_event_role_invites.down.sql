@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_event_role_invites_event;
+DROP TABLE IF EXISTS event_role_invites CASCADE;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS event_role_invites (
+                                                  id UUID PRIMARY KEY,
+                                                  event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+                                                  role TEXT NOT NULL,
+                                                  token TEXT NOT NULL UNIQUE,
+                                                  created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                  expires_at TIMESTAMPTZ,
+                                                  used_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                                  used_at TIMESTAMPTZ,
+                                                  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_role_invites_event ON event_role_invites(event_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_reminders_event_status;
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS task_id;
+ALTER TABLE reminders DROP COLUMN IF EXISTS offset_seconds;
+
+DELETE FROM reminders WHERE user_id IS NULL;
+ALTER TABLE reminders ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE reminders ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS offset_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS task_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_reminders_event_status ON reminders(event_id, status);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_deliveries_reminder_user;
+
+DELETE FROM deliveries WHERE reminder_id IS NOT NULL;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS attempts;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS reminder_id;
@@ -0,0 +1,6 @@
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS reminder_id UUID REFERENCES reminders(id) ON DELETE CASCADE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deliveries_reminder_user
+    ON deliveries(reminder_id, target_user_id) WHERE reminder_id IS NOT NULL;
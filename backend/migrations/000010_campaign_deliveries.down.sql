@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_deliveries_campaign_user;
+
+ALTER TABLE campaigns DROP COLUMN IF EXISTS channel;
@@ -0,0 +1,4 @@
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT 'max';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deliveries_campaign_user
+    ON deliveries(campaign_id, target_user_id) WHERE campaign_id IS NOT NULL;
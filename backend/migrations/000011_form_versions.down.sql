@@ -0,0 +1,5 @@
+ALTER TABLE form_responses DROP COLUMN IF EXISTS stale;
+ALTER TABLE form_responses DROP COLUMN IF EXISTS previous_response_id;
+
+DROP INDEX IF EXISTS idx_forms_event_active;
+DROP INDEX IF EXISTS idx_forms_event_version;
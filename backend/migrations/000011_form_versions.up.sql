@@ -0,0 +1,22 @@
+-- number existing forms per event and keep only the newest one active
+UPDATE forms f
+SET version = v.rn
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY created_at) AS rn
+    FROM forms
+) v
+WHERE f.id = v.id;
+
+UPDATE forms f
+SET active = false
+WHERE active = true
+  AND EXISTS (
+    SELECT 1 FROM forms newer
+    WHERE newer.event_id = f.event_id AND newer.active = true AND newer.version > f.version
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_forms_event_version ON forms(event_id, version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_forms_event_active ON forms(event_id) WHERE active = true;
+
+ALTER TABLE form_responses ADD COLUMN IF NOT EXISTS previous_response_id UUID REFERENCES form_responses(id) ON DELETE SET NULL;
+ALTER TABLE form_responses ADD COLUMN IF NOT EXISTS stale BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_registrations_hold_until;
+
+UPDATE registrations SET status = 'going' WHERE status = 'pending_form';
+ALTER TABLE registrations DROP COLUMN IF EXISTS hold_until;
@@ -0,0 +1,4 @@
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS hold_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_registrations_hold_until
+    ON registrations(hold_until) WHERE status = 'pending_form';
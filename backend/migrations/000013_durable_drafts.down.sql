@@ -0,0 +1 @@
+ALTER TABLE form_responses DROP COLUMN IF EXISTS revision;
@@ -0,0 +1 @@
+ALTER TABLE form_responses ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS form_files (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    response_id UUID REFERENCES form_responses(id) ON DELETE CASCADE,
+    field_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_files_response ON form_files(response_id);
+CREATE INDEX IF NOT EXISTS idx_form_files_user ON form_files(event_id, user_id);
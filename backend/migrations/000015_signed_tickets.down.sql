@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS ticket_signing_keys;
+DROP TABLE IF EXISTS tickets;
+
+CREATE TABLE IF NOT EXISTS qr_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_qr_tokens_hash ON qr_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_qr_tokens_expires ON qr_tokens(expires_at);
@@ -0,0 +1,29 @@
+DROP TABLE IF EXISTS qr_tokens;
+
+CREATE TABLE IF NOT EXISTS tickets (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ticket_type_id UUID REFERENCES ticket_types(id) ON DELETE SET NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_until TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoke_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_live ON tickets(event_id, user_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tickets_event ON tickets(event_id);
+
+CREATE TABLE IF NOT EXISTS ticket_signing_keys (
+    id TEXT PRIMARY KEY,
+    public_key BYTEA NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+-- Only one key signs at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_signing_keys_active ON ticket_signing_keys((true)) WHERE retired_at IS NULL;
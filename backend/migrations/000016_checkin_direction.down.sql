@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_checkins_event_user;
+
+ALTER TABLE checkins DROP COLUMN IF EXISTS reentry;
+ALTER TABLE checkins DROP COLUMN IF EXISTS direction;
@@ -0,0 +1,4 @@
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS direction TEXT NOT NULL DEFAULT 'in';
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS reentry BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_checkins_event_user ON checkins(event_id, user_id, at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS checkin_attempts (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    scanner_id UUID NOT NULL REFERENCES users(id),
+    device_id TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    action TEXT NOT NULL,
+    ticket_id UUID,
+    user_id UUID,
+    accepted BOOLEAN NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    checkin_id UUID REFERENCES checkins(id) ON DELETE SET NULL,
+    at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkin_attempts_event ON checkin_attempts(event_id, at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_checkin_attempts_received;
+DROP TABLE IF EXISTS scanner_devices;
+DROP INDEX IF EXISTS idx_checkin_attempts_local;
+ALTER TABLE checkin_attempts DROP COLUMN IF EXISTS received_at;
+ALTER TABLE checkin_attempts DROP COLUMN IF EXISTS local_id;
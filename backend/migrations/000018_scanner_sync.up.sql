@@ -0,0 +1,25 @@
+ALTER TABLE checkin_attempts ADD COLUMN IF NOT EXISTS local_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE checkin_attempts ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+UPDATE checkin_attempts SET received_at = at;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_checkin_attempts_local
+    ON checkin_attempts(event_id, device_id, local_id) WHERE local_id <> '';
+
+CREATE TABLE IF NOT EXISTS scanner_devices (
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    device_id TEXT NOT NULL,
+    scanner_id UUID NOT NULL REFERENCES users(id),
+    user_agent TEXT NOT NULL DEFAULT '',
+    manifest_cursor TIMESTAMPTZ,
+    manifest_at TIMESTAMPTZ,
+    uploaded_at TIMESTAMPTZ,
+    last_scan_at TIMESTAMPTZ,
+    uploaded INT NOT NULL DEFAULT 0,
+    accepted INT NOT NULL DEFAULT 0,
+    conflicts INT NOT NULL DEFAULT 0,
+    rejected INT NOT NULL DEFAULT 0,
+    pending INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (event_id, device_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkin_attempts_received ON checkin_attempts(event_id, received_at DESC);
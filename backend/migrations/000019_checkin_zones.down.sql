@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_checkins_zone;
+ALTER TABLE checkin_attempts DROP COLUMN IF EXISTS zone_id;
+ALTER TABLE checkins DROP COLUMN IF EXISTS zone_id;
+DROP INDEX IF EXISTS idx_checkin_zones_event;
+DROP TABLE IF EXISTS checkin_zones;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS checkin_zones (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    ticket_type_ids UUID[] NOT NULL DEFAULT '{}',
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkin_zones_event ON checkin_zones(event_id);
+
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS zone_id UUID REFERENCES checkin_zones(id);
+ALTER TABLE checkin_attempts ADD COLUMN IF NOT EXISTS zone_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_checkins_zone ON checkins(zone_id, user_id, at) WHERE zone_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_events_series;
+CREATE INDEX IF NOT EXISTS idx_events_series ON events(series_id, original_starts_at);
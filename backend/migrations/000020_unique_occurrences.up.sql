@@ -0,0 +1,16 @@
+-- Concurrent materialization could store the same occurrence twice. Keep
+-- the oldest copy in the series; later copies are detached and cancelled,
+-- not deleted, since attendees may have registered for them.
+UPDATE events e
+SET series_id = NULL, status = 'cancelled', updated_at = NOW()
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY series_id, original_starts_at ORDER BY created_at, id
+    ) AS n
+    FROM events
+    WHERE series_id IS NOT NULL
+) d
+WHERE e.id = d.id AND d.n > 1;
+
+DROP INDEX IF EXISTS idx_events_series;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_series ON events(series_id, original_starts_at);
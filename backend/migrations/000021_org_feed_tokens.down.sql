@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_organization_members_feed_token;
+
+ALTER TABLE organization_members DROP COLUMN IF EXISTS feed_token;
@@ -0,0 +1,3 @@
+ALTER TABLE organization_members ADD COLUMN IF NOT EXISTS feed_token TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_members_feed_token ON organization_members(feed_token) WHERE feed_token IS NOT NULL;
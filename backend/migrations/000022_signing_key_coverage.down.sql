@@ -0,0 +1 @@
+ALTER TABLE ticket_signing_keys DROP COLUMN IF EXISTS signed_until;
@@ -0,0 +1,10 @@
+ALTER TABLE ticket_signing_keys ADD COLUMN IF NOT EXISTS signed_until TIMESTAMPTZ;
+
+-- What the existing keys signed is unknown, so assume any live ticket.
+UPDATE ticket_signing_keys
+SET signed_until = (SELECT MAX(valid_until) FROM tickets WHERE revoked_at IS NULL)
+WHERE expires_at IS NULL OR expires_at > NOW();
+
+UPDATE ticket_signing_keys
+SET expires_at = GREATEST(expires_at, signed_until)
+WHERE expires_at > NOW();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_checkins_received;
+
+ALTER TABLE checkins DROP COLUMN IF EXISTS received_at;
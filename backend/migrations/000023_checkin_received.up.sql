@@ -0,0 +1,4 @@
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+UPDATE checkins SET received_at = at;
+
+CREATE INDEX IF NOT EXISTS idx_checkins_received ON checkins(event_id, received_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_reminders_event_offset_pending;
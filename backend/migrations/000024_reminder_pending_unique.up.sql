@@ -0,0 +1,14 @@
+-- Keep the newest of any duplicate pending reminders; the worker skips the
+-- rest once they are no longer pending.
+UPDATE reminders r
+SET status = 'cancelled'
+WHERE r.user_id IS NULL AND r.status = 'pending'
+  AND EXISTS (
+      SELECT 1 FROM reminders d
+      WHERE d.event_id = r.event_id AND d.offset_seconds = r.offset_seconds
+        AND d.user_id IS NULL AND d.status = 'pending'
+        AND (d.created_at, d.id) > (r.created_at, r.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_event_offset_pending
+    ON reminders(event_id, offset_seconds) WHERE user_id IS NULL AND status = 'pending';
@@ -1,3 +1,4 @@
+import { useEffect, useState } from 'react'
 import { useQuery } from '@tanstack/react-query'
 import fetcher from '@/shared/api/fetcher'
 
@@ -12,6 +13,17 @@ export interface EventAnalytics {
     waitlist: number
     checked_in: number
     by_source: Record<string, number>
+    by_zone?: ZoneStats[]
+}
+
+export interface ZoneStats {
+    zone_id: string
+    name: string
+    attended: number
+    entries: number
+    inside: number
+    total_dwell_seconds: number
+    avg_dwell_seconds: number
 }
 
 export function useEventAnalytics(eventId: string, from?: string, to?: string) {
@@ -30,3 +42,55 @@ export function useEventAnalytics(eventId: string, from?: string, to?: string) {
         enabled: !!eventId,
     })
 }
+
+export interface LiveCounts {
+    capacity: number
+    seats: number
+    going: number
+    maybe: number
+    not_going: number
+    waitlist: number
+    pending: number
+    pending_form: number
+    rejected: number
+    inside: number
+    attended: number
+}
+
+export interface LiveUpdate {
+    kind: 'snapshot' | 'checkin' | 'rsvp' | 'waitlist' | 'registration'
+    event_id: string
+    user_id?: string
+    status?: string
+    prev_status?: string
+    zone_id?: string
+    reentry?: boolean
+    counts?: LiveCounts
+    at: string
+}
+
+const liveKinds: LiveUpdate['kind'][] = ['snapshot', 'checkin', 'rsvp', 'waitlist', 'registration']
+
+export function useLiveDashboard(eventId: string, keep = 20) {
+    const [counts, setCounts] = useState<LiveCounts | null>(null)
+    const [feed, setFeed] = useState<LiveUpdate[]>([])
+    const [connected, setConnected] = useState(false)
+
+    useEffect(() => {
+        if (!eventId) return
+        const source = new EventSource(`/api/v1/events/${eventId}/live`, { withCredentials: true })
+        const onUpdate = (e: MessageEvent) => {
+            const update = JSON.parse(e.data) as LiveUpdate
+            if (update.counts) setCounts(update.counts)
+            if (update.kind !== 'snapshot') {
+                setFeed((prev) => [update, ...prev].slice(0, keep))
+            }
+        }
+        liveKinds.forEach((kind) => source.addEventListener(kind, onUpdate))
+        source.onopen = () => setConnected(true)
+        source.onerror = () => setConnected(false)
+        return () => source.close()
+    }, [eventId, keep])
+
+    return { counts, feed, connected }
+}
@@ -38,6 +38,7 @@ export interface Form {
         fields: FormField[];
     };
     rules: FieldRule[];
+    prefill?: Record<string, unknown>;
 }
 
 export function useActiveForm(eventId: string) {
@@ -3,7 +3,7 @@ import { useParams } from 'react-router-dom'
 import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card'
 import { Button } from '@/components/ui/button'
 import { Input } from '@/components/ui/input'
-import { useEventAnalytics } from '@/entities/analytics/api'
+import { useEventAnalytics, useLiveDashboard, type LiveUpdate } from '@/entities/analytics/api'
 
 export default function AnalyticsPage() {
     const { eventId } = useParams<{ eventId: string }>()
@@ -11,6 +11,7 @@ export default function AnalyticsPage() {
     const [to, setTo] = useState('')
 
     const { data, isLoading } = useEventAnalytics(eventId || '', from || undefined, to || undefined)
+    const live = useLiveDashboard(eventId || '')
 
     const handleDownloadCSV = () => {
         if (!eventId) return
@@ -39,6 +40,47 @@ export default function AnalyticsPage() {
                 <Button onClick={handleDownloadCSV}>Экспорт CSV</Button>
             </div>
 
+            <Card>
+                <CardHeader>
+                    <CardTitle className="text-base flex items-center gap-2">
+                        Сейчас
+                        <span className={`h-2 w-2 rounded-full ${live.connected ? 'bg-green-500' : 'bg-muted-foreground'}`} />
+                    </CardTitle>
+                </CardHeader>
+                <CardContent className="space-y-4">
+                    {live.counts ? (
+                        <div className="grid gap-4 md:grid-cols-4">
+                            <LiveMetric
+                                title="Внутри"
+                                value={live.counts.inside}
+                                hint={live.counts.capacity > 0 ? `из ${live.counts.capacity}` : undefined}
+                            />
+                            <LiveMetric title="Пришли" value={live.counts.attended} />
+                            <LiveMetric
+                                title="Идут"
+                                value={live.counts.going}
+                                hint={`мест занято: ${live.counts.seats}`}
+                            />
+                            <LiveMetric title="Лист ожидания" value={live.counts.waitlist} />
+                        </div>
+                    ) : (
+                        <div className="text-sm text-muted-foreground">Подключение...</div>
+                    )}
+                    {live.feed.length > 0 && (
+                        <div className="space-y-1">
+                            {live.feed.map((u, i) => (
+                                <div key={`${u.at}-${i}`} className="flex items-center justify-between text-sm">
+                                    <span>{describeLiveUpdate(u)}</span>
+                                    <span className="text-muted-foreground">
+                                        {new Date(u.at).toLocaleTimeString()}
+                                    </span>
+                                </div>
+                            ))}
+                        </div>
+                    )}
+                </CardContent>
+            </Card>
+
             <Card>
                 <CardHeader>
                     <CardTitle className="text-base">Фильтры</CardTitle>
@@ -93,6 +135,25 @@ export default function AnalyticsPage() {
                             </CardContent>
                         </Card>
                     )}
+
+                    {data.by_zone && data.by_zone.length > 0 && (
+                        <Card>
+                            <CardHeader>
+                                <CardTitle className="text-base">Посещаемость зон</CardTitle>
+                            </CardHeader>
+                            <CardContent className="space-y-2">
+                                {data.by_zone.map((zone) => (
+                                    <div key={zone.zone_id} className="flex items-center justify-between text-sm">
+                                        <span className="text-muted-foreground">{zone.name}</span>
+                                        <span className="font-medium">
+                                            {zone.attended} чел. · сейчас {zone.inside} · в среднем{' '}
+                                            {formatDwell(zone.avg_dwell_seconds)}
+                                        </span>
+                                    </div>
+                                ))}
+                            </CardContent>
+                        </Card>
+                    )}
                 </>
             )}
         </div>
@@ -111,3 +172,38 @@ function MetricCard({ title, value }: { title: string; value: number }) {
         </Card>
     )
 }
+
+function LiveMetric({ title, value, hint }: { title: string; value: number; hint?: string }) {
+    return (
+        <div className="space-y-1">
+            <p className="text-xs text-muted-foreground">{title}</p>
+            <div className="text-2xl font-bold">{value}</div>
+            {hint && <p className="text-xs text-muted-foreground">{hint}</p>}
+        </div>
+    )
+}
+
+function formatDwell(seconds: number): string {
+    const minutes = Math.round(seconds / 60)
+    if (minutes < 60) return `${minutes} мин`
+    return `${Math.floor(minutes / 60)} ч ${minutes % 60} мин`
+}
+
+function describeLiveUpdate(u: LiveUpdate): string {
+    switch (u.kind) {
+        case 'checkin':
+            if (u.zone_id) return u.status === 'out' ? 'Выход из зоны' : 'Вход в зону'
+            if (u.status === 'out') return 'Участник вышел'
+            return u.reentry ? 'Участник вернулся' : 'Участник пришёл'
+        case 'waitlist':
+            return 'Участник переведён из листа ожидания'
+        case 'registration':
+            if (!u.status) return 'Регистрация отменена'
+            if (u.prev_status === 'pending') {
+                return u.status === 'rejected' ? 'Заявка отклонена' : 'Заявка одобрена'
+            }
+            return 'Новая регистрация'
+        default:
+            return `Ответ изменён: ${u.prev_status ?? ''} → ${u.status ?? ''}`
+    }
+}
@@ -112,6 +112,11 @@ function TicketCard({ eventId, title }: { eventId: string; title: string }) {
                         <div className="text-[10px] text-muted-foreground break-all">
                             {data.token}
                         </div>
+                        <Button asChild variant="outline" size="sm">
+                            <a href={`/api/v1/tickets/${eventId}/ticket.pdf`}>
+                                Скачать билет (PDF)
+                            </a>
+                        </Button>
                     </>
                 )}
             </CardContent>
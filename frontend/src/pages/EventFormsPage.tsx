@@ -7,11 +7,33 @@ import {
     useActiveForm,
     useCreateForm,
     FormField,
+    FormFieldOption,
     FieldRule,
 } from "@/entities/form/api";
 import { Textarea } from "@/components/ui/textarea";
 import { useToast } from "@/components/ui/use-toast";
 
+// fieldId turns a label into an ID the server accepts: a letter followed by
+// letters, digits or underscores, unique within the form.
+function fieldId(label: string, taken: Set<string>): string {
+    let base = label
+        .trim()
+        .toLowerCase()
+        .replace(/[^\p{L}\p{N}]+/gu, "_")
+        .replace(/^_+|_+$/g, "");
+    if (!/^\p{L}/u.test(base)) base = base ? `field_${base}` : "field";
+    base = base.slice(0, 56);
+
+    let id = base;
+    for (let n = 2; taken.has(id); n++) id = `${base}_${n}`;
+    return id;
+}
+
+function parseOptions(text: string): FormFieldOption[] {
+    const labels = [...new Set(text.split(",").map((o) => o.trim()).filter(Boolean))];
+    return labels.map((label) => ({ value: label, label }));
+}
+
 export default function EventFormsPage() {
     const { eventId } = useParams<{ eventId: string }>();
     const { data: currentForm } = useActiveForm(eventId || "");
@@ -27,12 +49,22 @@ export default function EventFormsPage() {
     const [newFieldType, setNewFieldType] = useState<
         "text" | "textarea" | "select" | "checkbox" | "radio"
     >("text");
+    const [newFieldOptions, setNewFieldOptions] = useState("");
+
+    const needsOptions = newFieldType === "select" || newFieldType === "radio";
+    const options = parseOptions(newFieldOptions);
+    const canAdd = newFieldLabel.trim() !== "" && (!needsOptions || options.length > 0);
 
     const addField = () => {
-        if (!newFieldLabel.trim()) return;
-        const id = newFieldLabel.toLowerCase().replace(/\s+/g, "_");
-        setFields((prev) => [...prev, { id, label: newFieldLabel, type: newFieldType }]);
+        if (!canAdd) return;
+        setFields((prev) => {
+            const id = fieldId(newFieldLabel, new Set(prev.map((f) => f.id)));
+            const field: FormField = { id, label: newFieldLabel.trim(), type: newFieldType };
+            if (needsOptions) field.options = options;
+            return [...prev, field];
+        });
         setNewFieldLabel("");
+        setNewFieldOptions("");
     };
 
     const save = async () => {
@@ -94,11 +126,19 @@ export default function EventFormsPage() {
                             <option value="checkbox">Чекбокс</option>
                             <option value="radio">Радио</option>
                         </select>
-                        <Button onClick={addField} disabled={!newFieldLabel.trim()}>
+                        <Button onClick={addField} disabled={!canAdd}>
                             Добавить
                         </Button>
                     </div>
 
+                    {needsOptions && (
+                        <Input
+                            placeholder="Варианты через запятую"
+                            value={newFieldOptions}
+                            onChange={(e) => setNewFieldOptions(e.target.value)}
+                        />
+                    )}
+
                     <div className="space-y-2 text-sm">
                         {fields.map((field, index) => (
                             <div
@@ -109,6 +149,11 @@ export default function EventFormsPage() {
                                 <span className="text-xs text-muted-foreground">
                   ({field.type})
                 </span>
+                                {field.options && field.options.length > 0 && (
+                                    <span className="text-xs text-muted-foreground">
+                                        {field.options.map((o) => o.label).join(", ")}
+                                    </span>
+                                )}
                                 <Button
                                     size="sm"
                                     variant="ghost"
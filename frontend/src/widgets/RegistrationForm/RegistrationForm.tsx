@@ -55,7 +55,10 @@ export default function RegistrationForm({
         const initialValues: FormValues = {};
         if (draftData && draftData.draft) {
             Object.assign(initialValues, draftData.draft);
-        } else if (user) {
+        } else {
+            Object.assign(initialValues, form.prefill ?? {});
+        }
+        if (!draftData?.draft && user) {
             for (const field of fields) {
                 if (!field.id) continue;
                 if (!initialValues[field.id]) {